@@ -8,6 +8,7 @@ import (
 	_ "net/http/pprof"
 	"path/filepath"
 	"runtime/debug"
+	"time"
 
 	sloggger "github.com/hectorgimenez/koolo/cmd/koolo/log"
 	"github.com/hectorgimenez/koolo/internal/bot"
@@ -15,7 +16,11 @@ import (
 	"github.com/hectorgimenez/koolo/internal/event"
 	"github.com/hectorgimenez/koolo/internal/remote/discord"
 	"github.com/hectorgimenez/koolo/internal/remote/droplog"
+	"github.com/hectorgimenez/koolo/internal/remote/eventlog"
+	"github.com/hectorgimenez/koolo/internal/remote/metrics"
+	"github.com/hectorgimenez/koolo/internal/remote/runlog"
 	"github.com/hectorgimenez/koolo/internal/remote/telegram"
+	"github.com/hectorgimenez/koolo/internal/remote/webhook"
 	"github.com/hectorgimenez/koolo/internal/server"
 	"github.com/hectorgimenez/koolo/internal/utils"
 	"github.com/hectorgimenez/koolo/internal/utils/winproc"
@@ -87,10 +92,22 @@ func main() {
 	dropDir := filepath.Join(dropBase, "droplogs")
 	dropWriter := droplog.NewWriter(dropDir, logger)
 	eventListener.Register(dropWriter.Handle)
-	manager := bot.NewSupervisorManager(logger, eventListener)
+	runStore := runlog.NewStore(filepath.Join(dropBase, "runlogs"), logger)
+	eventListener.Register(runStore.Handle)
+	if config.Koolo.Debug.RecordEvents {
+		eventRecorder := eventlog.NewRecorder(filepath.Join(dropBase, "events"), logger)
+		unsubscribe := eventListener.Register(eventRecorder.Handle)
+		defer func() {
+			unsubscribe()
+			eventRecorder.Close()
+		}()
+	}
+	manager := bot.NewSupervisorManager(logger, eventListener, runStore)
+	metricsCollector := metrics.NewCollector(manager, eventListener)
+	eventListener.Register(metricsCollector.Handle)
 	scheduler := bot.NewScheduler(manager, logger)
 	go scheduler.Start()
-	srv, err := server.New(logger, manager)
+	srv, err := server.New(logger, manager, metricsCollector, runStore)
 	if err != nil {
 		log.Fatalf("Error starting local server: %s", err.Error())
 	}
@@ -130,7 +147,8 @@ func main() {
 			return
 		}
 
-		eventListener.Register(discordBot.Handle)
+		// Remote notifications are not worth stalling the bot for, drop them if the API is too slow to keep up
+		eventListener.RegisterWithOptions(discordBot.Handle, event.HandlerOptions{Name: "discord", QueueSize: 64, Policy: event.QueueDrop, Timeout: 30 * time.Second})
 		g.Go(wrapWithRecover(logger, func() error {
 			return discordBot.Start(ctx)
 		}))
@@ -144,12 +162,25 @@ func main() {
 			return
 		}
 
-		eventListener.Register(telegramBot.Handle)
+		eventListener.RegisterWithOptions(telegramBot.Handle, event.HandlerOptions{Name: "telegram", QueueSize: 64, Policy: event.QueueDrop, Timeout: 30 * time.Second})
 		g.Go(wrapWithRecover(logger, func() error {
 			return telegramBot.Start(ctx)
 		}))
 	}
 
+	// Webhooks get a queue each, a slow endpoint only delays its own notifications. The timeout covers the retries.
+	for _, webhookCfg := range config.Koolo.Webhooks {
+		if !webhookCfg.Enabled {
+			continue
+		}
+		notifier, err := webhook.NewNotifier(webhookCfg, logger)
+		if err != nil {
+			logger.Error("Webhook could not been initialized", slog.Any("error", err))
+			continue
+		}
+		eventListener.RegisterWithOptions(notifier.Handle, event.HandlerOptions{Name: "webhook/" + notifier.Name(), QueueSize: 64, Policy: event.QueueDrop, Timeout: 2 * time.Minute})
+	}
+
 	g.Go(wrapWithRecover(logger, func() error {
 		defer cancel()
 		return srv.Listen(8087)
@@ -0,0 +1,84 @@
+// Command replay feeds sessions recorded with Debug.RecordEvents back to the event handlers, so the run and drop
+// logs of a session can be reproduced and inspected offline.
+//
+//	replay [-out dir] [-speed n] [-supervisor name] recording.jsonl|recordings-dir...
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"github.com/hectorgimenez/koolo/internal/event"
+	"github.com/hectorgimenez/koolo/internal/remote/droplog"
+	"github.com/hectorgimenez/koolo/internal/remote/eventlog"
+	"github.com/hectorgimenez/koolo/internal/remote/runlog"
+)
+
+func main() {
+	out := flag.String("out", "replay", "directory the run and drop logs are written to")
+	speed := flag.Float64("speed", 0, "1 keeps the recorded pace, 2 is twice as fast, 0 replays without waiting")
+	supervisor := flag.String("supervisor", "", "only replay the events of this supervisor")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] recording.jsonl|recordings-dir...\n", filepath.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	files, err := recordings(flag.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if len(files) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	listener := event.NewSyncListener(logger)
+	listener.Register(droplog.NewWriter(filepath.Join(*out, "droplogs"), logger).Handle)
+	listener.Register(runlog.NewStore(filepath.Join(*out, "runlogs"), logger).Handle)
+	listener.Register(func(_ context.Context, e event.Event) error {
+		fmt.Printf("%s [%s] %s: %s\n", e.OccurredAt().Format(time.DateTime), e.Supervisor(), event.TypeTag(e), e.Message())
+		return nil
+	})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	opts := eventlog.ReplayOptions{Speed: *speed, Supervisor: *supervisor}
+	count, err := eventlog.NewReplayer(listener, logger).ReplayFiles(ctx, opts, files...)
+	logger.Info("Replay finished", slog.Int("events", count), slog.String("out", *out))
+	if err != nil {
+		logger.Error("Replay failed", slog.Any("error", err))
+		os.Exit(1)
+	}
+}
+
+// recordings expands the directories in args to the recordings they contain, oldest first
+func recordings(args []string) ([]string, error) {
+	var files []string
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, arg)
+			continue
+		}
+		dirFiles, err := eventlog.Recordings(arg)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, dirFiles...)
+	}
+
+	return files, nil
+}
@@ -31,7 +31,22 @@ func ClearCurrentLevel(openChests bool, filter data.MonsterFilter) error {
 
 	// We can make this configurable later, but 20 is a good starting radius.
 	const pickupRadius = 20
-	rooms := ctx.PathFinder.OptimizeRoomsTraverseOrder()
+	rooms := ctx.PathFinder.ExploreRooms(func(r data.Room) bool {
+		// Super uniques and super chests first, they have the best drops
+		for _, m := range ctx.Data.Monsters.Enemies() {
+			if m.Type == data.MonsterTypeSuperUnique && r.IsInside(m.Position) {
+				return true
+			}
+		}
+		if openChests {
+			for _, o := range ctx.Data.Objects {
+				if o.IsSuperChest() && o.Selectable && r.IsInside(o.Position) {
+					return true
+				}
+			}
+		}
+		return false
+	})
 	for _, r := range rooms {
 		if errDeath := checkPlayerDeath(ctx); errDeath != nil {
 			return errDeath
@@ -39,7 +54,7 @@ func ClearCurrentLevel(openChests bool, filter data.MonsterFilter) error {
 		// First, clear the room of monsters
 		err := clearRoom(r, filter)
 		if err != nil {
-			ctx.Logger.Warn("Failed to clear room: %v", err)
+			ctx.Logger.Warn("Failed to clear room", "error", err)
 		}
 
 		//ctx.Logger.Debug(fmt.Sprintf("Clearing room complete, attempting to pickup items in a radius of %d", pickupRadius))
@@ -48,27 +63,31 @@ func ClearCurrentLevel(openChests bool, filter data.MonsterFilter) error {
 			ctx.Logger.Warn("Failed to pickup items", slog.Any("error", err))
 		}
 
-		// Iterate through objects in the current room
+		// Interact with chests if openChests is true, in the order that walks the least
+		var chests []data.Object
+		var chestPositions []data.Position
 		for _, o := range ctx.Data.Objects {
-			if r.IsInside(o.Position) {
-				// Interact with chests if openChests is true
-				if openChests && o.IsChest() && o.Selectable {
-					ctx.Logger.Debug(fmt.Sprintf("Found chest. attempting to interact. Name=%s. ID=%v UnitID=%v Pos=%v,%v Area='%s' InteractType=%v", o.Desc().Name, o.Name, o.ID, o.Position.X, o.Position.Y, ctx.Data.PlayerUnit.Area.Area().Name, o.InteractType))
-					err = MoveToCoords(o.Position)
-					if err != nil {
-						ctx.Logger.Warn("Failed moving to chest", slog.Any("error", err))
-						continue
-					}
-					err = InteractObject(o, func() bool {
-						chest, _ := ctx.Data.Objects.FindByID(o.ID)
-						return !chest.Selectable
-					})
-					if err != nil {
-						ctx.Logger.Warn("Failed interacting with chest", slog.Any("error", err))
-					}
-					utils.Sleep(500) // Add small delay to allow the game to open the chest and drop the content
-				}
+			if openChests && r.IsInside(o.Position) && o.IsChest() && o.Selectable {
+				chests = append(chests, o)
+				chestPositions = append(chestPositions, o.Position)
+			}
+		}
+		for _, i := range ctx.PathFinder.VisitOrder(chestPositions) {
+			o := chests[i]
+			ctx.Logger.Debug(fmt.Sprintf("Found chest. attempting to interact. Name=%s. ID=%v UnitID=%v Pos=%v,%v Area='%s' InteractType=%v", o.Desc().Name, o.Name, o.ID, o.Position.X, o.Position.Y, ctx.Data.PlayerUnit.Area.Area().Name, o.InteractType))
+			err = MoveToCoords(o.Position)
+			if err != nil {
+				ctx.Logger.Warn("Failed moving to chest", slog.Any("error", err))
+				continue
+			}
+			err = InteractObject(o, func() bool {
+				chest, _ := ctx.Data.Objects.FindByID(o.ID)
+				return !chest.Selectable
+			})
+			if err != nil {
+				ctx.Logger.Warn("Failed interacting with chest", slog.Any("error", err))
 			}
+			utils.Sleep(500) // Add small delay to allow the game to open the chest and drop the content
 		}
 	}
 
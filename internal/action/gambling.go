@@ -15,7 +15,6 @@ import (
 	"github.com/hectorgimenez/koolo/internal/town"
 	"github.com/hectorgimenez/koolo/internal/ui"
 	"github.com/hectorgimenez/koolo/internal/utils"
-	"github.com/lxn/win"
 )
 
 func Gamble() error {
@@ -39,9 +38,9 @@ func Gamble() error {
 		InteractNPC(vendorNPC)
 		// Jamella gamble button is the second one
 		if vendorNPC == npc.Jamella {
-			ctx.HID.KeySequence(win.VK_HOME, win.VK_DOWN, win.VK_RETURN)
+			ctx.HID.KeySequence(game.VKHome, game.VKDown, game.VKReturn)
 		} else {
-			ctx.HID.KeySequence(win.VK_HOME, win.VK_DOWN, win.VK_DOWN, win.VK_RETURN)
+			ctx.HID.KeySequence(game.VKHome, game.VKDown, game.VKDown, game.VKReturn)
 		}
 
 		if !ctx.Data.OpenMenus.NPCShop {
@@ -78,9 +77,9 @@ func GambleSingleItem(items []string, desiredQuality item.Quality) error {
 		InteractNPC(vendorNPC)
 		// Jamella gamble button is the second one
 		if vendorNPC == npc.Jamella {
-			ctx.HID.KeySequence(win.VK_HOME, win.VK_DOWN, win.VK_RETURN)
+			ctx.HID.KeySequence(game.VKHome, game.VKDown, game.VKReturn)
 		} else {
-			ctx.HID.KeySequence(win.VK_HOME, win.VK_DOWN, win.VK_DOWN, win.VK_RETURN)
+			ctx.HID.KeySequence(game.VKHome, game.VKDown, game.VKDown, game.VKReturn)
 		}
 
 		if !ctx.Data.OpenMenus.NPCShop {
@@ -224,9 +223,9 @@ func gambleItems() error {
 
 				// Select gamble option
 				if vendorNPC == npc.Jamella {
-					ctx.HID.KeySequence(win.VK_HOME, win.VK_DOWN, win.VK_RETURN)
+					ctx.HID.KeySequence(game.VKHome, game.VKDown, game.VKReturn)
 				} else {
-					ctx.HID.KeySequence(win.VK_HOME, win.VK_DOWN, win.VK_DOWN, win.VK_RETURN)
+					ctx.HID.KeySequence(game.VKHome, game.VKDown, game.VKDown, game.VKReturn)
 				}
 
 				refreshAttempts = 0
@@ -355,14 +355,22 @@ func GetItemsToPickup(maxDistance int) []data.Item {
 
 	// Remove blacklisted items from the list, we don't want to pick them up
 	filteredItems := make([]data.Item, 0, len(itemsToPickup))
+	positions := make([]data.Position, 0, len(itemsToPickup))
 	for _, itm := range itemsToPickup {
 		isBlacklisted := IsBlacklisted(itm)
 		if !isBlacklisted {
 			filteredItems = append(filteredItems, itm)
+			positions = append(positions, itm.Position)
 		}
 	}
 
-	return filteredItems
+	// Sort them so picking them up one after the other is a single short loop instead of going back and forth
+	orderedItems := make([]data.Item, 0, len(filteredItems))
+	for _, i := range ctx.PathFinder.VisitOrder(positions) {
+		orderedItems = append(orderedItems, filteredItems[i])
+	}
+
+	return orderedItems
 }
 
 func shouldBePickedUp(i data.Item) bool {
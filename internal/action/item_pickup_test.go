@@ -0,0 +1,82 @@
+package action_test
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/d2go/pkg/data/npc"
+	"github.com/hectorgimenez/d2go/pkg/data/skill"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
+	"github.com/hectorgimenez/d2go/pkg/nip"
+	"github.com/hectorgimenez/koolo/internal/action"
+	"github.com/hectorgimenez/koolo/internal/character"
+	"github.com/hectorgimenez/koolo/internal/context"
+	"github.com/hectorgimenez/koolo/internal/game"
+	"github.com/hectorgimenez/koolo/internal/game/sim"
+)
+
+// simContext creates a backend for the scenario with the given pickit rules, and attaches a bot context driven by it
+// to the test goroutine
+func simContext(t *testing.T, s sim.Scenario, rules ...string) (*sim.Backend, *context.Status) {
+	t.Helper()
+
+	for i, line := range rules {
+		rule, err := nip.NewRule(line, "test.nip", i+1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.CharacterCfg.Runtime.Rules = append(s.CharacterCfg.Runtime.Rules, rule)
+	}
+	if s.CharacterCfg.Character.Class == "" {
+		s.CharacterCfg.Character.Class = "hammerdin"
+	}
+
+	b := sim.NewBackend(s)
+	ctx := sim.NewContext(t.Name(), b, &s.CharacterCfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	t.Cleanup(ctx.Detach)
+
+	char, err := character.BuildCharacter(ctx.Context)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx.Char = char
+
+	return b, ctx
+}
+
+func TestItemPickup(t *testing.T) {
+	b, _ := simContext(t, sim.Scenario{
+		Seed:   1,
+		Areas:  map[area.ID]game.AreaData{area.BloodMoor: sim.OpenArea(area.BloodMoor, 100, 100, 60, 60)},
+		Player: data.PlayerUnit{Area: area.BloodMoor, Position: data.Position{X: 120, Y: 120}},
+		Monsters: map[area.ID]data.Monsters{
+			area.BloodMoor: {{UnitID: 30, Name: npc.Zombie, Position: data.Position{X: 137, Y: 129}, Stats: map[stat.ID]int{stat.Life: 150}}},
+		},
+		GroundItems: map[area.ID][]data.Item{
+			area.BloodMoor: {
+				sim.NewItem(20, "Ring", item.QualityUnique, data.Position{X: 135, Y: 128}),
+				sim.NewItem(21, "Amulet", item.QualityMagic, data.Position{X: 124, Y: 118}),
+			},
+		},
+		KeyBindings: sim.DefaultKeyBindings(skill.Concentration, skill.BlessedHammer),
+	}, "[name] == ring && [quality] == unique")
+
+	if err := action.ItemPickup(30); err != nil {
+		t.Fatal(err)
+	}
+
+	if alive := b.GetData().Monsters.Enemies(); len(alive) != 0 {
+		t.Fatalf("expected the zombie next to the ring to be killed, got %+v", alive)
+	}
+	inv := b.GetInventory()
+	if picked := inv.ByLocation(item.LocationInventory); len(picked) != 1 || picked[0].UnitID != 20 {
+		t.Fatalf("expected only the unique ring to be picked up, got %+v", picked)
+	}
+	if ground := inv.ByLocation(item.LocationGround); len(ground) != 1 || ground[0].UnitID != 21 {
+		t.Fatalf("expected the amulet to stay on the ground, got %+v", ground)
+	}
+}
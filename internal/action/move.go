@@ -397,6 +397,9 @@ func MoveTo(toFunc func() (data.Position, bool), options ...step.MoveOption) err
 	var pathFound bool
 	var pathErrors int
 	var stuck bool
+	var plan teleportPlan
+	var pathStale bool // Teleported along the hop plan since the path was computed
+	watchdog := newMovementWatchdog()
 	blacklistedInteractions := map[data.UnitID]bool{}
 	adjustMinDist := false
 
@@ -432,6 +435,8 @@ func MoveTo(toFunc func() (data.Position, bool), options ...step.MoveOption) err
 			//Safety first, handle enemies
 			if !opts.IgnoreMonsters() && (!ctx.Data.CanTeleport() || overrideClearPathDist) && time.Since(actionLastMonsterHandlingTime) > monsterHandleCooldown {
 				actionLastMonsterHandlingTime = time.Now()
+				// Fighting and looting is not being stuck
+				fightStart := time.Now()
 				filters := opts.MonsterFilters()
 				filters = append(filters, func(monsters data.Monsters) (filteredMonsters []data.Monster) {
 					for _, m := range monsters {
@@ -449,6 +454,7 @@ func MoveTo(toFunc func() (data.Position, bool), options ...step.MoveOption) err
 						ctx.Logger.Warn("Error picking up items after combat", slog.String("error", lootErr.Error()))
 					}
 				}
+				watchdog.pause(time.Since(fightStart))
 			}
 
 			//Check shrine nearby
@@ -491,9 +497,10 @@ func MoveTo(toFunc func() (data.Position, bool), options ...step.MoveOption) err
 			targetPosition = chest.Position
 		}
 
-		//Only recompute path if needed, it can be heavy
-		if !utils.IsSamePosition(previousTargetPosition, targetPosition) || !pathFound {
+		//Only recompute path if needed, it can be heavy. Teleporting along the hop plan doesn't need it.
+		if !utils.IsSamePosition(previousTargetPosition, targetPosition) || ((!pathFound || pathStale) && !plan.following(targetPosition)) {
 			previousTargetPosition = targetPosition
+			pathStale = false
 			path, _, pathFound = ctx.PathFinder.GetPath(targetPosition)
 			pathOffsetX, pathOffsetY = getPathOffsets(targetPosition)
 		}
@@ -506,17 +513,21 @@ func MoveTo(toFunc func() (data.Position, bool), options ...step.MoveOption) err
 				if err := UsePortalInTown(); err != nil {
 					return errors.New("path failed during moveto. player in town, target position outside of town and no tp")
 				}
+			} else if ctx.Data.CanTeleport() && plan.reachable(ctx, targetPosition) {
+				//No tile path but the hops get there, like crossing the Arcane Sanctuary void
+				pathErrors = 0
 			} else {
 				pathErrors++
-				//Try some randome movements to help pathfinding (not sure that it helps)
-				if pathErrors < 5 {
+				//Try some randome movements to help pathfinding (not sure that it helps), then escalate
+				if pathErrors < 3 {
 					ctx.Logger.Warn("No path found, trying random movement to fix")
 					ctx.PathFinder.RandomMovement()
 					utils.Sleep(200)
 					continue
-				} else {
-					return errors.New("path could not be calculated. Current area: [" + ctx.Data.PlayerUnit.Area.Area().Name + "]. Trying to path to Destination: [" + fmt.Sprintf("%d,%d", to.X, to.Y) + "]")
+				} else if err := watchdog.recover(stuckReasonUnreachable, data.Position{}); err != nil {
+					return fmt.Errorf("path could not be calculated. Current area: [%s]. Trying to path to Destination: [%d,%d]: %w", ctx.Data.PlayerUnit.Area.Area().Name, to.X, to.Y, err)
 				}
+				continue
 			}
 		} else {
 			pathErrors = 0
@@ -538,6 +549,7 @@ func MoveTo(toFunc func() (data.Position, bool), options ...step.MoveOption) err
 
 		//We've reached our target destination !
 		if distanceToTarget <= finishMoveDist || (adjustMinDist && distanceToTarget <= finishMoveDist*2) {
+			interactionStart := time.Now()
 			if shrine.ID != 0 && targetPosition == shrine.Position {
 				//Handle shrine if any
 				if err := InteractObject(shrine, func() bool {
@@ -548,6 +560,7 @@ func MoveTo(toFunc func() (data.Position, bool), options ...step.MoveOption) err
 				}
 				blacklistedInteractions[shrine.ID] = true
 				shrine = data.Object{}
+				watchdog.pause(time.Since(interactionStart))
 				continue
 			} else if chest.ID != 0 && targetPosition == chest.Position {
 				//Handle chest if any
@@ -565,6 +578,7 @@ func MoveTo(toFunc func() (data.Position, bool), options ...step.MoveOption) err
 					}
 				}
 				chest = data.Object{}
+				watchdog.pause(time.Since(interactionStart))
 				continue
 			}
 
@@ -577,6 +591,7 @@ func MoveTo(toFunc func() (data.Position, bool), options ...step.MoveOption) err
 		//We're not done yet, split the path into smaller segments when outside of town
 		nextPosition := targetPosition
 		pathStep := 0
+		castHop := false
 		if !ctx.Data.AreaData.Area.IsTown() {
 			//Default path step when teleporting
 			maxPathStep := 10
@@ -594,27 +609,63 @@ func MoveTo(toFunc func() (data.Position, bool), options ...step.MoveOption) err
 			}
 
 			//Pick target position on path and convert path position to global coordinates
-			pathStep = min(maxPathStep, len(path)-1)
-			nextPathPos := path[pathStep]
-			nextPosition = utils.PositionAddCoords(nextPathPos, pathOffsetX, pathOffsetY)
-			if pather.DistanceFromPoint(nextPosition, targetPosition) <= minDistanceToFinishMoving {
-				nextPosition = targetPosition
+			if len(path) > 0 {
+				pathStep = min(maxPathStep, len(path)-1)
+				nextPathPos := path[pathStep]
+				nextPosition = utils.PositionAddCoords(nextPathPos, pathOffsetX, pathOffsetY)
+				if pather.DistanceFromPoint(nextPosition, targetPosition) <= minDistanceToFinishMoving {
+					nextPosition = targetPosition
+				}
+			}
+
+			//Teleport straight to the next planned hop, the tile path is kept as fallback when there is no plan
+			if ctx.Data.CanTeleport() {
+				if hop, hopFound := plan.next(ctx, targetPosition); hopFound {
+					nextPosition = hop.To
+					pathStep = 0
+					castHop = true
+				}
+			}
+		}
+
+		//Moving but not getting any closer (going back and forth between two spots for example). Straight line distance
+		//is the same whether walking the path or teleporting along the hops.
+		if reason, isStuck := watchdog.track(targetPosition, distanceToTarget, time.Now()); isStuck {
+			previousTargetPosition = data.Position{}
+			plan.reset()
+			if err := watchdog.recover(reason, tileAhead(ctx.Data.PlayerUnit.Position, path, pathOffsetX, pathOffsetY)); err != nil {
+				return err
 			}
+			continue
 		}
 
-		//Do the actual movement...
-		moveErr := step.MoveTo(nextPosition, moveOptions...)
+		//Do the actual movement, hops are cast as planned, they may cross tiles the tile path can't...
+		var moveErr error
+		if castHop {
+			moveErr = step.Teleport(nextPosition)
+		} else {
+			moveErr = step.MoveTo(nextPosition, moveOptions...)
+		}
 		if moveErr != nil {
-			//... Reset previous target position to recompute path...
+			//... Reset previous target position and hops to recompute them...
 			previousTargetPosition = (data.Position{})
+			plan.reset()
 
 			//... and handle errors if possible
 			if errors.Is(moveErr, step.ErrMonstersInPath) {
 				continue
 			} else if errors.Is(moveErr, step.ErrPlayerStuck) || errors.Is(moveErr, step.ErrPlayerRoundTrip) {
-				if (!ctx.Data.CanTeleport() || stuck) || ctx.Data.PlayerUnit.Area.IsTown() {
-					ctx.PathFinder.RandomMovement()
-					time.Sleep(time.Millisecond * 200)
+				//Teleporting characters retry once before any recovery, a cast can be lost to lag
+				if ctx.Data.CanTeleport() && !stuck {
+					stuck = true
+					continue
+				}
+				reason := stuckReasonBlocked
+				if errors.Is(moveErr, step.ErrPlayerRoundTrip) {
+					reason = stuckReasonOscillation
+				}
+				if err := watchdog.recover(reason, tileAhead(ctx.Data.PlayerUnit.Position, path, pathOffsetX, pathOffsetY)); err != nil {
+					return err
 				}
 				stuck = true
 				continue
@@ -632,6 +683,10 @@ func MoveTo(toFunc func() (data.Position, bool), options ...step.MoveOption) err
 
 		stuck = false
 		previousPosition = ctx.Data.PlayerUnit.Position
+		if castHop {
+			plan.advance()
+			pathStale = true
+		}
 		//If we're not in town and moved without errors, move forward in the path
 		if !ctx.Data.AreaData.Area.IsTown() {
 			path = path[pathStep:]
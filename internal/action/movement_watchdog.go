@@ -0,0 +1,256 @@
+package action
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/koolo/internal/action/step"
+	"github.com/hectorgimenez/koolo/internal/context"
+	"github.com/hectorgimenez/koolo/internal/event"
+	"github.com/hectorgimenez/koolo/internal/pather"
+	"github.com/hectorgimenez/koolo/internal/utils"
+)
+
+const (
+	// Time without getting any closer to the target before it's considered stuck, even if the player keeps moving
+	noProgressTimeout = 15 * time.Second
+	// Tiles the player has to get closer to the target after a recovery to start the escalation again from the first
+	// strategy
+	recoveredProgress = 10
+	// Radius of the tiles blocked when re-pathing around the spot where the player got stuck
+	blockedTileRadius = 1
+	// Max distance of the random position used to get out of the spot
+	randomPositionRadius = 15
+)
+
+var ErrMovementUnrecoverable = errors.New("player is stuck and every recovery failed")
+
+type stuckReason string
+
+const (
+	stuckReasonBlocked     stuckReason = "blocked"
+	stuckReasonDoor        stuckReason = "blocked by door"
+	stuckReasonObject      stuckReason = "blocked by object"
+	stuckReasonOscillation stuckReason = "oscillation"
+	stuckReasonNoProgress  stuckReason = "no progress"
+	stuckReasonUnreachable stuckReason = "unreachable"
+)
+
+type recoveryStrategy string
+
+const (
+	recoveryRepath         recoveryStrategy = "re-path"
+	recoveryOpenDoor       recoveryStrategy = "open door"
+	recoveryDestroyObject  recoveryStrategy = "destroy object"
+	recoveryRandomPosition recoveryStrategy = "random position"
+	recoveryTownPortal     recoveryStrategy = "town portal"
+)
+
+// Strategies are tried in this order, from the cheapest one to going back to town
+var recoveryStrategies = []recoveryStrategy{
+	recoveryRepath,
+	recoveryOpenDoor,
+	recoveryDestroyObject,
+	recoveryRandomPosition,
+	recoveryTownPortal,
+}
+
+// movementWatchdog tracks the progress of a MoveTo along its path and gets the player unstuck, every time it's stuck
+// the next recovery strategy is tried until the player makes progress again
+type movementWatchdog struct {
+	target       data.Position
+	bestProgress int // Shortest distance to the target so far
+	lastProgress time.Time
+	attempt      int // Next strategy to try
+	recoveredAt  int // Distance to the target when the last recovery was done
+}
+
+func newMovementWatchdog() *movementWatchdog {
+	return &movementWatchdog{lastProgress: time.Now(), bestProgress: -1}
+}
+
+// track registers the remaining distance to the target, it returns stuckReasonNoProgress when the player didn't get any
+// closer for too long
+func (w *movementWatchdog) track(target data.Position, remaining int, now time.Time) (stuckReason, bool) {
+	if target != w.target {
+		// New target, nothing to compare to
+		w.target = target
+		w.bestProgress = remaining
+		w.lastProgress = now
+		w.attempt = 0
+		return "", false
+	}
+
+	if w.bestProgress < 0 || remaining < w.bestProgress {
+		w.bestProgress = remaining
+		w.lastProgress = now
+		if w.attempt > 0 && w.recoveredAt-remaining >= recoveredProgress {
+			// Last recovery did the job
+			w.attempt = 0
+		}
+		return "", false
+	}
+
+	if now.Sub(w.lastProgress) > noProgressTimeout {
+		// Give the recovery some time before checking again
+		w.lastProgress = now
+		return stuckReasonNoProgress, true
+	}
+
+	return "", false
+}
+
+// pause keeps time spent on something else than moving, like fighting or looting, out of the no progress timeout
+func (w *movementWatchdog) pause(d time.Duration) {
+	w.lastProgress = w.lastProgress.Add(d)
+}
+
+// nextStrategy returns the strategies not tried yet since the last progress, in order
+func (w *movementWatchdog) nextStrategy() (recoveryStrategy, bool) {
+	if w.attempt >= len(recoveryStrategies) {
+		return "", false
+	}
+	s := recoveryStrategies[w.attempt]
+	w.attempt++
+
+	return s, true
+}
+
+// recover applies the next recovery strategy that can be done, strategies that don't apply (no door around, already
+// in town...) are skipped. blockedAt is the tile the player couldn't get into, empty if unknown.
+func (w *movementWatchdog) recover(reason stuckReason, blockedAt data.Position) error {
+	ctx := context.Get()
+	if reason == stuckReasonBlocked {
+		reason = classifyBlockage(ctx)
+	}
+	w.recoveredAt = w.bestProgress
+
+	for {
+		strategy, found := w.nextStrategy()
+		if !found {
+			return fmt.Errorf("%w: %s in %s", ErrMovementUnrecoverable, reason, ctx.Data.PlayerUnit.Area.Area().Name)
+		}
+
+		applied, err := applyRecovery(ctx, strategy, blockedAt)
+		if !applied {
+			continue
+		}
+
+		ctx.Logger.Info("Trying to recover movement",
+			slog.String("reason", string(reason)),
+			slog.String("strategy", string(strategy)),
+			slog.Int("attempt", w.attempt),
+			slog.Any("position", ctx.Data.PlayerUnit.Position),
+		)
+		event.Send(event.MovementRecovery(
+			event.Text(ctx.Name, fmt.Sprintf("Movement recovery: %s (%s)", strategy, reason)),
+			string(reason), string(strategy), w.attempt, ctx.Data.PlayerUnit.Area, ctx.Data.PlayerUnit.Position,
+		))
+		if err != nil {
+			ctx.Logger.Warn("Movement recovery failed", slog.String("strategy", string(strategy)), slog.Any("error", err))
+		}
+
+		return nil
+	}
+}
+
+// tileAhead returns the tile of the path right after the one closest to the player, the one the player couldn't step
+// into. Path positions are relative to the grid, offsetX and offsetY convert them back. Empty without a path.
+func tileAhead(player data.Position, path pather.Path, offsetX, offsetY int) data.Position {
+	if len(path) == 0 {
+		return data.Position{}
+	}
+
+	closest, closestDistance := 0, -1
+	for i, p := range path {
+		d := pather.DistanceFromPoint(player, utils.PositionAddCoords(p, offsetX, offsetY))
+		if closestDistance < 0 || d < closestDistance {
+			closest, closestDistance = i, d
+		}
+	}
+
+	return utils.PositionAddCoords(path[min(closest+1, len(path)-1)], offsetX, offsetY)
+}
+
+// classifyBlockage tells what is blocking the player, when it's something it can be interacted with
+func classifyBlockage(ctx *context.Status) stuckReason {
+	if _, found := ctx.PathFinder.GetClosestDoor(ctx.Data.PlayerUnit.Position); found {
+		return stuckReasonDoor
+	}
+	if _, found := ctx.PathFinder.GetClosestDestructible(ctx.Data.PlayerUnit.Position); found {
+		return stuckReasonObject
+	}
+
+	return stuckReasonBlocked
+}
+
+// applyRecovery returns false when the strategy can't be applied right now
+func applyRecovery(ctx *context.Status, strategy recoveryStrategy, blockedAt data.Position) (bool, error) {
+	switch strategy {
+	case recoveryRepath:
+		if blockedAt == (data.Position{}) || ctx.Data.PlayerUnit.Area.IsTown() {
+			return false, nil
+		}
+		ctx.PathFinder.BlockTile(blockedAt, blockedTileRadius)
+		return true, nil
+	case recoveryOpenDoor:
+		door, found := ctx.PathFinder.GetClosestDoor(ctx.Data.PlayerUnit.Position)
+		if !found {
+			return false, nil
+		}
+		return true, InteractObject(*door, func() bool {
+			d, found := ctx.Data.Objects.FindByID(door.ID)
+			return found && !d.Selectable
+		})
+	case recoveryDestroyObject:
+		obj, found := ctx.PathFinder.GetClosestDestructible(ctx.Data.PlayerUnit.Position)
+		if !found {
+			return false, nil
+		}
+		return true, InteractObject(*obj, func() bool {
+			o, found := ctx.Data.Objects.FindByID(obj.ID)
+			return !found || !o.Selectable
+		})
+	case recoveryRandomPosition:
+		if p, found := randomWalkablePosition(ctx, randomPositionRadius); found {
+			return true, step.MoveTo(p, step.WithIgnoreMonsters())
+		}
+		ctx.PathFinder.RandomMovement()
+		utils.Sleep(200)
+		return true, nil
+	case recoveryTownPortal:
+		if ctx.Data.PlayerUnit.Area.IsTown() {
+			return false, nil
+		}
+		if err := ReturnTown(); err != nil {
+			return true, err
+		}
+		return true, UsePortalInTown()
+	}
+
+	return false, nil
+}
+
+// randomWalkablePosition picks a walkable position around the player, it's in the current area so teleporting
+// characters can reach it in one cast most of the time
+func randomWalkablePosition(ctx *context.Status, radius int) (data.Position, bool) {
+	player := ctx.Data.PlayerUnit.Position
+	for range 20 {
+		p := data.Position{
+			X: player.X + rand.Intn(radius*2+1) - radius,
+			Y: player.Y + rand.Intn(radius*2+1) - radius,
+		}
+		if utils.CalculateDistance(player, p) < float64(radius)/2 {
+			continue
+		}
+		if ctx.Data.AreaData.IsInside(p) && ctx.Data.AreaData.IsWalkable(p) {
+			return p, true
+		}
+	}
+
+	return data.Position{}, false
+}
@@ -0,0 +1,178 @@
+package action
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/koolo/internal/pather"
+)
+
+func TestMovementWatchdogTrack(t *testing.T) {
+	start := time.Date(2025, 3, 14, 10, 0, 0, 0, time.UTC)
+	target := data.Position{X: 100, Y: 100}
+	other := data.Position{X: 200, Y: 200}
+
+	type sample struct {
+		target    data.Position
+		remaining int
+		after     time.Duration // Since start
+		stuck     bool
+	}
+	tests := []struct {
+		name    string
+		samples []sample
+	}{
+		{
+			name: "getting closer",
+			samples: []sample{
+				{target: target, remaining: 50},
+				{target: target, remaining: 40, after: 10 * time.Second},
+				{target: target, remaining: 30, after: 20 * time.Second},
+				{target: target, remaining: 20, after: 30 * time.Second},
+			},
+		},
+		{
+			name: "no progress timeout",
+			samples: []sample{
+				{target: target, remaining: 50},
+				{target: target, remaining: 50, after: 10 * time.Second},
+				{target: target, remaining: 55, after: noProgressTimeout},
+				{target: target, remaining: 50, after: noProgressTimeout + time.Second, stuck: true},
+				// Recovery gets some time before checking again
+				{target: target, remaining: 50, after: noProgressTimeout + 2*time.Second},
+				{target: target, remaining: 50, after: 2*noProgressTimeout + 2*time.Second, stuck: true},
+			},
+		},
+		{
+			name: "progress resets the timeout",
+			samples: []sample{
+				{target: target, remaining: 50},
+				{target: target, remaining: 49, after: noProgressTimeout},
+				{target: target, remaining: 50, after: noProgressTimeout + time.Second},
+				{target: target, remaining: 50, after: 2 * noProgressTimeout},
+				{target: target, remaining: 50, after: 2*noProgressTimeout + time.Second, stuck: true},
+			},
+		},
+		{
+			name: "new target resets the timeout",
+			samples: []sample{
+				{target: target, remaining: 50},
+				{target: target, remaining: 50, after: noProgressTimeout},
+				{target: other, remaining: 80, after: noProgressTimeout + time.Second},
+				{target: other, remaining: 80, after: 2 * noProgressTimeout},
+				{target: other, remaining: 80, after: 2*noProgressTimeout + 2*time.Second, stuck: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newMovementWatchdog()
+			for i, s := range tt.samples {
+				reason, stuck := w.track(s.target, s.remaining, start.Add(s.after))
+				if stuck != s.stuck {
+					t.Fatalf("sample %d: stuck = %v, want %v", i, stuck, s.stuck)
+				}
+				if stuck && reason != stuckReasonNoProgress {
+					t.Errorf("sample %d: reason = %s, want %s", i, reason, stuckReasonNoProgress)
+				}
+			}
+		})
+	}
+}
+
+func TestMovementWatchdogPause(t *testing.T) {
+	start := time.Date(2025, 3, 14, 10, 0, 0, 0, time.UTC)
+	target := data.Position{X: 100, Y: 100}
+
+	w := newMovementWatchdog()
+	w.track(target, 50, start)
+	// A long fight, the player doesn't move at all
+	w.pause(2 * noProgressTimeout)
+	if _, stuck := w.track(target, 50, start.Add(2*noProgressTimeout+time.Second)); stuck {
+		t.Fatal("time spent fighting counted as no progress")
+	}
+	if _, stuck := w.track(target, 50, start.Add(3*noProgressTimeout+time.Second)); !stuck {
+		t.Error("expected no progress once the fight is over")
+	}
+}
+
+func TestMovementWatchdogStrategies(t *testing.T) {
+	start := time.Date(2025, 3, 14, 10, 0, 0, 0, time.UTC)
+	target := data.Position{X: 100, Y: 100}
+
+	tests := []struct {
+		name string
+		// Strategies taken before the progress, and the remaining path after the recovery
+		taken     int
+		remaining int
+		want      recoveryStrategy
+	}{
+		{name: "escalates without progress", taken: 2, remaining: 50, want: recoveryDestroyObject},
+		{name: "small progress keeps escalating", taken: 2, remaining: 50 - recoveredProgress + 1, want: recoveryDestroyObject},
+		{name: "progress after a recovery starts over", taken: 2, remaining: 50 - recoveredProgress, want: recoveryRepath},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newMovementWatchdog()
+			w.track(target, 50, start)
+			for range tt.taken {
+				if _, found := w.nextStrategy(); !found {
+					t.Fatal("ran out of strategies")
+				}
+			}
+			w.recoveredAt = w.bestProgress
+			w.track(target, tt.remaining, start.Add(time.Second))
+
+			if got, _ := w.nextStrategy(); got != tt.want {
+				t.Errorf("next strategy = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMovementWatchdogExhaustion(t *testing.T) {
+	w := newMovementWatchdog()
+	for i, want := range recoveryStrategies {
+		got, found := w.nextStrategy()
+		if !found || got != want {
+			t.Fatalf("strategy %d = %s (%v), want %s", i, got, found, want)
+		}
+	}
+	if s, found := w.nextStrategy(); found {
+		t.Errorf("every strategy was tried, got %s", s)
+	}
+
+	// A new target starts over
+	w.track(data.Position{X: 1, Y: 1}, 10, time.Now())
+	if s, found := w.nextStrategy(); !found || s != recoveryStrategies[0] {
+		t.Errorf("new target strategy = %s (%v), want %s", s, found, recoveryStrategies[0])
+	}
+}
+
+func TestTileAhead(t *testing.T) {
+	// Relative to a grid with origin 100,100
+	path := pather.Path{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0}, {X: 3, Y: 0}, {X: 4, Y: 0}}
+
+	tests := []struct {
+		name   string
+		player data.Position
+		path   pather.Path
+		want   data.Position
+	}{
+		{name: "path start", player: data.Position{X: 100, Y: 100}, path: path, want: data.Position{X: 101, Y: 100}},
+		{name: "along the path", player: data.Position{X: 102, Y: 100}, path: path, want: data.Position{X: 103, Y: 100}},
+		{name: "path end", player: data.Position{X: 104, Y: 100}, path: path, want: data.Position{X: 104, Y: 100}},
+		{name: "no path", player: data.Position{X: 100, Y: 100}, want: data.Position{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tileAhead(tt.player, tt.path, 100, 100); got != tt.want {
+				t.Errorf("tileAhead = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,7 +4,7 @@ import (
 	"github.com/hectorgimenez/d2go/pkg/data/npc"
 	botCtx "github.com/hectorgimenez/koolo/internal/context" // ALIAS THIS IMPORT
 	"github.com/hectorgimenez/koolo/internal/town"
-	"github.com/lxn/win"
+	"github.com/hectorgimenez/koolo/internal/game"
 	"github.com/hectorgimenez/d2go/pkg/data/item"
 	"github.com/hectorgimenez/d2go/pkg/data/stat"
 )
@@ -25,9 +25,9 @@ func ReviveMerc() {
 		InteractNPC(mercNPC)
 
 		if mercNPC == npc.Tyrael2 {
-			status.HID.KeySequence(win.VK_END, win.VK_UP, win.VK_RETURN, win.VK_ESCAPE)
+			status.HID.KeySequence(game.VKEnd, game.VKUp, game.VKReturn, game.VKEscape)
 		} else {
-			status.HID.KeySequence(win.VK_HOME, win.VK_DOWN, win.VK_RETURN, win.VK_ESCAPE)
+			status.HID.KeySequence(game.VKHome, game.VKDown, game.VKReturn, game.VKEscape)
 		}
 	}
 }
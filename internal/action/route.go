@@ -0,0 +1,70 @@
+package action
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/koolo/internal/context"
+	"github.com/hectorgimenez/koolo/internal/pather/route"
+)
+
+// TravelTo goes to any level of the game, using the cheapest combination of waypoints, town portals and level exits
+func TravelTo(dst area.ID) error {
+	ctx := context.Get()
+	ctx.SetLastAction("TravelTo")
+
+	if err := checkPlayerDeath(ctx); err != nil {
+		return err
+	}
+
+	steps, cost, found := newRoutePlanner(ctx).Plan(ctx.Data.PlayerUnit.Area, ctx.Data.PlayerUnit.Position, dst)
+	if !found {
+		return fmt.Errorf("no route found from %s to %s", ctx.Data.PlayerUnit.Area.Area().Name, dst.Area().Name)
+	}
+	ctx.Logger.Debug("Route planned", slog.String("destination", dst.Area().Name), slog.Int("steps", len(steps)), slog.Int("cost", cost))
+
+	for _, s := range steps {
+		var err error
+		switch s.Kind {
+		case route.Waypoint:
+			err = WayPoint(s.Area)
+		case route.TownPortal:
+			err = ReturnTown()
+		default:
+			err = MoveToArea(s.Area)
+		}
+		if err != nil {
+			return fmt.Errorf("route step %s to %s failed: %w", s.Kind, s.Area.Area().Name, err)
+		}
+	}
+
+	return nil
+}
+
+func newRoutePlanner(ctx *context.Status) *route.Planner {
+	levels := make(map[area.ID]route.Level, len(ctx.Data.Areas))
+	for id, ad := range ctx.Data.Areas {
+		lvl := route.Level{Exits: ad.AdjacentLevels}
+		for _, o := range ad.Objects {
+			if o.IsWaypoint() {
+				lvl.Waypoint = o.Position
+				break
+			}
+		}
+		levels[id] = lvl
+	}
+
+	return route.NewPlanner(levels, discoveredWaypoints.available(waypointKey(ctx)), hasTownPortal(ctx))
+}
+
+// hasTownPortal tells if there is a tome with charges or a scroll to open a town portal with, like step.OpenPortal
+func hasTownPortal(ctx *context.Status) bool {
+	if NeedsTPsToContinue(ctx.Context) {
+		return true
+	}
+	_, found := ctx.Data.Inventory.Find(item.ScrollOfTownPortal, item.LocationInventory)
+
+	return found
+}
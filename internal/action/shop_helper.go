@@ -7,9 +7,9 @@ import (
 	"github.com/hectorgimenez/d2go/pkg/data/stat"
 	"github.com/hectorgimenez/koolo/internal/action/step"
 	"github.com/hectorgimenez/koolo/internal/context"
+	"github.com/hectorgimenez/koolo/internal/game"
 	"github.com/hectorgimenez/koolo/internal/town"
 	"github.com/hectorgimenez/koolo/internal/utils"
-	"github.com/lxn/win"
 )
 
 // BuyAct2Flails attempts to purchase 3-socket normal Flails from Fara in Act 2 for Barbarian characters.
@@ -58,7 +58,7 @@ func BuyAct2Flails(ctx *context.Status) error {
 			continue
 		}
 		// Trade option for Fara (first option is repair, second is trade)
-		ctx.HID.KeySequence(win.VK_HOME, win.VK_DOWN, win.VK_RETURN)
+		ctx.HID.KeySequence(game.VKHome, game.VKDown, game.VKReturn)
 		utils.Sleep(1000)
 
 		ctx.GameReader.GetData()
@@ -164,7 +164,7 @@ func BuyAct2BoneWands(ctx *context.Status) error {
 			continue
 		}
 		// Trade option for Drognan (first option is trade)
-		ctx.HID.KeySequence(win.VK_HOME, win.VK_DOWN, win.VK_RETURN)
+		ctx.HID.KeySequence(game.VKHome, game.VKDown, game.VKReturn)
 		utils.Sleep(1000)
 
 		ctx.GameReader.GetData()
@@ -19,7 +19,6 @@ import (
 	"github.com/hectorgimenez/koolo/internal/game"
 	"github.com/hectorgimenez/koolo/internal/ui"
 	"github.com/hectorgimenez/koolo/internal/utils"
-	"github.com/lxn/win"
 )
 
 const (
@@ -557,7 +556,7 @@ func CloseStash() error {
 	ctx.SetLastAction("CloseStash")
 
 	if ctx.Data.OpenMenus.Stash {
-		ctx.HID.PressKey(win.VK_ESCAPE)
+		ctx.HID.PressKey(game.VKEscape)
 
 	} else {
 		return errors.New("stash is not open")
@@ -0,0 +1,55 @@
+package action_test
+
+import (
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/d2go/pkg/data/object"
+	"github.com/hectorgimenez/koolo/internal/action"
+	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/game"
+	"github.com/hectorgimenez/koolo/internal/game/sim"
+)
+
+func TestStash(t *testing.T) {
+	ring := sim.NewItem(20, "Ring", item.QualityUnique, data.Position{X: 0, Y: 0})
+	ring.Location = item.Location{LocationType: item.LocationInventory}
+	ring.Identified = true
+	amulet := sim.NewItem(21, "Amulet", item.QualityMagic, data.Position{X: 1, Y: 0})
+	amulet.Location = item.Location{LocationType: item.LocationInventory}
+
+	cfg := config.CharacterCfg{}
+	// 1 marks the slots the bot is allowed to stash from
+	for range 4 {
+		cfg.Inventory.InventoryLock = append(cfg.Inventory.InventoryLock, []int{1, 1, 1, 1, 1, 1, 1, 1, 1, 1})
+	}
+
+	b, _ := simContext(t, sim.Scenario{
+		Seed:   1,
+		Areas:  map[area.ID]game.AreaData{area.RogueEncampment: sim.OpenArea(area.RogueEncampment, 100, 100, 60, 60)},
+		Player: data.PlayerUnit{Area: area.RogueEncampment, Position: data.Position{X: 120, Y: 120}},
+		Objects: map[area.ID][]data.Object{
+			area.RogueEncampment: {{ID: 40, Name: object.Bank, Position: data.Position{X: 130, Y: 125}, Selectable: true}},
+		},
+		Inventory:    data.Inventory{AllItems: []data.Item{ring, amulet}},
+		KeyBindings:  sim.DefaultKeyBindings(),
+		CharacterCfg: cfg,
+	}, "[name] == ring && [quality] == unique")
+
+	if err := action.Stash(false); err != nil {
+		t.Fatal(err)
+	}
+
+	inv := b.GetInventory()
+	if stashed := inv.ByLocation(item.LocationStash); len(stashed) != 1 || stashed[0].UnitID != 20 {
+		t.Fatalf("expected only the unique ring to be stashed, got %+v", stashed)
+	}
+	if kept := inv.ByLocation(item.LocationInventory); len(kept) != 1 || kept[0].UnitID != 21 {
+		t.Fatalf("expected the amulet to stay in the inventory, got %+v", kept)
+	}
+	if b.GetData().OpenMenus.IsMenuOpen() {
+		t.Fatal("expected the stash to be closed")
+	}
+}
@@ -17,6 +17,9 @@ import (
 const DistanceToFinishMoving = 4
 const stepMonsterCheckInterval = 100 * time.Millisecond
 
+// Time to see the player landing after a teleport cast before it's considered stuck
+const teleportLandTimeout = 2 * time.Second
+
 var (
 	ErrMonstersInPath  = errors.New("monsters detected in movement path")
 	ErrPlayerStuck     = errors.New("player is stuck")
@@ -304,8 +307,12 @@ func MoveTo(dest data.Position, options ...MoveOption) error {
 			}
 		}
 
-		//Compute path to reach destination
-		path, _, found := ctx.PathFinder.GetPath(currentDest)
+		//Compute path to reach destination, walking characters keep away from monsters when possible
+		getPath := ctx.PathFinder.GetPath
+		if !ctx.Data.CanTeleport() && !ctx.Data.AreaData.Area.IsTown() {
+			getPath = ctx.PathFinder.GetSafePath
+		}
+		path, _, found := getPath(currentDest)
 		if !found {
 			//Couldn't find path, abort movement
 			ctx.Logger.Warn("path could not be calculated. Current area: [" + ctx.Data.PlayerUnit.Area.Area().Name + "]. Trying to path to Destination: [" + fmt.Sprintf("%d,%d", currentDest.X, currentDest.Y) + "]")
@@ -325,3 +332,33 @@ func MoveTo(dest data.Position, options ...MoveOption) error {
 		ctx.PathFinder.MoveThroughPath(path, walkDuration)
 	}
 }
+
+// Teleport casts a single teleport at dest and waits for the player to land, dest has to be on screen. No path is
+// computed, so hops over non walkable tiles (Arcane Sanctuary platforms) land where they were planned.
+func Teleport(dest data.Position) error {
+	ctx := context.Get()
+	ctx.SetLastStep("Teleport")
+
+	if ctx.Data.PlayerUnit.RightSkill != skill.Teleport {
+		ctx.HID.PressKeyBinding(ctx.Data.KeyBindings.MustKBForSkill(skill.Teleport))
+	}
+
+	from := ctx.Data.PlayerUnit.Position
+	castAt := time.Now()
+	x, y := ctx.PathFinder.GameCoordsToScreenCords(dest.X, dest.Y)
+	ctx.HID.Click(game.RightButton, x, y)
+
+	for time.Since(castAt) < teleportLandTimeout {
+		time.Sleep(50 * time.Millisecond)
+		ctx.RefreshGameData()
+		if ctx.Data.PlayerUnit.Position != from {
+			// Next cast can't start before this one is done
+			if wait := ctx.Data.PlayerCastDuration() - time.Since(castAt); wait > 0 {
+				time.Sleep(wait)
+			}
+			return nil
+		}
+	}
+
+	return ErrPlayerStuck
+}
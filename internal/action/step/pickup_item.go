@@ -142,7 +142,7 @@ func PickupItemMouse(it data.Item, itemPickupAttempt int) error {
 		time.Sleep(spiralDelay)
 
 		// Click on item if mouse is hovering over
-		if currentItem.UnitID == ctx.GameReader.GameReader.GetData().HoverData.UnitID {
+		if currentItem.UnitID == ctx.GameReader.GetData().HoverData.UnitID {
 			ctx.HID.Click(game.LeftButton, cursorX, cursorY)
 			time.Sleep(clickDelay)
 
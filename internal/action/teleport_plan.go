@@ -0,0 +1,69 @@
+package action
+
+import (
+	"log/slog"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/koolo/internal/context"
+	"github.com/hectorgimenez/koolo/internal/pather"
+	"github.com/hectorgimenez/koolo/internal/pather/teleport"
+)
+
+// Max distance between the player and the start of the next hop to keep following the plan, landings can be a tile
+// or two away from the clicked position
+const hopLandingTolerance = 3
+
+// teleportPlan caches the hops to a target, planning searches the whole area grid so it's only done again when the
+// target changes or the player is not where the next hop starts
+type teleportPlan struct {
+	target  data.Position
+	hops    []teleport.Hop
+	planned bool // Planning was done for target
+	found   bool // There was a plan for target, hops is empty once all of them were cast
+}
+
+// next returns the hop to cast to get closer to target, false when there is no plan and the tile path has to be used
+func (p *teleportPlan) next(ctx *context.Status, target data.Position) (teleport.Hop, bool) {
+	if !p.planned || p.target != target || (p.found && (len(p.hops) == 0 || !p.onPlan(ctx.Data.PlayerUnit.Position))) {
+		p.target, p.planned = target, true
+		p.hops, p.found = ctx.PathFinder.GetTeleportHops(target)
+		if p.found && len(p.hops) > 0 {
+			ctx.Logger.Debug("Teleport hops planned",
+				slog.Int("hops", len(p.hops)),
+				slog.Duration("estimatedTime", teleport.Duration(p.hops, ctx.Data.PlayerCastDuration())))
+		}
+	}
+	if len(p.hops) == 0 {
+		return teleport.Hop{}, false
+	}
+
+	return p.hops[0], true
+}
+
+// reachable tells if there are hops to target, it plans when needed
+func (p *teleportPlan) reachable(ctx *context.Status, target data.Position) bool {
+	_, found := p.next(ctx, target)
+	return found
+}
+
+// following tells if there are hops left to cast to target without planning again
+func (p *teleportPlan) following(target data.Position) bool {
+	return p.planned && p.target == target && len(p.hops) > 0
+}
+
+func (p *teleportPlan) onPlan(player data.Position) bool {
+	return len(p.hops) > 0 && pather.DistanceFromPoint(player, p.hops[0].From) <= hopLandingTolerance
+}
+
+// advance drops the hop that was just cast
+func (p *teleportPlan) advance() {
+	if len(p.hops) > 0 {
+		p.hops = p.hops[1:]
+	}
+}
+
+// reset plans again on the next hop, the player may not be where the plan expects after a failed cast or a recovery
+func (p *teleportPlan) reset() {
+	p.planned = false
+	p.hops = nil
+}
@@ -0,0 +1,55 @@
+package action
+
+import (
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/koolo/internal/pather/teleport"
+)
+
+func TestTeleportPlanFollowsHops(t *testing.T) {
+	target := data.Position{X: 40, Y: 0}
+	p := &teleportPlan{
+		target:  target,
+		planned: true,
+		found:   true,
+		hops: []teleport.Hop{
+			{From: data.Position{X: 0, Y: 0}, To: data.Position{X: 20, Y: 0}},
+			{From: data.Position{X: 20, Y: 0}, To: target},
+		},
+	}
+
+	if !p.following(target) || p.following(data.Position{X: 1, Y: 1}) {
+		t.Fatal("plan should only be followed for its target")
+	}
+
+	p.advance()
+	if len(p.hops) != 1 || p.hops[0].To != target {
+		t.Errorf("hops after a cast = %v, want the last one", p.hops)
+	}
+	tests := []struct {
+		player data.Position
+		want   bool
+	}{
+		{player: data.Position{X: 20, Y: 0}, want: true},
+		// Landed a couple of tiles off
+		{player: data.Position{X: 21, Y: 2}, want: true},
+		{player: data.Position{X: 14, Y: 0}, want: false},
+	}
+	for _, tt := range tests {
+		if got := p.onPlan(tt.player); got != tt.want {
+			t.Errorf("onPlan(%v) = %v, want %v", tt.player, got, tt.want)
+		}
+	}
+
+	p.advance()
+	if p.following(target) || p.onPlan(target) {
+		t.Error("every hop was cast, plan should be done")
+	}
+
+	p.advance()
+	p.reset()
+	if p.planned || len(p.hops) != 0 {
+		t.Error("reset should plan again")
+	}
+}
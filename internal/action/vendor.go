@@ -8,8 +8,8 @@ import (
 	"github.com/hectorgimenez/d2go/pkg/data/npc"
 	"github.com/hectorgimenez/koolo/internal/action/step"
 	botCtx "github.com/hectorgimenez/koolo/internal/context"
+	"github.com/hectorgimenez/koolo/internal/game"
 	"github.com/hectorgimenez/koolo/internal/town"
-	"github.com/lxn/win"
 )
 
 func VendorRefill(forceRefill bool, sellJunk bool, tempLock ...[][]int) (err error) {
@@ -48,9 +48,9 @@ func VendorRefill(forceRefill bool, sellJunk bool, tempLock ...[][]int) (err err
 
 	// Jamella trade button is the first one
 	if vendorNPC == npc.Jamella {
-		ctx.HID.KeySequence(win.VK_HOME, win.VK_RETURN)
+		ctx.HID.KeySequence(game.VKHome, game.VKReturn)
 	} else {
-		ctx.HID.KeySequence(win.VK_HOME, win.VK_DOWN, win.VK_RETURN)
+		ctx.HID.KeySequence(game.VKHome, game.VKDown, game.VKReturn)
 	}
 
 	if sellJunk {
@@ -80,9 +80,9 @@ func BuyAtVendor(vendor npc.ID, items ...VendorItemRequest) error {
 
 	// Jamella trade button is the first one
 	if vendor == npc.Jamella {
-		ctx.HID.KeySequence(win.VK_HOME, win.VK_DOWN, win.VK_RETURN)
+		ctx.HID.KeySequence(game.VKHome, game.VKDown, game.VKReturn)
 	} else {
-		ctx.HID.KeySequence(win.VK_HOME, win.VK_DOWN, win.VK_RETURN)
+		ctx.HID.KeySequence(game.VKHome, game.VKDown, game.VKReturn)
 	}
 
 	for _, i := range items {
@@ -51,6 +51,8 @@ func WayPoint(dest area.ID) error {
 			utils.PingSleep(utils.Medium, 250) // Medium operation: Wait for waypoint tab to load
 			// Just to make sure no message like TZ change or public game spam prevent bot from clicking on waypoint
 			ClearMessages()
+			ctx.RefreshGameData()
+			rememberWaypoints(ctx, wpCoords.Tab)
 		}
 	}
 
@@ -0,0 +1,72 @@
+package action
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+	"github.com/hectorgimenez/d2go/pkg/data/difficulty"
+	"github.com/hectorgimenez/koolo/internal/context"
+)
+
+// Discovered waypoints of every character and difficulty, the game only reports the waypoints of the act tab selected
+// in the waypoint menu so every act is filled the first time its tab is read
+var discoveredWaypoints = &waypointCache{acts: make(map[waypointCacheKey]map[int][]area.ID)}
+
+type waypointCacheKey struct {
+	character  string
+	difficulty difficulty.Difficulty
+}
+
+type waypointCache struct {
+	mu   sync.Mutex
+	acts map[waypointCacheKey]map[int][]area.ID // Discovered waypoints by act tab
+}
+
+func waypointKey(ctx *context.Status) waypointCacheKey {
+	return waypointCacheKey{character: ctx.CharacterCfg.CharacterName, difficulty: ctx.CharacterCfg.Game.Difficulty}
+}
+
+// update saves the waypoints the menu shows for the act tab, available is ignored if the menu was not read yet
+func (c *waypointCache) update(key waypointCacheKey, tab int, available []area.ID) {
+	var discovered []area.ID
+	for _, id := range available {
+		if wp, found := area.WPAddresses[id]; found && wp.Tab == tab {
+			discovered = append(discovered, id)
+		}
+	}
+	if len(discovered) == 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.acts[key] == nil {
+		c.acts[key] = make(map[int][]area.ID)
+	}
+	c.acts[key][tab] = discovered
+}
+
+// available returns the discovered waypoints, every waypoint of the acts whose tab was never read is assumed to be
+// discovered. WayPoint walks from the previous discovered one when that's not the case.
+func (c *waypointCache) available(key waypointCacheKey) []area.ID {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	acts := c.acts[key]
+	var waypoints []area.ID
+	for id, wp := range area.WPAddresses {
+		if discovered, read := acts[wp.Tab]; !read || slices.Contains(discovered, id) {
+			waypoints = append(waypoints, id)
+		}
+	}
+	slices.Sort(waypoints)
+
+	return waypoints
+}
+
+// rememberWaypoints saves the waypoints shown by the open waypoint menu for the act tab
+func rememberWaypoints(ctx *context.Status, tab int) {
+	discoveredWaypoints.update(waypointKey(ctx), tab, ctx.Data.PlayerUnit.AvailableWaypoints)
+}
@@ -0,0 +1,55 @@
+package action
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+	"github.com/hectorgimenez/d2go/pkg/data/difficulty"
+)
+
+func TestWaypointCache(t *testing.T) {
+	c := &waypointCache{acts: make(map[waypointCacheKey]map[int][]area.ID)}
+	normal := waypointCacheKey{character: "sorc", difficulty: difficulty.Normal}
+	hell := waypointCacheKey{character: "sorc", difficulty: difficulty.Hell}
+
+	// Nothing read yet, every waypoint is assumed to be there
+	if got := c.available(normal); len(got) != len(area.WPAddresses) {
+		t.Fatalf("got %d waypoints before reading the menu, want all %d", len(got), len(area.WPAddresses))
+	}
+
+	// Act 2 tab read, Act 1 waypoints shown with it are ignored
+	c.update(normal, 2, []area.ID{area.LutGholein, area.DryHills, area.RogueEncampment})
+	got := c.available(normal)
+	for _, tt := range []struct {
+		area area.ID
+		want bool
+	}{
+		{area: area.LutGholein, want: true},
+		{area: area.DryHills, want: true},
+		{area: area.LostCity, want: false},
+		{area: area.RogueEncampment, want: true},
+		{area: area.BlackMarsh, want: true},
+		{area: area.KurastDocks, want: true},
+	} {
+		if slices.Contains(got, tt.area) != tt.want {
+			t.Errorf("%s available = %v, want %v", tt.area.Area().Name, !tt.want, tt.want)
+		}
+	}
+
+	// A new read replaces the act, difficulties are kept apart
+	c.update(normal, 2, []area.ID{area.LutGholein, area.DryHills, area.LostCity})
+	if !slices.Contains(c.available(normal), area.LostCity) {
+		t.Error("Lost City discovered after reading the tab again")
+	}
+	c.update(hell, 2, []area.ID{area.LutGholein})
+	if slices.Contains(c.available(hell), area.DryHills) || !slices.Contains(c.available(normal), area.DryHills) {
+		t.Error("Expected discovered waypoints by difficulty")
+	}
+
+	// Empty reads, the tab didn't load
+	c.update(hell, 2, nil)
+	if !slices.Contains(c.available(hell), area.LutGholein) {
+		t.Error("An empty read should keep the last one")
+	}
+}
@@ -3,6 +3,7 @@ package action
 import (
 	"log/slog"
 
+	"github.com/hectorgimenez/d2go/pkg/data/area"
 	"github.com/hectorgimenez/koolo/internal/action/step"
 	"github.com/hectorgimenez/koolo/internal/context"
 )
@@ -22,6 +23,11 @@ func DiscoverWaypoint() error {
 			}
 
 			ctx.Logger.Info("Waypoint discovered", slog.String("area", ctx.Data.PlayerUnit.Area.Area().Name))
+			// The menu opens in the tab of the current act
+			if wp, found := area.WPAddresses[ctx.Data.PlayerUnit.Area]; found {
+				ctx.RefreshGameData()
+				rememberWaypoints(ctx, wp.Tab)
+			}
 			step.CloseAllMenus()
 		}
 	}
@@ -71,7 +71,7 @@ func (b *Bot) Run(ctx context.Context, firstRun bool, runs []run.Run) error {
 	b.ctx.SwitchPriority(botCtx.PriorityNormal) // Restore priority to normal, in case it was stopped in previous game
 	b.ctx.CurrentGame = botCtx.NewGameHelper()  // Reset current game helper structure
 
-	err := b.ctx.GameReader.FetchMapData()
+	err := b.ctx.MemoryReader.FetchMapData()
 	if err != nil {
 		return err
 	}
@@ -86,6 +86,9 @@ func (b *Bot) Run(ctx context.Context, firstRun bool, runs []run.Run) error {
 	action.SwitchToLegacyMode()
 	b.ctx.RefreshGameData()
 
+	// Drop the previous game grids and start building the pathing hierarchy of the starting area
+	b.ctx.PathFinder.Prepare()
+
 	b.updateActivityAndPosition() // Initial update for activity and position
 
 	// This routine is in charge of refreshing the game data and handling cancellation, will work in parallel with any other execution
@@ -404,7 +407,7 @@ func (b *Bot) Run(ctx context.Context, firstRun bool, runs []run.Run) error {
 					runFinishReason = event.FinishedOK
 				}
 
-				event.Send(event.RunFinished(event.Text(b.ctx.Name, fmt.Sprintf("Finished run: %s", r.Name())), r.Name(), runFinishReason))
+				event.Send(event.RunFinished(event.Text(b.ctx.Name, fmt.Sprintf("Finished run: %s", r.Name())), r.Name(), runFinishReason, b.ctx.Data.PlayerUnit.Area))
 
 				if err != nil {
 					return err
@@ -17,6 +17,7 @@ import (
 	"github.com/hectorgimenez/koolo/internal/health"
 	"github.com/hectorgimenez/koolo/internal/mule"
 	"github.com/hectorgimenez/koolo/internal/pather"
+	"github.com/hectorgimenez/koolo/internal/remote/runlog"
 	"github.com/hectorgimenez/koolo/internal/utils"
 	"github.com/hectorgimenez/koolo/internal/utils/winproc"
 	"github.com/lxn/win"
@@ -27,15 +28,19 @@ type SupervisorManager struct {
 	supervisors    map[string]Supervisor
 	crashDetectors map[string]*game.CrashDetector
 	eventListener  *event.Listener
+	statsHandlers  map[string]func() // Unsubscribes the stats handler of each running supervisor
+	runStore       *runlog.Store     // Run history, read by the adaptive run rotation
 }
 
-func NewSupervisorManager(logger *slog.Logger, eventListener *event.Listener) *SupervisorManager {
+func NewSupervisorManager(logger *slog.Logger, eventListener *event.Listener, runStore *runlog.Store) *SupervisorManager {
 
 	return &SupervisorManager{
 		logger:         logger,
 		supervisors:    make(map[string]Supervisor),
 		crashDetectors: make(map[string]*game.CrashDetector),
 		eventListener:  eventListener,
+		statsHandlers:  make(map[string]func()),
+		runStore:       runStore,
 	}
 }
 
@@ -166,6 +171,12 @@ func (mng *SupervisorManager) Stop(supervisor string) {
 			delete(mng.crashDetectors, supervisor)
 		}
 
+		// Restarts register a new stats handler, drop the old one so they don't pile up on the listener
+		if unsubscribe, ok := mng.statsHandlers[supervisor]; ok {
+			unsubscribe()
+			delete(mng.statsHandlers, supervisor)
+		}
+
 		// The logic to start the next character has been removed from here.
 		// The restartFunc is now the single source of truth for this,
 		// preventing the mule from restarting itself.
@@ -258,6 +269,7 @@ func (mng *SupervisorManager) buildSupervisor(supervisorName string, logger *slo
 	ctx.Logger = logger
 	ctx.Manager = game.NewGameManager(gr, hidM, supervisorName)
 	ctx.GameReader = gr
+	ctx.MemoryReader = gr
 	ctx.MemoryInjector = gi
 	ctx.PathFinder = pf
 	ctx.BeltManager = bm
@@ -272,12 +284,17 @@ func (mng *SupervisorManager) buildSupervisor(supervisorName string, logger *slo
 	bot := NewBot(ctx.Context, muleManager)
 
 	statsHandler := NewStatsHandler(supervisorName, logger)
-	mng.eventListener.Register(statsHandler.Handle)
-	supervisor, err := NewSinglePlayerSupervisor(supervisorName, bot, statsHandler)
+	statsOpts := event.DefaultHandlerOptions
+	statsOpts.Name = "stats/" + supervisorName
+	statsOpts.Supervisor = supervisorName
+	unsubscribeStats := mng.eventListener.RegisterWithOptions(statsHandler.Handle, statsOpts)
+	supervisor, err := NewSinglePlayerSupervisor(supervisorName, bot, statsHandler, mng.runStore)
 
 	if err != nil {
+		unsubscribeStats()
 		return nil, nil, err
 	}
+	mng.statsHandlers[supervisorName] = unsubscribeStats
 
 	supervisor.GetContext().StopSupervisorFn = supervisor.Stop
 
@@ -306,6 +323,7 @@ func (mng *SupervisorManager) buildSupervisor(supervisorName string, logger *slo
 		}
 
 		mng.logger.Info("Restarting supervisor after crash", slog.String("supervisor", supervisorName))
+		event.Send(event.ClientCrashed(event.Text(supervisorName, "Game client crashed, restarting supervisor")))
 		mng.Stop(supervisorName)
 		time.Sleep(5 * time.Second) // Wait a bit before restarting
 
@@ -0,0 +1,112 @@
+package bot
+
+import (
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"time"
+
+	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/remote/runlog"
+	"github.com/hectorgimenez/koolo/internal/run/rotation"
+)
+
+// runRotation keeps the state of the run rotation of a supervisor across games
+type runRotation struct {
+	games    int
+	rnd      *rand.Rand
+	runStore *runlog.Store
+}
+
+func newRunRotation(runStore *runlog.Store) *runRotation {
+	return &runRotation{rnd: rand.New(rand.NewSource(time.Now().UnixNano())), runStore: runStore}
+}
+
+// next returns the runs of the next game following the rotation configured for the character
+func (rr *runRotation) next(supervisor string, cfg *config.CharacterCfg, runs []string, logger *slog.Logger) []string {
+	rr.games++
+
+	policy, err := rotationPolicy(cfg)
+	if err != nil {
+		logger.Warn("Invalid run rotation, using the run list as it is", slog.Any("error", err))
+		return runs
+	}
+
+	var failures map[string]rotation.FailureRate
+	if policy.Adaptive {
+		failures, err = runFailureRates(rr.runStore, supervisor, cfg.Game.Rotation.Adaptive.LookbackHours)
+		if err != nil {
+			logger.Warn("Error reading the run history, adaptive rotation disabled for this game", slog.Any("error", err))
+		}
+	}
+
+	return rotation.NewRotator(policy, rr.rnd).Order(runs, rotation.Game{Number: rr.games, Time: time.Now()}, failures)
+}
+
+func rotationPolicy(cfg *config.CharacterCfg) (rotation.Policy, error) {
+	rc := cfg.Game.Rotation
+	policy := rotation.Policy{
+		Strategy:       rc.Strategy,
+		Weights:        rc.Weights,
+		RunsPerGame:    rc.RunsPerGame,
+		EveryNthGame:   rc.EveryNthGame,
+		Adaptive:       rc.Adaptive.Enabled,
+		MaxFailureRate: rc.Adaptive.MaxFailureRate,
+		MinRuns:        rc.Adaptive.MinRuns,
+	}
+
+	switch rc.Strategy {
+	case "":
+		policy.Strategy = rotation.Static
+		if cfg.Game.RandomizeRuns {
+			policy.Strategy = rotation.Random
+		}
+	case rotation.Static, rotation.Random, rotation.Weighted, rotation.RoundRobin:
+	default:
+		return rotation.Policy{}, fmt.Errorf("unknown rotation strategy %q", rc.Strategy)
+	}
+
+	for _, tod := range rc.TimeOfDay {
+		from, err := time.Parse("15:04", tod.From)
+		if err != nil {
+			return rotation.Policy{}, fmt.Errorf("invalid time of day %q: %w", tod.From, err)
+		}
+		to, err := time.Parse("15:04", tod.To)
+		if err != nil {
+			return rotation.Policy{}, fmt.Errorf("invalid time of day %q: %w", tod.To, err)
+		}
+
+		runs := make([]string, len(tod.Runs))
+		for i, r := range tod.Runs {
+			runs[i] = string(r)
+		}
+		policy.TimeOfDay = append(policy.TimeOfDay, rotation.TimeOfDayRuns{From: clockOffset(from), To: clockOffset(to), Runs: runs})
+	}
+
+	return policy, nil
+}
+
+// runFailureRates reads the recent run history of the supervisor, deaths and errors count as failures
+func runFailureRates(runStore *runlog.Store, supervisor string, lookbackHours int) (map[string]rotation.FailureRate, error) {
+	if runStore == nil {
+		return nil, fmt.Errorf("run history not available")
+	}
+	if lookbackHours <= 0 {
+		lookbackHours = 24
+	}
+
+	runs, err := runStore.Runs(runlog.Query{
+		From:        time.Now().Add(-time.Duration(lookbackHours) * time.Hour),
+		Supervisors: []string{supervisor},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	failures := make(map[string]rotation.FailureRate)
+	for name, rate := range runlog.RatesByRun(runs) {
+		failures[name] = rotation.FailureRate{Runs: rate.Runs, Rate: rate.DeathRate + rate.ErrorRate}
+	}
+
+	return failures, nil
+}
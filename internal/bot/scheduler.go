@@ -1,23 +1,37 @@
 package bot
 
 import (
+	"fmt"
 	"log/slog"
+	"math/rand"
+	"sync"
 	"time"
 
 	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/schedule"
 )
 
 type Scheduler struct {
-	manager *SupervisorManager
-	logger  *slog.Logger
-	stop    chan struct{}
+	manager    *SupervisorManager
+	logger     *slog.Logger
+	stop       chan struct{}
+	rnd        *rand.Rand
+	evaluators map[string]*schedule.Evaluator
+	lastErrors map[string]string // Last invalid config error of every supervisor, so it's only logged once
+
+	mu       sync.Mutex
+	starting map[string]bool // Supervisors being started, the manager only knows them once the client is up
 }
 
 func NewScheduler(manager *SupervisorManager, logger *slog.Logger) *Scheduler {
 	return &Scheduler{
-		manager: manager,
-		logger:  logger,
-		stop:    make(chan struct{}),
+		manager:    manager,
+		logger:     logger,
+		stop:       make(chan struct{}),
+		rnd:        rand.New(rand.NewSource(time.Now().UnixNano())),
+		evaluators: make(map[string]*schedule.Evaluator),
+		lastErrors: make(map[string]string),
+		starting:   make(map[string]bool),
 	}
 }
 
@@ -29,7 +43,7 @@ func (s *Scheduler) Start() {
 	for {
 		select {
 		case <-ticker.C:
-			s.checkSchedules()
+			s.checkSchedules(time.Now())
 		case <-s.stop:
 			s.logger.Info("Scheduler stopped")
 			return
@@ -41,47 +55,95 @@ func (s *Scheduler) Stop() {
 	close(s.stop)
 }
 
-func (s *Scheduler) checkSchedules() {
-	now := time.Now()
-	currentDay := int(now.Weekday())
-
+func (s *Scheduler) checkSchedules(now time.Time) {
 	for supervisorName, cfg := range config.GetCharacters() {
 		if !cfg.Scheduler.Enabled {
+			delete(s.evaluators, supervisorName)
 			continue
 		}
 
-		for _, day := range cfg.Scheduler.Days {
-			if day.DayOfWeek != currentDay {
-				continue
+		policy, found, err := policyFromConfig(cfg.Scheduler)
+		if err != nil {
+			if s.lastErrors[supervisorName] != err.Error() {
+				s.logger.Error("Invalid scheduler configuration", "supervisor", supervisorName, "error", err)
+				s.lastErrors[supervisorName] = err.Error()
 			}
+			continue
+		}
+		delete(s.lastErrors, supervisorName)
+		// Nothing scheduled, leave the supervisor alone
+		if !found {
+			continue
+		}
 
-			actionTaken := false
-
-			// Check if any time range is active
-			for _, timeRange := range day.TimeRanges {
-				start := time.Date(now.Year(), now.Month(), now.Day(), timeRange.Start.Hour(), timeRange.Start.Minute(), 0, 0, now.Location())
-				end := time.Date(now.Year(), now.Month(), now.Day(), timeRange.End.Hour(), timeRange.End.Minute(), 0, 0, now.Location())
-
-				if now.After(start) && now.Before(end) && s.supervisorNotStarted(supervisorName) {
-					s.logger.Info("Starting supervisor based on schedule. Time range: "+start.Format("15:04")+" - "+end.Format("15:04"), "supervisor", supervisorName)
-					go s.startSupervisor(supervisorName)
-					actionTaken = true
-					break
-				} else if now.After(end) || now == end || now.Before(start) && !s.supervisorNotStarted(supervisorName) {
-					s.logger.Info("Stopping supervisor based on schedule. Time range: "+start.Format("15:04")+" - "+end.Format("15:04"), "supervisor", supervisorName)
-					s.stopSupervisor(supervisorName)
-					actionTaken = true
-					break
-				}
-			}
+		running := !s.supervisorNotStarted(supervisorName)
+		if s.isStarting(supervisorName) && !running {
+			continue
+		}
 
-			// To prevent unnecessary checks since it can be only one day.
-			// Also avoid starting/stoping a supervisor in the same minute ... this is rediculous
-			if actionTaken {
-				break
-			}
+		ev, found := s.evaluators[supervisorName]
+		if !found {
+			ev = schedule.NewEvaluator(policy, s.rnd)
+			s.evaluators[supervisorName] = ev
+		} else {
+			ev.SetPolicy(policy)
+		}
+
+		decision := ev.Decide(now, running, len(s.manager.GetSupervisorStats(supervisorName).Games))
+		switch decision.Action {
+		case schedule.Start:
+			s.logger.Info("Starting supervisor based on schedule: "+decision.Reason, "supervisor", supervisorName)
+			s.setStarting(supervisorName, true)
+			go s.startSupervisor(supervisorName)
+		case schedule.Stop:
+			s.logger.Info("Stopping supervisor based on schedule: "+decision.Reason, "supervisor", supervisorName)
+			s.stopSupervisor(supervisorName)
+		}
+	}
+}
+
+// policyFromConfig converts the scheduler config, found is false when there is nothing to schedule
+func policyFromConfig(cfg config.Scheduler) (policy schedule.Policy, found bool, err error) {
+	for _, day := range cfg.Days {
+		for _, tr := range day.TimeRanges {
+			policy.Windows = append(policy.Windows, schedule.WeeklyRange{
+				Weekday: time.Weekday(day.DayOfWeek),
+				Start:   clockOffset(tr.Start),
+				End:     clockOffset(tr.End),
+			})
+		}
+	}
+	for _, cw := range cfg.Cron {
+		c, err := schedule.ParseCron(cw.Expression)
+		if err != nil {
+			return schedule.Policy{}, false, err
+		}
+		if cw.DurationMinutes <= 0 {
+			return schedule.Policy{}, false, fmt.Errorf("cron window %q needs a duration", cw.Expression)
 		}
+		policy.Windows = append(policy.Windows, schedule.CronWindow{Cron: c, Duration: minutes(cw.DurationMinutes)})
 	}
+
+	policy.StartJitter = minutes(cfg.StartJitterMinutes)
+	policy.StopJitter = minutes(cfg.StopJitterMinutes)
+	policy.BreakAfter = minutes(cfg.BreakAfterMinutes)
+	policy.BreakDuration = minutes(cfg.BreakMinutes)
+	policy.MaxDaily = minutes(cfg.MaxDailyMinutes)
+	policy.MaxWeekly = minutes(cfg.MaxWeeklyMinutes)
+	policy.RestAfterGames = cfg.RestAfterGames
+	policy.RestDuration = minutes(cfg.RestMinutes)
+
+	found = len(policy.Windows) > 0 || policy.BreakAfter > 0 || policy.MaxDaily > 0 || policy.MaxWeekly > 0 || policy.RestAfterGames > 0
+
+	return policy, found, nil
+}
+
+func clockOffset(t time.Time) time.Duration {
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+}
+
+func minutes(m int) time.Duration {
+	return time.Duration(m) * time.Minute
 }
 
 func (s *Scheduler) supervisorNotStarted(name string) bool {
@@ -89,7 +151,24 @@ func (s *Scheduler) supervisorNotStarted(name string) bool {
 	return stats.SupervisorStatus == NotStarted || stats.SupervisorStatus == Crashed || stats.SupervisorStatus == ""
 }
 
+func (s *Scheduler) isStarting(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.starting[name]
+}
+
+func (s *Scheduler) setStarting(name string, starting bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.starting[name] = starting
+}
+
 func (s *Scheduler) startSupervisor(name string) {
+	// Start blocks until the supervisor is stopped
+	defer s.setStarting(name, false)
+
 	if s.supervisorNotStarted(name) {
 		err := s.manager.Start(name, false)
 		if err != nil {
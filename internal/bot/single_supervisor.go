@@ -5,7 +5,6 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
-	"math/rand"
 	"strings"
 	"time"
 
@@ -18,6 +17,7 @@ import (
 	"github.com/hectorgimenez/koolo/internal/event"
 	"github.com/hectorgimenez/koolo/internal/game"
 	"github.com/hectorgimenez/koolo/internal/health"
+	"github.com/hectorgimenez/koolo/internal/remote/runlog"
 	"github.com/hectorgimenez/koolo/internal/run"
 	"github.com/hectorgimenez/koolo/internal/utils"
 )
@@ -43,8 +43,8 @@ func (s *SinglePlayerSupervisor) GetContext() *ct.Context {
 	return s.bot.ctx
 }
 
-func NewSinglePlayerSupervisor(name string, bot *Bot, statsHandler *StatsHandler) (*SinglePlayerSupervisor, error) {
-	bs, err := newBaseSupervisor(bot, name, statsHandler)
+func NewSinglePlayerSupervisor(name string, bot *Bot, statsHandler *StatsHandler, runStore *runlog.Store) (*SinglePlayerSupervisor, error) {
+	bs, err := newBaseSupervisor(bot, name, statsHandler, runStore)
 	if err != nil {
 		return nil, err
 	}
@@ -92,7 +92,7 @@ func (s *SinglePlayerSupervisor) orderRuns(runs []string) []string {
 
 func (s *SinglePlayerSupervisor) changeDifficulty(d difficulty.Difficulty) {
 
-	s.bot.ctx.GameReader.GetSelectedCharacterName()
+	s.bot.ctx.MemoryReader.GetSelectedCharacterName()
 
 	s.bot.ctx.HID.Click(game.LeftButton, 6, 6)
 
@@ -207,15 +207,12 @@ func (s *SinglePlayerSupervisor) Start() error {
 			return nil
 		}
 
+		cfg, _ := config.GetCharacter(s.name)
+		orderedRuns = s.rotation.next(s.name, cfg, orderedRuns, s.bot.ctx.Logger)
 		runs := run.BuildRuns(s.bot.ctx.CharacterCfg, orderedRuns)
 		gameStart := time.Now()
-		cfg, _ := config.GetCharacter(s.name)
-
-		if cfg.Game.RandomizeRuns {
-			rand.Shuffle(len(runs), func(i, j int) { runs[i], runs[j] = runs[j], runs[i] })
-		}
 
-		event.Send(event.GameCreated(event.Text(s.name, "New game created"), s.bot.ctx.GameReader.LastGameName(), s.bot.ctx.GameReader.LastGamePass()))
+		event.Send(event.GameCreated(event.Text(s.name, "New game created"), s.bot.ctx.MemoryReader.LastGameName(), s.bot.ctx.MemoryReader.LastGamePass()))
 		s.bot.ctx.CurrentGame.FailedToCreateGameAttempts = 0
 		s.bot.ctx.LastBuffAt = time.Time{}
 		s.logGameStart(runs)
@@ -271,6 +268,7 @@ func (s *SinglePlayerSupervisor) Start() error {
 			sustainedDuration,
 		)
 		pingMonitor.Enabled = pingEnabled
+		s.bot.ctx.SetPingMonitor(pingMonitor)
 		pingMonitor.SetCallback(func() {
 			s.bot.ctx.Logger.Error("Sustained high ping detected. Forcing game exit.",
 				slog.Int("threshold", pingThreshold),
@@ -287,7 +285,7 @@ func (s *SinglePlayerSupervisor) Start() error {
 			var droppedMouseItem bool // Track if we've already tried dropping mouse item
 
 			// Initial position check
-			if s.bot.ctx.GameReader.InGame() && s.bot.ctx.Data.PlayerUnit.ID > 0 {
+			if s.bot.ctx.MemoryReader.InGame() && s.bot.ctx.Data.PlayerUnit.ID > 0 {
 				lastPosition = s.bot.ctx.Data.PlayerUnit.Position
 			}
 
@@ -300,7 +298,7 @@ func (s *SinglePlayerSupervisor) Start() error {
 						continue
 					}
 
-					if !s.bot.ctx.GameReader.InGame() || s.bot.ctx.Data.PlayerUnit.ID == 0 {
+					if !s.bot.ctx.MemoryReader.InGame() || s.bot.ctx.Data.PlayerUnit.ID == 0 {
 						continue
 					}
 
@@ -395,29 +393,29 @@ func (s *SinglePlayerSupervisor) Start() error {
 			default:
 				gameFinishReason = event.FinishedError
 			}
-			event.Send(event.GameFinished(event.WithScreenshot(s.name, err.Error(), s.bot.ctx.GameReader.Screenshot()), gameFinishReason))
+			event.Send(event.GameFinished(event.WithScreenshot(s.name, err.Error(), s.bot.ctx.MemoryReader.Screenshot()), gameFinishReason, s.bot.ctx.Data.PlayerUnit.Area))
 
 			s.bot.ctx.Logger.Warn(
 				fmt.Sprintf("Game finished with errors, reason: %s. Game total time: %0.2fs", err.Error(), time.Since(gameStart).Seconds()),
 				slog.String("supervisor", s.name),
-				slog.Uint64("mapSeed", uint64(s.bot.ctx.GameReader.MapSeed())),
+				slog.Uint64("mapSeed", uint64(s.bot.ctx.MemoryReader.MapSeed())),
 			)
 			continue
 		}
 
 		gameFinishReason := event.FinishedOK
-		event.Send(event.GameFinished(event.Text(s.name, "Game finished successfully"), gameFinishReason))
+		event.Send(event.GameFinished(event.Text(s.name, "Game finished successfully"), gameFinishReason, s.bot.ctx.Data.PlayerUnit.Area))
 		s.bot.ctx.Logger.Info(
 			fmt.Sprintf("Game finished successfully. Game total time: %0.2fs", time.Since(gameStart).Seconds()),
 			slog.String("supervisor", s.name),
-			slog.Uint64("mapSeed", uint64(s.bot.ctx.GameReader.MapSeed())),
+			slog.Uint64("mapSeed", uint64(s.bot.ctx.MemoryReader.MapSeed())),
 		)
 		if s.bot.ctx.CharacterCfg.Companion.Enabled && s.bot.ctx.CharacterCfg.Companion.Leader {
 			event.Send(event.ResetCompanionGameInfo(event.Text(s.name, "Game "+s.bot.ctx.Data.Game.LastGameName+" finished"), s.bot.ctx.CharacterCfg.CharacterName))
 		}
 		if exitErr := s.bot.ctx.Manager.ExitGame(); exitErr != nil {
 			errMsg := fmt.Sprintf("Error exiting game %s", exitErr.Error())
-			event.Send(event.GameFinished(event.WithScreenshot(s.name, errMsg, s.bot.ctx.GameReader.Screenshot()), event.FinishedError))
+			event.Send(event.GameFinished(event.WithScreenshot(s.name, errMsg, s.bot.ctx.MemoryReader.Screenshot()), event.FinishedError, s.bot.ctx.Data.PlayerUnit.Area))
 			return errors.New(errMsg)
 		}
 		s.bot.ctx.Logger.Info("Game finished successfully. Waiting 3 seconds for client to close.")
@@ -451,11 +449,11 @@ func (s *SinglePlayerSupervisor) HandleMenuFlow() error {
 
 	s.bot.ctx.Logger.Debug("[Menu Flow]: Starting menu flow ...")
 
-	if s.bot.ctx.GameReader.IsInCharacterCreationScreen() {
+	if s.bot.ctx.MemoryReader.IsInCharacterCreationScreen() {
 		s.bot.ctx.Logger.Debug("[Menu Flow]: We're in character creation screen, exiting ...")
 		s.bot.ctx.HID.PressKey(0x1B)
 		time.Sleep(2000)
-		if s.bot.ctx.GameReader.IsInCharacterCreationScreen() {
+		if s.bot.ctx.MemoryReader.IsInCharacterCreationScreen() {
 			return errors.New("[Menu Flow]: Failed to exit character creation screen")
 		}
 	}
@@ -465,13 +463,13 @@ func (s *SinglePlayerSupervisor) HandleMenuFlow() error {
 		return s.bot.ctx.Manager.ExitGame()
 	}
 
-	isDismissableModalPresent, text := s.bot.ctx.GameReader.IsDismissableModalPresent()
+	isDismissableModalPresent, text := s.bot.ctx.MemoryReader.IsDismissableModalPresent()
 	if isDismissableModalPresent {
 		s.bot.ctx.Logger.Debug("[Menu Flow]: Detected dismissable modal with text: " + text)
 		s.bot.ctx.HID.PressKey(0x1B)
 		time.Sleep(1000)
 
-		isDismissableModalStillPresent, _ := s.bot.ctx.GameReader.IsDismissableModalPresent()
+		isDismissableModalStillPresent, _ := s.bot.ctx.MemoryReader.IsDismissableModalPresent()
 		if isDismissableModalStillPresent {
 			s.bot.ctx.Logger.Warn(fmt.Sprintf("[Menu Flow]: Dismissable modal still present after attempt to dismiss: %s", text))
 			s.bot.ctx.CurrentGame.FailedToCreateGameAttempts++
@@ -496,7 +494,7 @@ func (s *SinglePlayerSupervisor) HandleMenuFlow() error {
 }
 
 func (s *SinglePlayerSupervisor) HandleStandardMenuFlow() error {
-	atCharacterSelectionScreen := s.bot.ctx.GameReader.IsInCharacterSelectionScreen()
+	atCharacterSelectionScreen := s.bot.ctx.MemoryReader.IsInCharacterSelectionScreen()
 
 	if atCharacterSelectionScreen && s.bot.ctx.CharacterCfg.AuthMethod != "None" && !s.bot.ctx.CharacterCfg.Game.CreateLobbyGames {
 		s.bot.ctx.Logger.Debug("[Menu Flow]: We're at the character selection screen, ensuring we're online ...")
@@ -517,7 +515,7 @@ func (s *SinglePlayerSupervisor) HandleStandardMenuFlow() error {
 		return s.callManagerWithTimeout(s.bot.ctx.Manager.NewGame)
 	}
 
-	atLobbyScreen := s.bot.ctx.GameReader.IsInLobby()
+	atLobbyScreen := s.bot.ctx.MemoryReader.IsInLobby()
 
 	if atLobbyScreen && s.bot.ctx.CharacterCfg.Game.CreateLobbyGames {
 		s.bot.ctx.Logger.Debug("[Menu Flow]: We're at the lobby screen and we should create a lobby game ...")
@@ -541,11 +539,11 @@ func (s *SinglePlayerSupervisor) HandleStandardMenuFlow() error {
 		s.bot.ctx.HID.PressKey(0x1B)
 		time.Sleep(2000)
 
-		if s.bot.ctx.GameReader.IsInLobby() {
+		if s.bot.ctx.MemoryReader.IsInLobby() {
 			return fmt.Errorf("[Menu Flow]: Failed to exit lobby")
 		}
 
-		if s.bot.ctx.GameReader.IsInCharacterSelectionScreen() {
+		if s.bot.ctx.MemoryReader.IsInCharacterSelectionScreen() {
 			return s.callManagerWithTimeout(s.bot.ctx.Manager.NewGame)
 		}
 	}
@@ -564,7 +562,7 @@ func (s *SinglePlayerSupervisor) HandleCompanionMenuFlow() error {
 		return fmt.Errorf("idle")
 	}
 
-	if s.bot.ctx.GameReader.IsInCharacterSelectionScreen() {
+	if s.bot.ctx.MemoryReader.IsInCharacterSelectionScreen() {
 		err := s.ensureOnline()
 		if err != nil {
 			return err
@@ -581,7 +579,7 @@ func (s *SinglePlayerSupervisor) HandleCompanionMenuFlow() error {
 		return s.callManagerWithTimeout(joinGameFunc)
 	}
 
-	if s.bot.ctx.GameReader.IsInLobby() {
+	if s.bot.ctx.MemoryReader.IsInLobby() {
 		s.bot.ctx.Logger.Debug("[Menu Flow]: We're in lobby, joining game ...")
 		joinGameFunc := func() error {
 			return s.bot.ctx.Manager.JoinOnlineGame(gameName, gamePassword)
@@ -593,13 +591,13 @@ func (s *SinglePlayerSupervisor) HandleCompanionMenuFlow() error {
 }
 
 func (s *SinglePlayerSupervisor) tryEnterLobby() error {
-	if s.bot.ctx.GameReader.IsInLobby() {
+	if s.bot.ctx.MemoryReader.IsInLobby() {
 		s.bot.ctx.Logger.Debug("[Menu Flow]: We're already in lobby, exiting ...")
 		return nil
 	}
 
 	retryCount := 0
-	for !s.bot.ctx.GameReader.IsInLobby() {
+	for !s.bot.ctx.MemoryReader.IsInLobby() {
 		s.bot.ctx.Logger.Info("Entering lobby", slog.String("supervisor", s.name))
 		if retryCount >= 5 {
 			return fmt.Errorf("[Menu Flow]: Failed to enter bnet lobby after 5 retries")
@@ -635,7 +633,7 @@ func (s *SinglePlayerSupervisor) createLobbyGame() error {
 		return fmt.Errorf("[Menu Flow]: Failed to create lobby game: %w", err)
 	}
 
-	isDismissableModalPresent, text := s.bot.ctx.GameReader.IsDismissableModalPresent()
+	isDismissableModalPresent, text := s.bot.ctx.MemoryReader.IsDismissableModalPresent()
 	if isDismissableModalPresent {
 		s.bot.ctx.CharacterCfg.Game.PublicGameCounter++
 		s.bot.ctx.Logger.Warn(fmt.Sprintf("[Menu Flow]: Dismissable modal present after game creation attempt: %s", text))
@@ -12,6 +12,7 @@ import (
 	ct "github.com/hectorgimenez/koolo/internal/context"
 	"github.com/hectorgimenez/koolo/internal/event"
 	"github.com/hectorgimenez/koolo/internal/game"
+	"github.com/hectorgimenez/koolo/internal/remote/runlog"
 	"github.com/hectorgimenez/koolo/internal/run"
 	"github.com/hectorgimenez/koolo/internal/utils/winproc"
 	"github.com/lxn/win"
@@ -33,17 +34,20 @@ type baseSupervisor struct {
 	name         string
 	statsHandler *StatsHandler
 	cancelFn     context.CancelFunc
+	rotation     *runRotation
 }
 
 func newBaseSupervisor(
 	bot *Bot,
 	name string,
 	statsHandler *StatsHandler,
+	runStore *runlog.Store,
 ) (*baseSupervisor, error) {
 	return &baseSupervisor{
 		bot:          bot,
 		name:         name,
 		statsHandler: statsHandler,
+		rotation:     newRunRotation(runStore),
 	}, nil
 }
 
@@ -78,7 +82,7 @@ func (s *baseSupervisor) Stop() {
 	s.bot.ctx.SwitchPriority(ct.PriorityStop)
 
 	s.bot.ctx.MemoryInjector.Unload()
-	s.bot.ctx.GameReader.Close()
+	s.bot.ctx.MemoryReader.Close()
 
 	if s.bot.ctx.CharacterCfg.KillD2OnStop || s.bot.ctx.CharacterCfg.Scheduler.Enabled {
 		s.KillClient()
@@ -89,7 +93,7 @@ func (s *baseSupervisor) Stop() {
 
 func (s *baseSupervisor) KillClient() error {
 
-	process, err := os.FindProcess(int(s.bot.ctx.GameReader.Process.GetPID()))
+	process, err := os.FindProcess(int(s.bot.ctx.MemoryReader.Process.GetPID()))
 	if err != nil {
 		s.bot.ctx.Logger.Info("Failed to find process", slog.String("configuration", s.name))
 		return err
@@ -120,7 +124,7 @@ func (s *baseSupervisor) logGameStart(runs []run.Run) {
 func (s *baseSupervisor) waitUntilCharacterSelectionScreen() error {
 	s.bot.ctx.Logger.Info("Waiting for character selection screen...")
 
-	for !s.bot.ctx.GameReader.IsInCharacterSelectionScreen() {
+	for !s.bot.ctx.MemoryReader.IsInCharacterSelectionScreen() {
 		// Spam left click to skip to the char select screen
 		s.bot.ctx.HID.Click(game.LeftButton, 100, 100)
 		time.Sleep(250 * time.Millisecond)
@@ -151,7 +155,7 @@ func (s *baseSupervisor) waitUntilCharacterSelectionScreen() error {
 
 		// Try to select a character up to 25 times then give up and kill the client
 		for i := 0; i < 25; i++ {
-			characterName := s.bot.ctx.GameReader.GameReader.GetSelectedCharacterName()
+			characterName := s.bot.ctx.MemoryReader.GameReader.GetSelectedCharacterName()
 
 			s.bot.ctx.Logger.Debug(fmt.Sprintf("Checking character: %s", characterName))
 
@@ -176,15 +180,15 @@ func (s *baseSupervisor) waitUntilCharacterSelectionScreen() error {
 
 func (s *baseSupervisor) SetWindowPosition(x, y int) {
 	uFlags := win.SWP_NOZORDER | win.SWP_NOSIZE | win.SWP_NOACTIVATE
-	win.SetWindowPos(s.bot.ctx.GameReader.HWND, 0, int32(x), int32(y), 0, 0, uint32(uFlags))
+	win.SetWindowPos(s.bot.ctx.MemoryReader.HWND, 0, int32(x), int32(y), 0, 0, uint32(uFlags))
 }
 
 func (s *baseSupervisor) ensureOnline() error {
-	if !s.bot.ctx.GameReader.IsInCharacterSelectionScreen() {
+	if !s.bot.ctx.MemoryReader.IsInCharacterSelectionScreen() {
 		return fmt.Errorf("[Ensure Online]: We're not in the character selection screen")
 	}
 
-	if !s.bot.ctx.GameReader.IsOnline() && s.bot.ctx.CharacterCfg.AuthMethod != "None" {
+	if !s.bot.ctx.MemoryReader.IsOnline() && s.bot.ctx.CharacterCfg.AuthMethod != "None" {
 		s.bot.ctx.HID.Click(game.LeftButton, 1090, 32)
 		s.bot.ctx.Logger.Debug("[Ensure Online]: We're at the character selection screen but not online")
 
@@ -197,8 +201,8 @@ func (s *baseSupervisor) ensureOnline() error {
 			time.Sleep(2000)
 
 			for {
-				blockingPanel := s.bot.ctx.GameReader.GetPanel("BlockingPanel")
-				popuPanel := s.bot.ctx.GameReader.GetPanel("DismissableModal")
+				blockingPanel := s.bot.ctx.MemoryReader.GetPanel("BlockingPanel")
+				popuPanel := s.bot.ctx.MemoryReader.GetPanel("DismissableModal")
 
 				if blockingPanel.PanelName != "" && blockingPanel.PanelEnabled && blockingPanel.PanelVisible {
 					s.bot.ctx.Logger.Debug("[Ensure Online]: Loading panel detected, waiting for it to disappear")
@@ -217,7 +221,7 @@ func (s *baseSupervisor) ensureOnline() error {
 				break
 			}
 
-			if s.bot.ctx.GameReader.IsOnline() {
+			if s.bot.ctx.MemoryReader.IsOnline() {
 				s.bot.ctx.Logger.Debug("[Ensure Online]: We're online!")
 				return nil
 			}
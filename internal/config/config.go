@@ -33,9 +33,10 @@ var (
 
 type KooloCfg struct {
 	Debug struct {
-		Log         bool `yaml:"log"`
-		Screenshots bool `yaml:"screenshots"`
-		RenderMap   bool `yaml:"renderMap"`
+		Log          bool `yaml:"log"`
+		Screenshots  bool `yaml:"screenshots"`
+		RenderMap    bool `yaml:"renderMap"`
+		RecordEvents bool `yaml:"recordEvents"`
 	} `yaml:"debug"`
 	FirstRun              bool   `yaml:"firstRun"`
 	UseCustomSettings     bool   `yaml:"useCustomSettings"`
@@ -65,6 +66,32 @@ type KooloCfg struct {
 		HighPingThreshold int  `yaml:"highPingThreshold"` // Ping threshold in ms (default 500-1000)
 		SustainedDuration int  `yaml:"sustainedDuration"` // Seconds high ping must persist (default 10-30)
 	} `yaml:"pingMonitor"`
+	API struct {
+		Tokens []APIToken `yaml:"tokens"` // The /api/v1 endpoints are disabled while there are no tokens
+	} `yaml:"api"`
+	Webhooks []WebhookCfg `yaml:"webhooks"`
+	MapCache struct {
+		Disabled   bool `yaml:"disabled"`   // The cache is on unless disabled, older koolo.yaml files don't have this section
+		MaxEntries int  `yaml:"maxEntries"` // Games kept on disk, 0 uses the default
+	} `yaml:"mapCache"`
+}
+
+type APIToken struct {
+	Name     string `yaml:"name"`
+	Token    string `yaml:"token"`
+	ReadOnly bool   `yaml:"readOnly"` // Read only tokens can't start/stop supervisors or edit config
+}
+
+type WebhookCfg struct {
+	Name       string            `yaml:"name"`
+	Enabled    bool              `yaml:"enabled"`
+	URL        string            `yaml:"url"`
+	Events     []string          `yaml:"events"`     // Event type tags to send (run_finished, item_stashed...), empty sends all of them
+	Template   string            `yaml:"template"`   // text/template rendering the JSON body, empty sends the raw event
+	Headers    map[string]string `yaml:"headers"`    // Extra request headers, e.g. Authorization
+	Secret     string            `yaml:"secret"`     // Signs the body with HMAC-SHA256 in the X-Koolo-Signature header
+	Screenshot bool              `yaml:"screenshot"` // Send the event screenshot as multipart/form-data when there is one
+	MaxRetries int               `yaml:"maxRetries"`
 }
 
 type Day struct {
@@ -72,9 +99,49 @@ type Day struct {
 	TimeRanges []TimeRange `yaml:"timeRange"`
 }
 
+// RunRotation picks the runs of every game and their order, RandomizeRuns is used when Strategy is empty
+type RunRotation struct {
+	Strategy     string           `yaml:"strategy"`     // static, random, weighted or roundRobin
+	Weights      map[string]int   `yaml:"weights"`      // Weighted strategy, runs not listed get 1
+	RunsPerGame  int              `yaml:"runsPerGame"`  // Round robin strategy, 0 does all the runs every game
+	EveryNthGame map[string]int   `yaml:"everyNthGame"` // Runs only done every N games, e.g. cows: 5
+	TimeOfDay    []TimeOfDayRuns  `yaml:"timeOfDay"`
+	Adaptive     AdaptiveRotation `yaml:"adaptive"`
+}
+
+// TimeOfDayRuns replaces the run list between From and To (HH:MM), ranges can cross midnight
+type TimeOfDayRuns struct {
+	From string `yaml:"from"`
+	To   string `yaml:"to"`
+	Runs []Run  `yaml:"runs"`
+}
+
+// AdaptiveRotation moves the runs dying or failing too often to the end of the game
+type AdaptiveRotation struct {
+	Enabled        bool    `yaml:"enabled"`
+	LookbackHours  int     `yaml:"lookbackHours"`
+	MinRuns        int     `yaml:"minRuns"`        // Runs needed in the lookback before a run is judged
+	MaxFailureRate float64 `yaml:"maxFailureRate"` // Deaths and errors over runs, e.g. 0.2
+}
+
 type Scheduler struct {
-	Enabled bool  `yaml:"enabled"`
-	Days    []Day `yaml:"days"`
+	Enabled bool         `yaml:"enabled"`
+	Days    []Day        `yaml:"days"`
+	Cron    []CronWindow `yaml:"cron"` // Play windows opened by cron expressions, used along with Days
+
+	StartJitterMinutes int `yaml:"startJitterMinutes"` // Starts are delayed by a random amount of minutes up to this
+	StopJitterMinutes  int `yaml:"stopJitterMinutes"`  // Stops at the end of a window happen up to this many minutes earlier
+	BreakAfterMinutes  int `yaml:"breakAfterMinutes"`  // Continuous play time before a mandatory break, 0 disables breaks
+	BreakMinutes       int `yaml:"breakMinutes"`
+	MaxDailyMinutes    int `yaml:"maxDailyMinutes"`  // 0 means unlimited
+	MaxWeeklyMinutes   int `yaml:"maxWeeklyMinutes"` // 0 means unlimited, weeks start on monday
+	RestAfterGames     int `yaml:"restAfterGames"`   // Games played before resting, 0 disables it
+	RestMinutes        int `yaml:"restMinutes"`
+}
+
+type CronWindow struct {
+	Expression      string `yaml:"expression"` // Standard 5 field cron expression, e.g. "0 18 * * mon-fri"
+	DurationMinutes int    `yaml:"durationMinutes"`
 }
 
 type TimeRange struct {
@@ -130,6 +197,7 @@ type CharacterCfg struct {
 		UseMerc                      bool   `yaml:"useMerc"`
 		StashToShared                bool   `yaml:"stashToShared"`
 		UseTeleport                  bool   `yaml:"useTeleport"`
+		TeleportRadius               int    `yaml:"teleportRadius"`
 		ClearPathDist                int    `yaml:"clearPathDist"`
 		ShouldHireAct2MercFrozenAura bool   `yaml:"shouldHireAct2MercFrozenAura"`
 		UseExtraBuffs                bool   `yaml:"useExtraBuffs"`
@@ -171,6 +239,7 @@ type CharacterCfg struct {
 		Difficulty             difficulty.Difficulty `yaml:"difficulty"`
 		RandomizeRuns          bool                  `yaml:"randomizeRuns"`
 		Runs                   []Run                 `yaml:"runs"`
+		Rotation               RunRotation           `yaml:"rotation"`
 		CreateLobbyGames       bool                  `yaml:"createLobbyGames"`
 		PublicGameCounter      int                   `yaml:"-"`
 		MaxFailedMenuAttempts  int                   `yaml:"maxFailedMenuAttempts"`
@@ -430,17 +499,16 @@ func Load() error {
 			charCfg.Game.MaxFailedMenuAttempts = 10
 		}
 
-		var pickitPath string
 		if Koolo.CentralizedPickitPath != "" && charCfg.UseCentralizedPickit {
 			if _, err := os.Stat(Koolo.CentralizedPickitPath); os.IsNotExist(err) {
 				utils.ShowDialog("Error loading pickit rules for "+entry.Name(), "The centralized pickit path does not exist: "+Koolo.CentralizedPickitPath+"\nPlease check your Koolo settings.\nFalling back to local pickit.")
-				pickitPath = getAbsPath(filepath.Join("config", entry.Name(), "pickit")) + "\\"
-			} else {
-				pickitPath = Koolo.CentralizedPickitPath + "\\"
 			}
-		} else {
-			pickitPath = getAbsPath(filepath.Join("config", entry.Name(), "pickit")) + "\\"
 		}
+		pickitPath := PickitPath(&charCfg)
+		if !filepath.IsAbs(pickitPath) {
+			pickitPath = getAbsPath(pickitPath)
+		}
+		pickitPath += "\\"
 
 		rules, err := nip.ReadDir(pickitPath)
 		if err != nil {
@@ -513,6 +581,18 @@ func Load() error {
 	return nil
 }
 
+// PickitPath returns the directory the pickit rules of the character are read from, the centralized one when the
+// character uses it and it exists
+func PickitPath(charCfg *CharacterCfg) string {
+	if Koolo.CentralizedPickitPath != "" && charCfg.UseCentralizedPickit {
+		if _, err := os.Stat(Koolo.CentralizedPickitPath); !os.IsNotExist(err) {
+			return Koolo.CentralizedPickitPath
+		}
+	}
+
+	return filepath.Join("config", charCfg.ConfigFolderName, "pickit")
+}
+
 // Helper function to read a single NIP file using the temp directory workaround
 func readSinglePickitFile(filePath string) (nip.Rules, error) {
 	tempDir := filepath.Join(filepath.Dir(filePath), "temp_single_read")
@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/hectorgimenez/d2go/pkg/data"
@@ -44,10 +45,11 @@ type Context struct {
 	CharacterCfg         *config.CharacterCfg
 	Data                 *game.Data
 	EventListener        *event.Listener
-	HID                  *game.HID
+	HID                  game.Input
 	Logger               *slog.Logger
 	Manager              *game.Manager
-	GameReader           *game.MemoryReader
+	GameReader           game.Reader
+	MemoryReader         *game.MemoryReader // Live client backing GameReader, nil when running against a simulated backend
 	MemoryInjector       *game.MemoryInjector
 	PathFinder           *pather.PathFinder
 	BeltManager          *health.BeltManager
@@ -61,9 +63,10 @@ type Context struct {
 	StopSupervisorFn     StopFunc
 	CleanStopRequested   bool
 	RestartWithCharacter string
-	PacketSender         *game.PacketSender
+	PacketSender         game.PacketInteractor
 	IsLevelingCharacter  *bool
 	LastPortalTick       time.Time // NEW FIELD: Tracks last portal creation for spam prevention
+	pingMonitor          atomic.Pointer[health.PingMonitor]
 }
 
 type Debug struct {
@@ -90,6 +93,16 @@ type CurrentGameHelper struct {
 	StashFull         bool
 }
 
+// PingMonitor returns the monitor of the current game, nil until the first game starts. It's safe to call from
+// outside the bot goroutine.
+func (ctx *Context) PingMonitor() *health.PingMonitor {
+	return ctx.pingMonitor.Load()
+}
+
+func (ctx *Context) SetPingMonitor(pm *health.PingMonitor) {
+	ctx.pingMonitor.Store(pm)
+}
+
 func (ctx *Context) StopSupervisor() {
 	if ctx.StopSupervisorFn != nil {
 		ctx.Logger.Info("Game logic requested supervisor stop.", "source", "context")
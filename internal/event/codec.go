@@ -0,0 +1,132 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// eventTypes maps the type tag stored in recordings to the event type, tags are persisted so they must never change
+var eventTypes = map[string]reflect.Type{
+	"text":                        reflect.TypeOf(BaseEvent{}),
+	"used_potion":                 reflect.TypeOf(UsedPotionEvent{}),
+	"game_created":                reflect.TypeOf(GameCreatedEvent{}),
+	"game_finished":               reflect.TypeOf(GameFinishedEvent{}),
+	"run_started":                 reflect.TypeOf(RunStartedEvent{}),
+	"run_finished":                reflect.TypeOf(RunFinishedEvent{}),
+	"item_stashed":                reflect.TypeOf(ItemStashedEvent{}),
+	"item_blacklisted":            reflect.TypeOf(ItemBlackListedEvent{}),
+	"companion_leader_attack":     reflect.TypeOf(CompanionLeaderAttackEvent{}),
+	"companion_requested_tp":      reflect.TypeOf(CompanionRequestedTPEvent{}),
+	"interacted_to":               reflect.TypeOf(InteractedToEvent{}),
+	"game_paused":                 reflect.TypeOf(GamePausedEvent{}),
+	"request_companion_join_game": reflect.TypeOf(RequestCompanionJoinGameEvent{}),
+	"reset_companion_game_info":   reflect.TypeOf(ResetCompanionGameInfoEvent{}),
+	"client_crashed":              reflect.TypeOf(ClientCrashedEvent{}),
+	"character_switch":            reflect.TypeOf(CharacterSwitchEvent{}),
+	"movement_recovery":           reflect.TypeOf(MovementRecoveryEvent{}),
+}
+
+var eventTags = func() map[reflect.Type]string {
+	tags := make(map[reflect.Type]string, len(eventTypes))
+	for tag, t := range eventTypes {
+		tags[t] = tag
+	}
+	return tags
+}()
+
+var baseEventType = reflect.TypeOf(BaseEvent{})
+
+// Envelope is the serialized form of an event. The fields of BaseEvent are stored next to the type tag, the event
+// specific fields go to Payload. Screenshots are not stored, HasImage only tells there was one, and game passwords
+// are left empty.
+type Envelope struct {
+	Type       string          `json:"type"`
+	Supervisor string          `json:"supervisor"`
+	OccurredAt time.Time       `json:"occurredAt"`
+	Message    string          `json:"message,omitempty"`
+	HasImage   bool            `json:"hasImage,omitempty"`
+	Payload    json.RawMessage `json:"payload,omitempty"`
+}
+
+// TypeTag returns the tag identifying the event type in recordings and webhooks, empty for unknown types
+func TypeTag(e Event) string {
+	return eventTags[reflect.TypeOf(e)]
+}
+
+// Marshal encodes an event as a single JSON line (without the trailing new line) that Unmarshal can turn back into
+// the same event type
+func Marshal(e Event) ([]byte, error) {
+	t := reflect.TypeOf(e)
+	tag, found := eventTags[t]
+	if !found {
+		return nil, fmt.Errorf("unknown event type %s", t)
+	}
+
+	env := Envelope{
+		Type:       tag,
+		Supervisor: e.Supervisor(),
+		OccurredAt: e.OccurredAt(),
+		Message:    e.Message(),
+		HasImage:   e.Image() != nil,
+	}
+	if t != baseEventType {
+		payload, err := json.Marshal(Redact(e))
+		if err != nil {
+			return nil, fmt.Errorf("error encoding %s event: %w", tag, err)
+		}
+		if string(payload) != "{}" {
+			env.Payload = payload
+		}
+	}
+
+	return json.Marshal(env)
+}
+
+// Redact clears the secrets of the event, recordings and webhooks end up in places the game passwords shouldn't
+func Redact(e Event) Event {
+	switch ev := e.(type) {
+	case GameCreatedEvent:
+		ev.Password = ""
+		return ev
+	case RequestCompanionJoinGameEvent:
+		ev.Password = ""
+		return ev
+	}
+
+	return e
+}
+
+// Unmarshal decodes an event encoded by Marshal
+func Unmarshal(data []byte) (Event, error) {
+	var env Envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("error decoding event envelope: %w", err)
+	}
+
+	t, found := eventTypes[env.Type]
+	if !found {
+		return nil, fmt.Errorf("unknown event type tag %q", env.Type)
+	}
+
+	be := BaseEvent{
+		message:    env.Message,
+		occurredAt: env.OccurredAt,
+		supervisor: env.Supervisor,
+	}
+	if t == baseEventType {
+		return be, nil
+	}
+
+	v := reflect.New(t)
+	if len(env.Payload) > 0 {
+		if err := json.Unmarshal(env.Payload, v.Interface()); err != nil {
+			return nil, fmt.Errorf("error decoding %s event: %w", env.Type, err)
+		}
+	}
+	// Every event type embeds BaseEvent, its fields are unexported so they are restored here
+	v.Elem().FieldByName("BaseEvent").Set(reflect.ValueOf(be))
+
+	return v.Elem().Interface().(Event), nil
+}
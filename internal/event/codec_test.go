@@ -0,0 +1,74 @@
+package event
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+)
+
+func TestMarshalRoundTrip(t *testing.T) {
+	be := BaseEvent{
+		message:    "Run finished",
+		occurredAt: time.Date(2025, 3, 14, 18, 50, 0, 0, time.UTC),
+		supervisor: "sorc",
+	}
+
+	events := []Event{
+		be,
+		RunFinished(be, "mephisto", FinishedChicken, area.DuranceOfHateLevel3),
+		GameCreated(be, "game-1", ""),
+		CharacterSwitch(be, "sorc", "pala"),
+		CompanionRequestedTP(be),
+		ClientCrashed(be),
+		MovementRecovery(be, "stuck", "open door", 2, area.DuranceOfHateLevel2, data.Position{X: 17, Y: 40}),
+	}
+	for _, e := range events {
+		line, err := Marshal(e)
+		if err != nil {
+			t.Fatalf("marshal %T: %v", e, err)
+		}
+		got, err := Unmarshal(line)
+		if err != nil {
+			t.Fatalf("unmarshal %T: %v", e, err)
+		}
+		if !reflect.DeepEqual(got, e) {
+			t.Fatalf("round trip mismatch for %T:\n got %+v\nwant %+v", e, got, e)
+		}
+	}
+}
+
+func TestMarshalRedactsPasswords(t *testing.T) {
+	be := Text("sorc", "Game created")
+	for _, e := range []Event{
+		GameCreated(be, "game-1", "s3cr3t"),
+		RequestCompanionJoinGame(be, "sorc", "game-1", "s3cr3t"),
+	} {
+		line, err := Marshal(e)
+		if err != nil {
+			t.Fatalf("marshal %T: %v", e, err)
+		}
+		if strings.Contains(string(line), "s3cr3t") {
+			t.Errorf("%T password not redacted: %s", e, line)
+		}
+		if !strings.Contains(string(line), "game-1") {
+			t.Errorf("%T game name missing: %s", e, line)
+		}
+	}
+}
+
+func TestEveryEventTypeHasATag(t *testing.T) {
+	for tag, typ := range eventTypes {
+		if eventTags[typ] != tag {
+			t.Fatalf("type %s is registered under more than one tag", typ)
+		}
+		if typ != baseEventType {
+			if f, found := typ.FieldByName("BaseEvent"); !found || !f.Anonymous {
+				t.Fatalf("%s doesn't embed BaseEvent", typ)
+			}
+		}
+	}
+}
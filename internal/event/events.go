@@ -2,6 +2,7 @@ package event
 
 import (
 	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/area"
 )
 
 const (
@@ -47,12 +48,14 @@ func GameCreated(be BaseEvent, name string, password string) GameCreatedEvent {
 type GameFinishedEvent struct {
 	BaseEvent
 	Reason FinishReason
+	Area   area.ID // Area the player was in when the game finished
 }
 
-func GameFinished(be BaseEvent, reason FinishReason) GameFinishedEvent {
+func GameFinished(be BaseEvent, reason FinishReason, area area.ID) GameFinishedEvent {
 	return GameFinishedEvent{
 		BaseEvent: be,
 		Reason:    reason,
+		Area:      area,
 	}
 }
 
@@ -60,13 +63,15 @@ type RunFinishedEvent struct {
 	BaseEvent
 	RunName string
 	Reason  FinishReason
+	Area    area.ID // Area the player was in when the run finished
 }
 
-func RunFinished(be BaseEvent, runName string, reason FinishReason) RunFinishedEvent {
+func RunFinished(be BaseEvent, runName string, reason FinishReason, area area.ID) RunFinishedEvent {
 	return RunFinishedEvent{
 		BaseEvent: be,
 		RunName:   runName,
 		Reason:    reason,
+		Area:      area,
 	}
 }
 
@@ -181,3 +186,35 @@ func ResetCompanionGameInfo(be BaseEvent, leader string) ResetCompanionGameInfoE
 		Leader:    leader,
 	}
 }
+
+// ClientCrashedEvent is sent when the crash detector finds the game client closed and the supervisor is restarted
+type ClientCrashedEvent struct {
+	BaseEvent
+}
+
+func ClientCrashed(be BaseEvent) ClientCrashedEvent {
+	return ClientCrashedEvent{
+		BaseEvent: be,
+	}
+}
+
+// MovementRecoveryEvent is sent every time the movement watchdog tries to get the player unstuck
+type MovementRecoveryEvent struct {
+	BaseEvent
+	Reason   string
+	Strategy string
+	Attempt  int
+	Area     area.ID
+	Position data.Position
+}
+
+func MovementRecovery(be BaseEvent, reason, strategy string, attempt int, a area.ID, position data.Position) MovementRecoveryEvent {
+	return MovementRecoveryEvent{
+		BaseEvent: be,
+		Reason:    reason,
+		Strategy:  strategy,
+		Attempt:   attempt,
+		Area:      a,
+		Position:  position,
+	}
+}
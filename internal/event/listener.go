@@ -4,94 +4,238 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
-	"math"
-	"math/rand"
 	"os"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/hectorgimenez/koolo/internal/config"
 	"github.com/hectorgimenez/koolo/internal/utils"
 )
 
-var events = make(chan Event)
+var (
+	listenersMu sync.RWMutex
+	listeners   = make(map[*Listener]struct{})
+)
 
+// Listener fans out events to its handlers. By default every handler gets its own bounded queue and worker, so Send
+// never waits for a handler to finish. Synchronous listeners call the handlers from the sender goroutine instead, it's
+// meant for tests needing deterministic delivery. Handlers can be added and removed at any time from any goroutine.
 type Listener struct {
-	handlers         []Handler
-	deliveryHandlers map[int]Handler
-	logger           *slog.Logger
+	logger      *slog.Logger
+	synchronous bool
+	ctx         context.Context
+	cancel      context.CancelFunc
+
+	mu     sync.RWMutex
+	queues []*handlerQueue
 }
 
 type Handler func(ctx context.Context, e Event) error
 
 func NewListener(logger *slog.Logger) *Listener {
+	l := newListener(logger, false)
+	l.RegisterWithOptions(l.saveScreenshot, HandlerOptions{Name: "screenshots", QueueSize: 16, Policy: QueueDrop})
+
+	return l
+}
+
+// NewSyncListener returns a listener calling every handler in order from the goroutine sending the event
+func NewSyncListener(logger *slog.Logger) *Listener {
+	l := newListener(logger, true)
+	l.RegisterWithOptions(l.saveScreenshot, HandlerOptions{Name: "screenshots"})
+
+	return l
+}
+
+func newListener(logger *slog.Logger, synchronous bool) *Listener {
+	ctx, cancel := context.WithCancel(context.Background())
+
 	return &Listener{
-		logger:           logger,
-		deliveryHandlers: make(map[int]Handler),
+		logger:      logger,
+		synchronous: synchronous,
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+}
+
+// Register adds a handler receiving every event, the returned function removes it
+func (l *Listener) Register(h Handler) (unsubscribe func()) {
+	return l.RegisterWithOptions(h, DefaultHandlerOptions)
+}
+
+func (l *Listener) RegisterWithOptions(h Handler, opts HandlerOptions) (unsubscribe func()) {
+	return l.subscribe(newHandlerQueue(h, nil, opts, l.logger))
+}
+
+// Subscribe adds a handler only receiving events of type T, the returned function removes it
+func Subscribe[T Event](l *Listener, fn func(ctx context.Context, e T) error) (unsubscribe func()) {
+	return SubscribeWithOptions(l, fn, DefaultHandlerOptions)
+}
+
+// SubscribeWithOptions is Subscribe with custom queue options, set opts.Supervisor to only receive the events sent by
+// that supervisor
+func SubscribeWithOptions[T Event](l *Listener, fn func(ctx context.Context, e T) error, opts HandlerOptions) (unsubscribe func()) {
+	if opts.Name == "" {
+		opts.Name = handlerName(fn)
+	}
+	h := func(ctx context.Context, e Event) error {
+		return fn(ctx, e.(T))
 	}
+	accept := func(e Event) bool {
+		_, ok := e.(T)
+		return ok
+	}
+
+	return l.subscribe(newHandlerQueue(h, accept, opts, l.logger))
 }
 
-func (l *Listener) Register(h Handler) {
-	l.handlers = append(l.handlers, h)
+func (l *Listener) subscribe(q *handlerQueue) (unsubscribe func()) {
+	if !l.synchronous {
+		go q.run(l.ctx)
+	}
+
+	l.mu.Lock()
+	l.queues = append(l.queues, q)
+	l.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			l.queues = slices.DeleteFunc(l.queues, func(other *handlerQueue) bool { return other == q })
+			l.mu.Unlock()
+			// Events already queued are still delivered by the worker before it exits
+			q.close()
+		})
+	}
 }
 
+// Listen attaches the listener to Send until ctx is done, then waits for the queued events to be handled
 func (l *Listener) Listen(ctx context.Context) error {
-	for {
-		select {
-		case e := <-events:
-			if _, err := os.Stat("screenshots"); os.IsNotExist(err) {
-				err = os.MkdirAll("screenshots", os.ModePerm)
-				if err != nil {
-					l.logger.Error("error creating screenshots directory", slog.Any("error", err))
-				}
-			}
+	attach(l)
+	<-ctx.Done()
+	detach(l)
 
-			if e.Image() != nil && config.Koolo.Debug.Screenshots {
-				fileName := fmt.Sprintf("screenshots/error-%s.jpeg", time.Now().Format("2006-01-02 15_04_05"))
-				err := utils.SaveImageJPEG(e.Image(), fileName)
-				if err != nil {
-					l.logger.Error("error saving screenshot", slog.Any("error", err))
-				}
-			}
+	l.mu.RLock()
+	queues := append([]*handlerQueue{}, l.queues...)
+	l.mu.RUnlock()
+	for _, q := range queues {
+		q.close()
+	}
+	if !l.synchronous {
+		for _, q := range queues {
+			<-q.done
+		}
+	}
+	l.cancel()
 
-			for _, h := range l.handlers {
-				if err := h(ctx, e); err != nil && e.Message() != "" {
-					l.logger.Error("error running event handler", slog.Any("error", err))
-				}
-			}
-			for _, h := range l.deliveryHandlers {
-				if err := h(ctx, e); err != nil {
-					l.logger.Error("error running event delivery handler", slog.Any("error", err))
-				}
-			}
+	return nil
+}
+
+// Dispatch delivers an event to this listener only, Send dispatches to every listening listener
+func (l *Listener) Dispatch(e Event) {
+	l.mu.RLock()
+	queues := append([]*handlerQueue{}, l.queues...)
+	l.mu.RUnlock()
 
-		case <-ctx.Done():
-			return nil
+	for _, q := range queues {
+		if !q.accepts(e) {
+			continue
+		}
+		if l.synchronous {
+			q.handle(l.ctx, e)
+		} else {
+			q.push(e)
 		}
 	}
 }
 
-func (l *Listener) WaitForEvent(ctx context.Context) Event {
-	evtChan := make(chan Event)
-	idx := rand.Intn(math.MaxInt64)
-	l.deliveryHandlers[idx] = func(ctx context.Context, e Event) error {
-		evtChan <- e
+// QueueStats returns a snapshot of every handler queue
+func (l *Listener) QueueStats() []QueueStats {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	stats := make([]QueueStats, 0, len(l.queues))
+	for _, q := range l.queues {
+		stats = append(stats, q.stats())
+	}
+
+	return stats
+}
+
+// WaitFor blocks until an event of type T matching predicate is sent, or ctx is done. The predicate runs on the
+// goroutine sending the event, it must be cheap and must not send events itself. Only events sent after WaitFor is
+// called are considered.
+func WaitFor[T Event](ctx context.Context, predicate func(T) bool) (T, error) {
+	found := make(chan T, 1)
+	tap := newListener(slog.Default(), true)
+	SubscribeWithOptions(tap, func(_ context.Context, e T) error {
+		if predicate == nil || predicate(e) {
+			select {
+			case found <- e:
+			default:
+			}
+		}
 		return nil
+	}, HandlerOptions{Name: "wait-for"})
+
+	attach(tap)
+	defer detach(tap)
+
+	select {
+	case e := <-found:
+		return e, nil
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
 	}
-	// Clean up the handler when we're done
-	defer func() {
-		delete(l.deliveryHandlers, idx)
-	}()
-
-	for {
-		select {
-		case e := <-evtChan:
-			return e
-		case <-ctx.Done():
-			return nil
+}
+
+func (l *Listener) saveScreenshot(_ context.Context, e Event) error {
+	if e.Image() == nil || config.Koolo == nil || !config.Koolo.Debug.Screenshots {
+		return nil
+	}
+
+	if _, err := os.Stat("screenshots"); os.IsNotExist(err) {
+		err = os.MkdirAll("screenshots", os.ModePerm)
+		if err != nil {
+			l.logger.Error("error creating screenshots directory", slog.Any("error", err))
 		}
 	}
+
+	fileName := fmt.Sprintf("screenshots/error-%s.jpeg", time.Now().Format("2006-01-02 15_04_05"))
+	err := utils.SaveImageJPEG(e.Image(), fileName)
+	if err != nil {
+		l.logger.Error("error saving screenshot", slog.Any("error", err))
+	}
+
+	return nil
 }
 
+func attach(l *Listener) {
+	listenersMu.Lock()
+	listeners[l] = struct{}{}
+	listenersMu.Unlock()
+}
+
+func detach(l *Listener) {
+	listenersMu.Lock()
+	delete(listeners, l)
+	listenersMu.Unlock()
+}
+
+// Send publishes the event to every listening Listener, it doesn't wait for the handlers to run unless a handler
+// queue using QueueBlock is full. Events sent while nobody is listening are discarded.
 func Send(e Event) {
-	events <- e
+	listenersMu.RLock()
+	active := make([]*Listener, 0, len(listeners))
+	for l := range listeners {
+		active = append(active, l)
+	}
+	listenersMu.RUnlock()
+
+	for _, l := range active {
+		l.Dispatch(e)
+	}
 }
@@ -0,0 +1,189 @@
+package event
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+var testLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func TestSyncListenerHandlesBeforeSendReturns(t *testing.T) {
+	l := NewSyncListener(testLogger)
+	var got []string
+	l.Register(func(_ context.Context, e Event) error {
+		got = append(got, e.Message())
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go l.Listen(ctx)
+	waitUntilListening(t, l)
+
+	Send(Text("sup", "first"))
+	Send(Text("sup", "second"))
+
+	if len(got) != 2 || got[0] != "first" || got[1] != "second" {
+		t.Fatalf("expected both events in order, got %v", got)
+	}
+}
+
+func TestSlowHandlerDoesNotBlockSender(t *testing.T) {
+	l := NewListener(testLogger)
+	release := make(chan struct{})
+	l.RegisterWithOptions(func(_ context.Context, e Event) error {
+		<-release
+		return nil
+	}, HandlerOptions{Name: "slow", QueueSize: 1, Policy: QueueDrop})
+
+	sent := make(chan struct{})
+	go func() {
+		for range 5 {
+			l.Dispatch(Text("sup", "event"))
+		}
+		close(sent)
+	}()
+
+	select {
+	case <-sent:
+	case <-time.After(time.Second):
+		t.Fatal("sender was blocked by a slow handler")
+	}
+	close(release)
+
+	stats := l.QueueStats()
+	for _, qs := range stats {
+		if qs.Handler == "slow" && qs.Dropped == 0 {
+			t.Fatalf("expected dropped events, got %+v", qs)
+		}
+	}
+}
+
+func TestPanickingHandlerKeepsRunning(t *testing.T) {
+	l := NewListener(testLogger)
+	handled := make(chan string, 10)
+	l.RegisterWithOptions(func(_ context.Context, e Event) error {
+		if e.Message() == "panic" {
+			panic("broken handler")
+		}
+		handled <- e.Message()
+		return nil
+	}, HandlerOptions{Name: "panicky", QueueSize: 10, Policy: QueueBlock})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go l.Listen(ctx)
+	waitUntilListening(t, l)
+
+	l.Dispatch(Text("sup", "panic"))
+	l.Dispatch(Text("sup", "after"))
+
+	select {
+	case got := <-handled:
+		if got != "after" {
+			t.Fatalf("expected the event after the panic, got %s", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("handler stopped after a panic")
+	}
+	for _, qs := range l.QueueStats() {
+		if qs.Handler == "panicky" && qs.Errors != 1 {
+			t.Errorf("expected the panic counted as an error, got %+v", qs)
+		}
+	}
+}
+
+func TestListenDrainsQueuesOnShutdown(t *testing.T) {
+	l := NewListener(testLogger)
+	handled := make(chan struct{}, 10)
+	l.Register(func(_ context.Context, e Event) error {
+		handled <- struct{}{}
+		return nil
+	})
+
+	for range 3 {
+		l.Dispatch(Text("sup", "event"))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	l.Listen(ctx)
+
+	if len(handled) != 3 {
+		t.Fatalf("expected 3 handled events after shutdown, got %d", len(handled))
+	}
+}
+
+func TestSubscribeFiltersByTypeAndSupervisor(t *testing.T) {
+	l := NewSyncListener(testLogger)
+	var got []string
+	unsubscribe := SubscribeWithOptions(l, func(_ context.Context, e BaseEvent) error {
+		got = append(got, e.Message())
+		return nil
+	}, HandlerOptions{Supervisor: "sup"})
+
+	l.Dispatch(Text("sup", "first"))
+	l.Dispatch(Text("other", "ignored"))
+	l.Dispatch(GameCreated(Text("sup", "game"), "game", ""))
+	unsubscribe()
+	unsubscribe()
+	l.Dispatch(Text("sup", "after unsubscribe"))
+
+	if len(got) != 1 || got[0] != "first" {
+		t.Fatalf("expected only the first event, got %v", got)
+	}
+}
+
+func TestWaitForReturnsMatchingEvent(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	go func() {
+		for ctx.Err() == nil {
+			Send(Text("sup", "no"))
+			Send(Text("sup", "yes"))
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	e, err := WaitFor(ctx, func(e BaseEvent) bool { return e.Message() == "yes" })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if e.Message() != "yes" {
+		t.Fatalf("expected the matching event, got %q", e.Message())
+	}
+
+	listenersMu.RLock()
+	defer listenersMu.RUnlock()
+	if len(listeners) != 0 {
+		t.Fatalf("WaitFor left %d listeners attached", len(listeners))
+	}
+}
+
+func TestWaitForHonorsContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := WaitFor[BaseEvent](ctx, nil); err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func waitUntilListening(t *testing.T, l *Listener) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		listenersMu.RLock()
+		_, found := listeners[l]
+		listenersMu.RUnlock()
+		if found {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("listener never started listening")
+}
@@ -0,0 +1,208 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type QueuePolicy int
+
+const (
+	// QueueBlock makes the sender wait until the handler queue has room, no event is lost
+	QueueBlock QueuePolicy = iota
+	// QueueDrop discards the event for that handler when its queue is full, the sender never waits
+	QueueDrop
+)
+
+type HandlerOptions struct {
+	Name       string // Used in logs and queue stats, defaults to the handler function name
+	QueueSize  int    // Events buffered before Policy applies
+	Policy     QueuePolicy
+	Timeout    time.Duration // Deadline of the context passed to the handler, 0 disables it
+	Supervisor string        // Only deliver events sent by this supervisor, empty means all of them
+}
+
+// DefaultHandlerOptions are used by Register
+var DefaultHandlerOptions = HandlerOptions{
+	QueueSize: 256,
+	Policy:    QueueBlock,
+	Timeout:   30 * time.Second,
+}
+
+// QueueStats is a snapshot of a handler queue, counters are totals since the handler was registered
+type QueueStats struct {
+	Handler   string
+	Depth     int
+	Capacity  int
+	Processed uint64
+	Dropped   uint64
+	Timeouts  uint64
+	Errors    uint64
+}
+
+// handlerQueue owns the bounded queue and the worker goroutine of a single handler, so a slow handler only delays its
+// own events
+type handlerQueue struct {
+	name      string
+	handler   Handler
+	accept    func(Event) bool
+	opts      HandlerOptions
+	logger    *slog.Logger
+	events    chan Event
+	closing   chan struct{}
+	closeOnce sync.Once
+	done      chan struct{}
+
+	processed atomic.Uint64
+	dropped   atomic.Uint64
+	timeouts  atomic.Uint64
+	errors    atomic.Uint64
+}
+
+func newHandlerQueue(h Handler, accept func(Event) bool, opts HandlerOptions, logger *slog.Logger) *handlerQueue {
+	if opts.Name == "" {
+		opts.Name = handlerName(h)
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = DefaultHandlerOptions.QueueSize
+	}
+
+	return &handlerQueue{
+		name:    opts.Name,
+		handler: h,
+		accept:  accept,
+		opts:    opts,
+		logger:  logger,
+		events:  make(chan Event, opts.QueueSize),
+		closing: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// accepts filters events before they are queued, so filtered out events don't take room in the queue
+func (q *handlerQueue) accepts(e Event) bool {
+	if q.opts.Supervisor != "" && !strings.EqualFold(q.opts.Supervisor, e.Supervisor()) {
+		return false
+	}
+
+	return q.accept == nil || q.accept(e)
+}
+
+func (q *handlerQueue) push(e Event) {
+	select {
+	case <-q.closing:
+		return
+	default:
+	}
+
+	if q.opts.Policy == QueueDrop {
+		select {
+		case q.events <- e:
+		default:
+			if q.dropped.Add(1) == 1 {
+				q.logger.Warn("Event handler queue is full, dropping events", slog.String("handler", q.name))
+			}
+		}
+		return
+	}
+
+	select {
+	case q.events <- e:
+	case <-q.closing:
+	}
+}
+
+// run is the worker loop, once closing it still delivers whatever was already queued
+func (q *handlerQueue) run(ctx context.Context) {
+	defer close(q.done)
+	for {
+		select {
+		case e := <-q.events:
+			q.handle(ctx, e)
+		case <-q.closing:
+			for {
+				select {
+				case e := <-q.events:
+					q.handle(ctx, e)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (q *handlerQueue) handle(ctx context.Context, e Event) {
+	if q.opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, q.opts.Timeout)
+		defer cancel()
+	}
+
+	startedAt := time.Now()
+	panicked, err := q.call(ctx, e)
+	q.processed.Add(1)
+
+	if q.opts.Timeout > 0 && time.Since(startedAt) > q.opts.Timeout {
+		q.timeouts.Add(1)
+		q.logger.Warn("Event handler exceeded its timeout", slog.String("handler", q.name), slog.Duration("took", time.Since(startedAt)))
+	}
+	// Events without message are only meant for internal handlers, remote ones fail on them
+	if panicked || (err != nil && e.Message() != "") {
+		q.errors.Add(1)
+		q.logger.Error("error running event handler", slog.String("handler", q.name), slog.Any("error", err))
+	}
+}
+
+// call runs the handler, a panic is returned as an error so one broken handler doesn't take down its worker (and the
+// process with it)
+func (q *handlerQueue) call(ctx context.Context, e Event) (panicked bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			panicked, err = true, fmt.Errorf("handler panicked: %v\n%s", r, debug.Stack())
+		}
+	}()
+
+	return false, q.handler(ctx, e)
+}
+
+func (q *handlerQueue) close() {
+	q.closeOnce.Do(func() {
+		close(q.closing)
+	})
+}
+
+func (q *handlerQueue) stats() QueueStats {
+	return QueueStats{
+		Handler:   q.name,
+		Depth:     len(q.events),
+		Capacity:  cap(q.events),
+		Processed: q.processed.Load(),
+		Dropped:   q.dropped.Load(),
+		Timeouts:  q.timeouts.Load(),
+		Errors:    q.errors.Load(),
+	}
+}
+
+// handlerName turns "github.com/hectorgimenez/koolo/internal/remote/droplog.(*Writer).Handle-fm" into
+// "droplog.(*Writer).Handle"
+func handlerName(h any) string {
+	fn := runtime.FuncForPC(reflect.ValueOf(h).Pointer())
+	if fn == nil {
+		return "unknown"
+	}
+	name := strings.TrimSuffix(fn.Name(), "-fm")
+	if idx := strings.LastIndex(name, "/"); idx >= 0 {
+		name = name[idx+1:]
+	}
+
+	return name
+}
@@ -0,0 +1,53 @@
+package game
+
+import (
+	"image"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+)
+
+const (
+	RightButton MouseButton = 0x0002 // MK_RBUTTON
+	LeftButton  MouseButton = 0x0001 // MK_LBUTTON
+
+	ShiftKey ModifierKey = VKShift
+	CtrlKey  ModifierKey = VKControl
+)
+
+type MouseButton uint
+type ModifierKey byte
+
+// Reader is the read side of a game backend. Actions, runs and the pather only depend on this interface, MemoryReader
+// implements it against a live client and sim.Backend against an in-process world.
+type Reader interface {
+	GetData() Data
+	GetInventory() data.Inventory
+	InGame() bool
+	LegacyGraphics() bool
+	Screenshot() image.Image
+	GetMercList() []MercOption
+	GameAreaSize() (width, height int)
+}
+
+// Input is the mouse and keyboard side of a game backend.
+type Input interface {
+	MovePointer(x, y int)
+	Click(btn MouseButton, x, y int)
+	ClickWithModifier(btn MouseButton, x, y int, modifier ModifierKey)
+	PressKey(key byte)
+	PressKeyWithModifier(key byte, modifier ModifierKey)
+	PressKeyBinding(kb data.KeyBinding)
+	KeySequence(keysToPress ...byte)
+	KeyDown(kb data.KeyBinding)
+	KeyUp(kb data.KeyBinding)
+	GetASCIICode(key string) byte
+}
+
+// PacketInteractor sends the packets used as an alternative to clicking for pickups and interactions.
+type PacketInteractor interface {
+	PickUpItem(item data.Item) error
+	InteractWithTp(object data.Object) error
+	InteractWithEntrance(entrance data.Entrance) error
+}
+
+var _ PacketInteractor = (*PacketSender)(nil)
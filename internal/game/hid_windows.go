@@ -1,5 +1,10 @@
 package game
 
+var (
+	_ Reader = (*MemoryReader)(nil)
+	_ Input  = (*HID)(nil)
+)
+
 type HID struct {
 	gr *MemoryReader
 	gi *MemoryInjector
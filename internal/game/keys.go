@@ -0,0 +1,20 @@
+package game
+
+// Virtual-key codes sent through Input.PressKey, same values as the win.VK_* ones
+const (
+	VKBack    = 0x08
+	VKTab     = 0x09
+	VKReturn  = 0x0D
+	VKShift   = 0x10
+	VKControl = 0x11
+	VKEscape  = 0x1B
+	VKSpace   = 0x20
+	VKEnd     = 0x23
+	VKHome    = 0x24
+	VKLeft    = 0x25
+	VKUp      = 0x26
+	VKRight   = 0x27
+	VKDown    = 0x28
+	VKF1      = 0x70
+	VKF2      = 0x71
+)
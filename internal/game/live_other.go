@@ -0,0 +1,24 @@
+//go:build !windows
+
+package game
+
+import "errors"
+
+// The live client only runs on Windows, these stand-ins keep the packages referencing it buildable elsewhere, where
+// they run against a simulated backend (see package sim)
+
+var errNoLiveClient = errors.New("the game client is only available on Windows")
+
+type MemoryReader struct{}
+
+type MemoryInjector struct{}
+
+type Manager struct{}
+
+func (gm *Manager) ExitGame() error {
+	return errNoLiveClient
+}
+
+func (gm *Manager) InGame() bool {
+	return false
+}
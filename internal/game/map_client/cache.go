@@ -0,0 +1,184 @@
+package map_client
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data/difficulty"
+)
+
+// Bump it every time serverLevel changes, older entries are discarded
+const cacheVersion = 1
+
+// DefaultCacheEntries is the number of games kept in the cache when nothing is configured
+const DefaultCacheEntries = 200
+
+const cacheExtension = ".json.gz"
+
+// ErrNotCached is returned by fixture providers when the map data is not in the directory
+var ErrNotCached = errors.New("map data not cached")
+
+type cacheEntry struct {
+	Version    int                   `json:"version"`
+	Seed       uint                  `json:"seed"`
+	Difficulty difficulty.Difficulty `json:"difficulty"`
+	Levels     MapData               `json:"levels"`
+}
+
+// CachedProvider keeps the map data of the last games on disk, compressed, one file per seed and difficulty. Missing
+// games are fetched from the next provider and the least recently used ones are removed when there are too many.
+// Without a next provider it's a read only fixture directory.
+type CachedProvider struct {
+	mu         sync.Mutex
+	dir        string
+	maxEntries int
+	next       MapProvider
+}
+
+func NewCachedProvider(dir string, maxEntries int, next MapProvider) *CachedProvider {
+	if maxEntries <= 0 {
+		maxEntries = DefaultCacheEntries
+	}
+
+	return &CachedProvider{dir: dir, maxEntries: maxEntries, next: next}
+}
+
+// NewFixtureProvider reads the map data from a directory with files written by a CachedProvider, it's meant for
+// tests and tools running without the game
+func NewFixtureProvider(dir string) *CachedProvider {
+	return &CachedProvider{dir: dir}
+}
+
+func (p *CachedProvider) MapData(seed uint, difficulty difficulty.Difficulty) (MapData, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	path := p.path(seed, difficulty)
+	if levels, err := readEntry(path, seed, difficulty); err == nil {
+		if p.next != nil {
+			// Modification time is the last use, it's what the eviction goes by
+			now := time.Now()
+			_ = os.Chtimes(path, now, now)
+		}
+		return levels, nil
+	}
+
+	if p.next == nil {
+		return nil, fmt.Errorf("%w: seed %d, difficulty %s", ErrNotCached, seed, difficulty)
+	}
+
+	levels, err := p.next.MapData(seed, difficulty)
+	if err != nil {
+		return nil, err
+	}
+	// Caching it would break every game with this seed until the entry is evicted
+	if len(levels) == 0 {
+		return nil, fmt.Errorf("empty map data for seed %d, difficulty %s", seed, difficulty)
+	}
+	// Failing to cache is not a reason to fail the game, next time it will be fetched again
+	if err := p.write(path, cacheEntry{Version: cacheVersion, Seed: seed, Difficulty: difficulty, Levels: levels}); err == nil {
+		p.evict()
+	}
+
+	return levels, nil
+}
+
+func (p *CachedProvider) path(seed uint, difficulty difficulty.Difficulty) string {
+	return filepath.Join(p.dir, fmt.Sprintf("%d_%s%s", seed, getDifficultyAsNum(difficulty), cacheExtension))
+}
+
+func readEntry(path string, seed uint, difficulty difficulty.Difficulty) (MapData, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	var entry cacheEntry
+	if err := json.NewDecoder(gz).Decode(&entry); err != nil {
+		return nil, err
+	}
+	if entry.Version != cacheVersion || entry.Seed != seed || entry.Difficulty != difficulty {
+		return nil, fmt.Errorf("outdated cache entry %s", path)
+	}
+	// Written before empty map data was refused
+	if len(entry.Levels) == 0 {
+		return nil, fmt.Errorf("empty cache entry %s", path)
+	}
+
+	return entry.Levels, nil
+}
+
+// write saves the entry to a temporary file and renames it, so other supervisors never read half written files
+func (p *CachedProvider) write(path string, entry cacheEntry) error {
+	if err := os.MkdirAll(p.dir, 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(p.dir, "tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	gz := gzip.NewWriter(tmp)
+	if err := json.NewEncoder(gz).Encode(entry); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// evict removes the least recently used entries over the limit
+func (p *CachedProvider) evict() {
+	dirEntries, err := os.ReadDir(p.dir)
+	if err != nil {
+		return
+	}
+
+	type cached struct {
+		path    string
+		lastUse time.Time
+	}
+	var files []cached
+	for _, e := range dirEntries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), cacheExtension) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, cached{path: filepath.Join(p.dir, e.Name()), lastUse: info.ModTime()})
+	}
+	if len(files) <= p.maxEntries {
+		return
+	}
+
+	slices.SortFunc(files, func(a, b cached) int { return a.lastUse.Compare(b.lastUse) })
+	for _, f := range files[:len(files)-p.maxEntries] {
+		_ = os.Remove(f.path)
+	}
+}
@@ -0,0 +1,138 @@
+package map_client
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data/difficulty"
+)
+
+type countingProvider struct {
+	calls int
+	empty bool // Like a map server run that didn't print any level
+}
+
+func (p *countingProvider) MapData(seed uint, _ difficulty.Difficulty) (MapData, error) {
+	p.calls++
+	if p.empty {
+		return MapData{}, nil
+	}
+	lvl := serverLevel{Type: "map", ID: int(seed), Name: "Test", Map: [][]int{{1, 2, 3}}}
+	lvl.Size.Width, lvl.Size.Height = 6, 1
+	lvl.Objects = []serverObject{{ID: 2, Type: "exit", serverPosition: serverPosition{X: 3, Y: 4}}}
+
+	return MapData{lvl}, nil
+}
+
+func TestCachedProvider(t *testing.T) {
+	dir := t.TempDir()
+	next := &countingProvider{}
+
+	for i := 0; i < 2; i++ {
+		// New provider every time, like a bot restart
+		levels, err := NewCachedProvider(dir, 10, next).MapData(7, difficulty.Hell)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(levels) != 1 || levels[0].ID != 7 || levels[0].Objects[0].X != 3 || len(levels[0].CollisionGrid()[0]) != 6 {
+			t.Errorf("Unexpected levels %+v", levels)
+		}
+	}
+	if next.calls != 1 {
+		t.Errorf("Expected the map data to be fetched once, got %d", next.calls)
+	}
+
+	// Different difficulty is a different game
+	if _, err := NewCachedProvider(dir, 10, next).MapData(7, difficulty.Normal); err != nil {
+		t.Fatal(err)
+	}
+	if next.calls != 2 {
+		t.Errorf("Expected the map data to be fetched again for another difficulty, got %d calls", next.calls)
+	}
+}
+
+func TestCachedProviderDiscardsOutdatedEntries(t *testing.T) {
+	dir := t.TempDir()
+	p := NewCachedProvider(dir, 10, &countingProvider{})
+	if err := p.write(p.path(7, difficulty.Hell), cacheEntry{Version: cacheVersion - 1, Seed: 7, Difficulty: difficulty.Hell}); err != nil {
+		t.Fatal(err)
+	}
+
+	levels, err := p.MapData(7, difficulty.Hell)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(levels) != 1 {
+		t.Errorf("Expected outdated entry to be fetched again, got %+v", levels)
+	}
+}
+
+func TestCachedProviderRefusesEmptyMapData(t *testing.T) {
+	dir := t.TempDir()
+	next := &countingProvider{empty: true}
+	p := NewCachedProvider(dir, 10, next)
+
+	if _, err := p.MapData(7, difficulty.Hell); err == nil {
+		t.Fatal("Expected an error for empty map data")
+	}
+	if _, err := os.Stat(p.path(7, difficulty.Hell)); err == nil {
+		t.Fatal("Empty map data was cached")
+	}
+
+	// Entries cached before are fetched again
+	if err := p.write(p.path(7, difficulty.Hell), cacheEntry{Version: cacheVersion, Seed: 7, Difficulty: difficulty.Hell}); err != nil {
+		t.Fatal(err)
+	}
+	next.empty = false
+	if levels, err := p.MapData(7, difficulty.Hell); err != nil || len(levels) != 1 {
+		t.Errorf("Expected empty entry to be fetched again, got %+v, %v", levels, err)
+	}
+	if next.calls != 2 {
+		t.Errorf("Expected 2 fetches, got %d", next.calls)
+	}
+}
+
+func TestCachedProviderEvictsLeastRecentlyUsed(t *testing.T) {
+	dir := t.TempDir()
+	p := NewCachedProvider(dir, 2, &countingProvider{})
+
+	old := time.Now().Add(-time.Hour)
+	for _, seed := range []uint{1, 2} {
+		if _, err := p.MapData(seed, difficulty.Hell); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(p.path(seed, difficulty.Hell), old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Seed 1 is used again, so 2 is the least recently used one
+	if _, err := p.MapData(1, difficulty.Hell); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.MapData(3, difficulty.Hell); err != nil {
+		t.Fatal(err)
+	}
+
+	for seed, expected := range map[uint]bool{1: true, 2: false, 3: true} {
+		if _, err := os.Stat(p.path(seed, difficulty.Hell)); (err == nil) != expected {
+			t.Errorf("Expected seed %d to be cached: %v", seed, expected)
+		}
+	}
+}
+
+func TestFixtureProvider(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := NewCachedProvider(dir, 10, &countingProvider{}).MapData(7, difficulty.Hell); err != nil {
+		t.Fatal(err)
+	}
+
+	fixtures := NewFixtureProvider(dir)
+	if levels, err := fixtures.MapData(7, difficulty.Hell); err != nil || len(levels) != 1 {
+		t.Errorf("Expected recorded map data, got %+v, %v", levels, err)
+	}
+	if _, err := fixtures.MapData(8, difficulty.Hell); !errors.Is(err, ErrNotCached) {
+		t.Errorf("Expected ErrNotCached, got %v", err)
+	}
+}
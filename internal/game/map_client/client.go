@@ -1,43 +1,13 @@
 package map_client
 
 import (
-	"encoding/json"
-	"fmt"
-	"os/exec"
-	"strings"
-	"syscall"
-
 	"github.com/hectorgimenez/d2go/pkg/data"
 	"github.com/hectorgimenez/d2go/pkg/data/area"
 	"github.com/hectorgimenez/d2go/pkg/data/difficulty"
 	"github.com/hectorgimenez/d2go/pkg/data/npc"
 	"github.com/hectorgimenez/d2go/pkg/data/object"
-	"github.com/hectorgimenez/koolo/internal/config"
 )
 
-func GetMapData(seed string, difficulty difficulty.Difficulty) (MapData, error) {
-	cmd := exec.Command("./tools/koolo-map.exe", config.Koolo.D2LoDPath, "-s", seed, "-d", getDifficultyAsNum(difficulty))
-	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
-	stdout, err := cmd.Output()
-	if err != nil {
-		return nil, fmt.Errorf("error fetching Map data from Diablo II: LoD 1.13c game: %w", err)
-	}
-
-	stdoutLines := strings.Split(string(stdout), "\r\n")
-
-	lvls := make([]serverLevel, 0)
-	for _, line := range stdoutLines {
-		var lvl serverLevel
-		err = json.Unmarshal([]byte(line), &lvl)
-		// Discard empty lines or lines that don't contain level information
-		if err == nil && lvl.Type != "" && len(lvl.Map) > 0 {
-			lvls = append(lvls, lvl)
-		}
-	}
-
-	return lvls, nil
-}
-
 func getDifficultyAsNum(df difficulty.Difficulty) string {
 	switch df {
 	case difficulty.Normal:
@@ -0,0 +1,47 @@
+package map_client
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/hectorgimenez/d2go/pkg/data/difficulty"
+)
+
+// ExecProvider generates the map data with koolo-map.exe, it needs the Diablo II: LoD 1.13c game files
+type ExecProvider struct {
+	d2LoDPath string
+}
+
+func NewExecProvider(d2LoDPath string) *ExecProvider {
+	return &ExecProvider{d2LoDPath: d2LoDPath}
+}
+
+func (p *ExecProvider) MapData(seed uint, difficulty difficulty.Difficulty) (MapData, error) {
+	cmd := exec.Command("./tools/koolo-map.exe", p.d2LoDPath, "-s", strconv.Itoa(int(seed)), "-d", getDifficultyAsNum(difficulty))
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	stdout, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("error fetching Map data from Diablo II: LoD 1.13c game: %w", err)
+	}
+
+	stdoutLines := strings.Split(string(stdout), "\r\n")
+
+	lvls := make([]serverLevel, 0)
+	for _, line := range stdoutLines {
+		var lvl serverLevel
+		err = json.Unmarshal([]byte(line), &lvl)
+		// Discard empty lines or lines that don't contain level information
+		if err == nil && lvl.Type != "" && len(lvl.Map) > 0 {
+			lvls = append(lvls, lvl)
+		}
+	}
+	if len(lvls) == 0 {
+		return nil, fmt.Errorf("no level data returned by koolo-map.exe for seed %d, difficulty %s", seed, difficulty)
+	}
+
+	return lvls, nil
+}
@@ -0,0 +1,8 @@
+package map_client
+
+import "github.com/hectorgimenez/d2go/pkg/data/difficulty"
+
+// MapProvider supplies the map data of a game: the koolo-map executable, the disk cache or a fixture directory
+type MapProvider interface {
+	MapData(seed uint, difficulty difficulty.Difficulty) (MapData, error)
+}
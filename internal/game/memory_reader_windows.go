@@ -4,7 +4,6 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
-	"strconv"
 	"sync"
 	"time"
 
@@ -29,6 +28,7 @@ type MemoryReader struct {
 	GameAreaSizeY  int
 	supervisorName string
 	cachedMapData  map[area.ID]AreaData
+	maps           map_client.MapProvider
 	logger         *slog.Logger
 }
 
@@ -43,6 +43,7 @@ func NewGameReader(cfg *config.CharacterCfg, supervisorName string, pid uint32,
 		HWND:           window,
 		supervisorName: supervisorName,
 		cfg:            cfg,
+		maps:           newMapProvider(),
 		logger:         logger,
 	}
 
@@ -51,6 +52,18 @@ func NewGameReader(cfg *config.CharacterCfg, supervisorName string, pid uint32,
 	return gr, nil
 }
 
+// Parsed map data is cached in this directory, generating it takes a while on every game start
+const mapCacheDir = "cache/maps"
+
+func newMapProvider() map_client.MapProvider {
+	exe := map_client.NewExecProvider(config.Koolo.D2LoDPath)
+	if config.Koolo.MapCache.Disabled {
+		return exe
+	}
+
+	return map_client.NewCachedProvider(mapCacheDir, config.Koolo.MapCache.MaxEntries, exe)
+}
+
 func (gd *MemoryReader) MapSeed() uint {
 	return gd.mapSeed
 }
@@ -62,7 +75,7 @@ func (gd *MemoryReader) FetchMapData() error {
 	cfg, _ := config.GetCharacter(gd.supervisorName)
 	gd.logger.Debug("Fetching map data...", slog.Uint64("seed", uint64(gd.mapSeed)), slog.String("difficulty", string(cfg.Game.Difficulty)))
 
-	mapData, err := map_client.GetMapData(strconv.Itoa(int(gd.mapSeed)), cfg.Game.Difficulty)
+	mapData, err := gd.maps.MapData(gd.mapSeed, cfg.Game.Difficulty)
 	if err != nil {
 		return fmt.Errorf("error fetching map data: %w", err)
 	}
@@ -218,6 +231,11 @@ func (gd *MemoryReader) updateWindowPositionData() {
 	gd.GameAreaSizeY = int(pos.RcNormalPosition.Bottom) - gd.WindowTopY - 9
 }
 
+// GameAreaSize returns the size in pixels of the game window client area
+func (gd *MemoryReader) GameAreaSize() (int, int) {
+	return gd.GameAreaSizeX, gd.GameAreaSizeY
+}
+
 func (gd *MemoryReader) GetData() Data {
 	d := gd.GameReader.GetData()
 	currentArea, ok := gd.cachedMapData[d.PlayerUnit.Area]
@@ -0,0 +1,15 @@
+//go:build !windows
+
+package game
+
+import "github.com/hectorgimenez/d2go/pkg/data/skill"
+
+// MercOption mirrors memory.MercOption, the d2go memory package only builds on Windows
+type MercOption struct {
+	Index int
+	Name  string
+	Skill struct {
+		ID   skill.ID
+		Name string
+	}
+}
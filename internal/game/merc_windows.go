@@ -0,0 +1,5 @@
+package game
+
+import "github.com/hectorgimenez/d2go/pkg/memory"
+
+type MercOption = memory.MercOption
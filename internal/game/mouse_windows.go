@@ -7,17 +7,6 @@ import (
 	"github.com/lxn/win"
 )
 
-const (
-	RightButton MouseButton = win.MK_RBUTTON
-	LeftButton  MouseButton = win.MK_LBUTTON
-
-	ShiftKey ModifierKey = win.VK_SHIFT
-	CtrlKey  ModifierKey = win.VK_CONTROL
-)
-
-type MouseButton uint
-type ModifierKey byte
-
 // MovePointer moves the mouse to the requested position, x and y should be the final position based on
 // pixels shown in the screen. Top-left corner is 0,0
 func (hid *HID) MovePointer(x, y int) {
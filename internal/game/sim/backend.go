@@ -0,0 +1,355 @@
+package sim
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"slices"
+	"sync"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
+	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/game"
+)
+
+// Backend is a deterministic in-process game implementing game.Reader, game.Input and game.PacketInteractor. Every
+// GetData call advances the world one tick, input is applied immediately, so the same scenario driven by the same
+// actions always ends in the same state.
+type Backend struct {
+	mu             sync.Mutex
+	world          *World
+	cfg            config.CharacterCfg
+	keyBindings    data.KeyBindings
+	mercs          []game.MercOption
+	legacyGraphics bool
+	gameAreaSizeX  int
+	gameAreaSizeY  int
+	pointer        data.Position
+	modifier       game.ModifierKey
+	inputLog       []string
+}
+
+var (
+	_ game.Reader           = (*Backend)(nil)
+	_ game.Input            = (*Backend)(nil)
+	_ game.PacketInteractor = (*Backend)(nil)
+)
+
+func NewBackend(s Scenario) *Backend {
+	b := &Backend{
+		world:          newWorld(s),
+		cfg:            s.CharacterCfg,
+		keyBindings:    s.KeyBindings,
+		mercs:          s.Mercs,
+		legacyGraphics: s.LegacyGraphics,
+		gameAreaSizeX:  s.GameAreaSizeX,
+		gameAreaSizeY:  s.GameAreaSizeY,
+	}
+	if b.gameAreaSizeX == 0 || b.gameAreaSizeY == 0 {
+		b.gameAreaSizeX, b.gameAreaSizeY = defaultGameAreaSizeX, defaultGameAreaSizeY
+	}
+
+	return b
+}
+
+// Do runs fn with exclusive access to the world, it's the way tests script events or assert on the state
+func (b *Backend) Do(fn func(w *World)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	fn(b.world)
+}
+
+// InputLog returns every input received so far, in order
+func (b *Backend) InputLog() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return append([]string{}, b.inputLog...)
+}
+
+func (b *Backend) GetData() game.Data {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.world.step()
+	b.updateHover()
+
+	return b.snapshot()
+}
+
+func (b *Backend) GetInventory() data.Inventory {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.inventory()
+}
+
+func (b *Backend) InGame() bool {
+	return true
+}
+
+func (b *Backend) LegacyGraphics() bool {
+	return b.legacyGraphics
+}
+
+// Screenshot returns a blank frame, there is nothing to render
+func (b *Backend) Screenshot() image.Image {
+	return image.NewRGBA(image.Rect(0, 0, b.gameAreaSizeX, b.gameAreaSizeY))
+}
+
+func (b *Backend) GetMercList() []game.MercOption {
+	return b.mercs
+}
+
+func (b *Backend) GameAreaSize() (int, int) {
+	return b.gameAreaSizeX, b.gameAreaSizeY
+}
+
+func (b *Backend) PickUpItem(it data.Item) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.logInput("packet pickup %d", it.UnitID)
+
+	return b.pickUp(it.UnitID)
+}
+
+func (b *Backend) InteractWithTp(object data.Object) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.logInput("packet tp %d", object.ID)
+
+	for _, o := range b.world.Objects[b.world.Player.Area] {
+		if o.ID == object.ID && (o.IsPortal() || o.IsRedPortal()) {
+			b.world.enterArea(o.PortalData.DestArea, data.Position{})
+			return nil
+		}
+	}
+
+	return fmt.Errorf("portal %d not found", object.ID)
+}
+
+func (b *Backend) InteractWithEntrance(entrance data.Entrance) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.logInput("packet entrance %d", entrance.ID)
+
+	lvl, found := b.closestLevel(entrance.Position, true)
+	if !found {
+		return fmt.Errorf("entrance %d not found", entrance.ID)
+	}
+	b.world.enterArea(lvl.Area, data.Position{})
+
+	return nil
+}
+
+func (b *Backend) snapshot() game.Data {
+	w := b.world
+	player := w.Player
+	player.Stats = slices.Clone(w.Player.Stats)
+	d := data.Data{
+		PlayerUnit:     player,
+		Monsters:       cloneMonsters(w.Monsters[w.Player.Area]),
+		Objects:        append(data.Objects{}, w.Objects[w.Player.Area]...),
+		Inventory:      b.inventory(),
+		OpenMenus:      w.OpenMenus,
+		HoverData:      w.HoverData,
+		KeyBindings:    b.keyBindings,
+		LegacyGraphics: b.legacyGraphics,
+		IsIngame:       true,
+	}
+	for i := range d.Monsters {
+		d.Monsters[i].IsHovered = w.HoverData.IsHovered && w.HoverData.UnitType == 1 && w.HoverData.UnitID == d.Monsters[i].UnitID
+	}
+	for i := range d.Objects {
+		d.Objects[i].IsHovered = w.HoverData.IsHovered && w.HoverData.UnitType == 2 && w.HoverData.UnitID == d.Objects[i].ID
+	}
+
+	areaData := w.Areas[w.Player.Area]
+	if areaData.Grid != nil {
+		d.AreaOrigin = data.Position{X: areaData.OffsetX, Y: areaData.OffsetY}
+	}
+	d.NPCs = areaData.NPCs
+	d.AdjacentLevels = areaData.AdjacentLevels
+	d.Rooms = areaData.Rooms
+
+	return game.Data{
+		Data:         d,
+		CharacterCfg: b.cfg,
+		AreaData:     areaData,
+		Areas:        w.Areas,
+	}
+}
+
+func (b *Backend) inventory() data.Inventory {
+	inv := b.world.Inventory
+	inv.AllItems = append([]data.Item{}, b.world.Inventory.AllItems...)
+	for _, it := range b.world.GroundItems[b.world.Player.Area] {
+		it.IsHovered = b.world.HoverData.IsHovered && b.world.HoverData.UnitType == 4 && b.world.HoverData.UnitID == it.UnitID
+		inv.AllItems = append(inv.AllItems, it)
+	}
+
+	return inv
+}
+
+// updateHover resolves which unit is below the mouse pointer, in the same priority the game does: monsters first, then
+// items, objects and finally level entrances
+func (b *Backend) updateHover() {
+	w := b.world
+	w.HoverData = data.HoverData{}
+	if w.OpenMenus.IsMenuOpen() {
+		return
+	}
+	pos := b.pointerWorldPosition()
+
+	if m, found := b.monsterAt(pos); found {
+		w.HoverData = data.HoverData{IsHovered: true, UnitID: m.UnitID, UnitType: 1}
+		return
+	}
+	if it, found := b.groundItemAt(pos); found {
+		w.HoverData = data.HoverData{IsHovered: true, UnitID: it.UnitID, UnitType: 4}
+		return
+	}
+	if o, found := b.objectAt(pos); found {
+		w.HoverData = data.HoverData{IsHovered: true, UnitID: o.ID, UnitType: 2}
+		return
+	}
+	if _, found := b.closestLevel(pos, true); found {
+		w.HoverData = data.HoverData{IsHovered: true, UnitType: 5}
+	}
+}
+
+// pointerWorldPosition is the inverse of the isometric transform used by ui.GameCoordsToScreenCords
+func (b *Backend) pointerWorldPosition() data.Position {
+	a := (float64(b.pointer.X) - float64(b.gameAreaSizeX/2)) / 19.8
+	c := (float64(b.pointer.Y) - float64(b.gameAreaSizeY/2)) / 9.9
+
+	return data.Position{
+		X: b.world.Player.Position.X + int(math.Round((a+c)/2)),
+		Y: b.world.Player.Position.Y + int(math.Round((c-a)/2)),
+	}
+}
+
+func (b *Backend) monsterAt(pos data.Position) (*data.Monster, bool) {
+	monsters := b.world.Monsters[b.world.Player.Area]
+	idx := closest(len(monsters), pos, func(i int) (data.Position, bool) {
+		// Town NPCs don't have life
+		return monsters[i].Position, monsters[i].Stats[stat.Life] > 0 || monsters[i].IsGoodNPC()
+	})
+	if idx < 0 {
+		return nil, false
+	}
+
+	return &monsters[idx], true
+}
+
+func (b *Backend) groundItemAt(pos data.Position) (data.Item, bool) {
+	items := b.world.GroundItems[b.world.Player.Area]
+	idx := closest(len(items), pos, func(i int) (data.Position, bool) {
+		return items[i].Position, true
+	})
+	if idx < 0 {
+		return data.Item{}, false
+	}
+
+	return items[idx], true
+}
+
+func (b *Backend) objectAt(pos data.Position) (*data.Object, bool) {
+	objects := b.world.Objects[b.world.Player.Area]
+	idx := closest(len(objects), pos, func(i int) (data.Position, bool) {
+		return objects[i].Position, objects[i].Selectable
+	})
+	if idx < 0 {
+		return nil, false
+	}
+
+	return &objects[idx], true
+}
+
+func (b *Backend) closestLevel(pos data.Position, entrancesOnly bool) (data.Level, bool) {
+	levels := b.world.Areas[b.world.Player.Area].AdjacentLevels
+	idx := closest(len(levels), pos, func(i int) (data.Position, bool) {
+		return levels[i].Position, levels[i].IsEntrance || !entrancesOnly
+	})
+	if idx < 0 {
+		return data.Level{}, false
+	}
+
+	return levels[idx], true
+}
+
+// closest returns the index of the candidate nearest to pos within hoverRadius, or -1
+func closest(n int, pos data.Position, candidate func(i int) (data.Position, bool)) int {
+	best, bestDistance := -1, hoverRadius+1
+	for i := 0; i < n; i++ {
+		p, ok := candidate(i)
+		if !ok {
+			continue
+		}
+		distance := max(abs(p.X-pos.X), abs(p.Y-pos.Y))
+		if distance < bestDistance {
+			best, bestDistance = i, distance
+		}
+	}
+
+	return best
+}
+
+func (b *Backend) pickUp(unitID data.UnitID) error {
+	w := b.world
+	items := w.GroundItems[w.Player.Area]
+	for i, it := range items {
+		if it.UnitID != unitID {
+			continue
+		}
+		if max(abs(it.Position.X-w.Player.Position.X), abs(it.Position.Y-w.Player.Position.Y)) > pickupRange {
+			return fmt.Errorf("item %d is too far away", unitID)
+		}
+		slot, found := freeInventorySlot(w.Inventory, it)
+		if !found {
+			return fmt.Errorf("no room in inventory for item %d", unitID)
+		}
+		it.Position = slot
+		it.Location = item.Location{LocationType: item.LocationInventory}
+		w.Inventory.AllItems = append(w.Inventory.AllItems, it)
+		w.GroundItems[w.Player.Area] = append(items[:i:i], items[i+1:]...)
+
+		return nil
+	}
+
+	return fmt.Errorf("item %d not found on the ground", unitID)
+}
+
+func freeInventorySlot(inv data.Inventory, it data.Item) (data.Position, bool) {
+	matrix := inv.Matrix()
+	width, height := max(it.Desc().InventoryWidth, 1), max(it.Desc().InventoryHeight, 1)
+	for x := 0; x+width <= len(matrix[0]); x++ {
+		for y := 0; y+height <= len(matrix); y++ {
+			free := true
+			for i := 0; i < width && free; i++ {
+				for j := 0; j < height && free; j++ {
+					free = !matrix[y+j][x+i]
+				}
+			}
+			if free {
+				return data.Position{X: x, Y: y}, true
+			}
+		}
+	}
+
+	return data.Position{}, false
+}
+
+func (b *Backend) logInput(format string, args ...any) {
+	b.inputLog = append(b.inputLog, fmt.Sprintf(format, args...))
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+
+	return v
+}
@@ -0,0 +1,83 @@
+package sim
+
+import (
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/d2go/pkg/data/npc"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
+	"github.com/hectorgimenez/koolo/internal/game"
+)
+
+func testScenario() Scenario {
+	cg := make([][]game.CollisionType, 40)
+	for y := range cg {
+		cg[y] = make([]game.CollisionType, 40)
+		for x := range cg[y] {
+			cg[y][x] = game.CollisionTypeWalkable
+		}
+	}
+
+	return Scenario{
+		Seed: 1,
+		Areas: map[area.ID]game.AreaData{
+			area.BloodMoor: {Area: area.BloodMoor, Grid: game.NewGrid(cg, 100, 100, false)},
+		},
+		Player: data.PlayerUnit{Area: area.BloodMoor, Position: data.Position{X: 120, Y: 120}},
+		Monsters: map[area.ID]data.Monsters{
+			area.BloodMoor: {{UnitID: 10, Name: npc.Zombie, Position: data.Position{X: 125, Y: 125}, Stats: map[stat.ID]int{stat.Life: 150}}},
+		},
+		GroundItems: map[area.ID][]data.Item{
+			area.BloodMoor: {{UnitID: 20, Name: "Ring", Position: data.Position{X: 122, Y: 121}, Location: item.Location{LocationType: item.LocationGround}}},
+		},
+	}
+}
+
+func (b *Backend) screenCoords(p data.Position) (int, int) {
+	player := b.world.Player.Position
+	diffX, diffY := p.X-player.X, p.Y-player.Y
+
+	return int(float32(diffX-diffY)*19.8) + b.gameAreaSizeX/2, int(float32(diffX+diffY)*9.9) + b.gameAreaSizeY/2
+}
+
+func TestClickMovesPlayerTowardsPointer(t *testing.T) {
+	b := NewBackend(testScenario())
+	x, y := b.screenCoords(data.Position{X: 110, Y: 120})
+
+	b.Click(game.LeftButton, x, y)
+	for range 10 {
+		b.GetData()
+	}
+
+	if pos := b.GetData().PlayerUnit.Position; pos != (data.Position{X: 110, Y: 120}) {
+		t.Fatalf("expected player at 110,120, got %v", pos)
+	}
+}
+
+func TestClickOnMonsterAttacksIt(t *testing.T) {
+	b := NewBackend(testScenario())
+	x, y := b.screenCoords(data.Position{X: 125, Y: 125})
+
+	b.Click(game.LeftButton, x, y)
+	b.Click(game.LeftButton, x, y)
+
+	d := b.GetData()
+	if len(d.Monsters.Enemies()) != 0 {
+		t.Fatalf("expected monster to be dead, life: %d", d.Monsters[0].Stats[stat.Life])
+	}
+}
+
+func TestPickUpItem(t *testing.T) {
+	b := NewBackend(testScenario())
+
+	if err := b.PickUpItem(data.Item{UnitID: 20}); err != nil {
+		t.Fatal(err)
+	}
+
+	inv := b.GetInventory()
+	if len(inv.ByLocation(item.LocationGround)) != 0 || len(inv.ByLocation(item.LocationInventory)) != 1 {
+		t.Fatalf("expected item to be moved to the inventory, got %+v", inv.AllItems)
+	}
+}
@@ -0,0 +1,110 @@
+package sim
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/koolo/internal/config"
+	botCtx "github.com/hectorgimenez/koolo/internal/context"
+	"github.com/hectorgimenez/koolo/internal/event"
+	"github.com/hectorgimenez/koolo/internal/game"
+	"github.com/hectorgimenez/koolo/internal/health"
+	"github.com/hectorgimenez/koolo/internal/pather"
+)
+
+// NewContext builds a bot context wired to the simulated backend and attaches it to the calling goroutine, the same way
+// the supervisor manager does it for a live client. The character is left for the caller to build, since it depends on
+// the character config being tested. ctx.EventListener is synchronous, handlers run before event.Send returns, but it
+// only receives events sent through the global bus while it is listening.
+//
+// Game data is refreshed after every input on the goroutine sending it, instead of by a background routine, so the bot
+// sees the result of its actions without a second goroutine writing ctx.Data. Use Refresh for the live behavior.
+func NewContext(name string, b *Backend, cfg *config.CharacterCfg, logger *slog.Logger) *botCtx.Status {
+	// Tests don't load koolo.yaml, the defaults are used
+	if config.Koolo == nil {
+		config.Koolo = &config.KooloCfg{}
+	}
+
+	ctx := botCtx.NewContext(name)
+
+	bm := health.NewBeltManager(ctx.Data, b, logger, name)
+
+	ctx.CharacterCfg = cfg
+	ctx.EventListener = event.NewSyncListener(logger)
+	ctx.HID = refreshingInput{Backend: b, ctx: ctx.Context}
+	ctx.PacketSender = b
+	ctx.GameReader = b
+	ctx.Logger = logger
+	ctx.PathFinder = pather.NewPathFinder(b, ctx.Data, b, cfg)
+	ctx.BeltManager = bm
+	ctx.HealthManager = health.NewHealthManager(bm, ctx.Data)
+	*ctx.Data = b.GetData()
+
+	return ctx
+}
+
+// Refresh keeps the context game data up to date until ctx is done, it replaces the refresh routine Bot.Run starts
+// for live clients
+func Refresh(ctx context.Context, c *botCtx.Context, interval time.Duration) {
+	c.AttachRoutine(botCtx.PriorityBackground)
+	defer c.Detach()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.RefreshGameData()
+		}
+	}
+}
+
+// refreshingInput refreshes the context game data after every input
+type refreshingInput struct {
+	*Backend
+	ctx *botCtx.Context
+}
+
+func (r refreshingInput) MovePointer(x, y int) {
+	r.Backend.MovePointer(x, y)
+	r.ctx.RefreshGameData()
+}
+
+func (r refreshingInput) Click(btn game.MouseButton, x, y int) {
+	r.Backend.Click(btn, x, y)
+	r.ctx.RefreshGameData()
+}
+
+func (r refreshingInput) ClickWithModifier(btn game.MouseButton, x, y int, modifier game.ModifierKey) {
+	r.Backend.ClickWithModifier(btn, x, y, modifier)
+	r.ctx.RefreshGameData()
+}
+
+func (r refreshingInput) PressKey(key byte) {
+	r.Backend.PressKey(key)
+	r.ctx.RefreshGameData()
+}
+
+func (r refreshingInput) PressKeyWithModifier(key byte, modifier game.ModifierKey) {
+	r.Backend.PressKeyWithModifier(key, modifier)
+	r.ctx.RefreshGameData()
+}
+
+func (r refreshingInput) PressKeyBinding(kb data.KeyBinding) {
+	r.Backend.PressKeyBinding(kb)
+	r.ctx.RefreshGameData()
+}
+
+func (r refreshingInput) KeySequence(keysToPress ...byte) {
+	r.Backend.KeySequence(keysToPress...)
+	r.ctx.RefreshGameData()
+}
+
+func (r refreshingInput) KeyDown(kb data.KeyBinding) {
+	r.Backend.KeyDown(kb)
+	r.ctx.RefreshGameData()
+}
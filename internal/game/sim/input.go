@@ -0,0 +1,267 @@
+package sim
+
+import (
+	"strings"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/d2go/pkg/data/mode"
+	"github.com/hectorgimenez/d2go/pkg/data/object"
+	"github.com/hectorgimenez/d2go/pkg/data/skill"
+	"github.com/hectorgimenez/koolo/internal/game"
+	"github.com/hectorgimenez/koolo/internal/ui"
+)
+
+func (b *Backend) MovePointer(x, y int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.pointer = data.Position{X: x, Y: y}
+	b.updateHover()
+}
+
+func (b *Backend) Click(btn game.MouseButton, x, y int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.pointer = data.Position{X: x, Y: y}
+	b.logInput("click %d %d,%d", btn, x, y)
+	b.click(btn)
+}
+
+func (b *Backend) ClickWithModifier(btn game.MouseButton, x, y int, modifier game.ModifierKey) {
+	b.mu.Lock()
+	b.modifier = modifier
+	b.mu.Unlock()
+
+	b.Click(btn, x, y)
+
+	b.mu.Lock()
+	b.modifier = 0
+	b.mu.Unlock()
+}
+
+func (b *Backend) PressKey(key byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.logInput("key %d", key)
+	b.pressKey(key)
+}
+
+func (b *Backend) PressKeyWithModifier(key byte, modifier game.ModifierKey) {
+	b.mu.Lock()
+	b.modifier = modifier
+	b.mu.Unlock()
+
+	b.PressKey(key)
+
+	b.mu.Lock()
+	b.modifier = 0
+	b.mu.Unlock()
+}
+
+func (b *Backend) PressKeyBinding(kb data.KeyBinding) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.logInput("keybinding %v", kb.Key1)
+	b.pressKeyBinding(kb)
+}
+
+// KeySequence applies every key immediately, the simulator doesn't need the delay between keys a real client does
+func (b *Backend) KeySequence(keysToPress ...byte) {
+	for _, key := range keysToPress {
+		b.PressKey(key)
+	}
+}
+
+func (b *Backend) KeyDown(kb data.KeyBinding) {
+	b.PressKeyBinding(kb)
+}
+
+func (b *Backend) KeyUp(_ data.KeyBinding) {}
+
+func (b *Backend) GetASCIICode(key string) byte {
+	return strings.ToUpper(key)[0]
+}
+
+func (b *Backend) pressKey(key byte) {
+	w := b.world
+	switch key {
+	case game.VKEscape, game.VKSpace:
+		w.closeMenus()
+	case game.VKHome:
+		w.menuCursor = 0
+	case game.VKDown:
+		w.menuCursor++
+	case game.VKUp:
+		w.menuCursor = max(w.menuCursor-1, 0)
+	case game.VKReturn:
+		w.selectMenuEntry()
+	default:
+		for _, kb := range [][2]byte{b.keyBindings.Inventory.Key1, b.keyBindings.Inventory.Key2} {
+			if kb[0] == key && key != 0 {
+				w.OpenMenus.Inventory = !w.OpenMenus.Inventory
+				return
+			}
+		}
+	}
+}
+
+func (b *Backend) pressKeyBinding(kb data.KeyBinding) {
+	w := b.world
+	// Actions without a binding can't be triggered
+	if kb == (data.KeyBinding{}) {
+		return
+	}
+	if kb == b.keyBindings.ForceMove {
+		dest := b.pointerWorldPosition()
+		w.destination = &dest
+		return
+	}
+	if kb == b.keyBindings.Inventory {
+		w.OpenMenus.Inventory = !w.OpenMenus.Inventory
+		return
+	}
+	for _, sb := range b.keyBindings.Skills {
+		if sb.KeyBinding == kb && sb.SkillID != 0 {
+			w.Player.RightSkill = sb.SkillID
+			return
+		}
+	}
+	for column, belt := range b.keyBindings.UseBelt {
+		if belt == kb {
+			b.drinkFromBelt(column)
+			return
+		}
+	}
+}
+
+func (b *Backend) drinkFromBelt(column int) {
+	w := b.world
+	for i, it := range w.Inventory.AllItems {
+		if it.Location.LocationType == item.LocationBelt && it.Position.X == column {
+			w.Inventory.AllItems = append(w.Inventory.AllItems[:i:i], w.Inventory.AllItems[i+1:]...)
+			w.heal()
+			return
+		}
+	}
+}
+
+func (b *Backend) click(btn game.MouseButton) {
+	w := b.world
+
+	switch {
+	case w.OpenMenus.Waypoint:
+		b.clickWaypointMenu()
+		return
+	case w.OpenMenus.Stash || w.OpenMenus.NPCShop:
+		if b.modifier == game.CtrlKey {
+			b.moveItemUnderPointer()
+		}
+		return
+	case w.OpenMenus.IsMenuOpen():
+		return
+	}
+
+	if btn == game.RightButton && w.Player.RightSkill == skill.Teleport {
+		if dest := b.pointerWorldPosition(); w.walkable(dest) {
+			w.Player.Position = dest
+			w.crossAreaBorder()
+		}
+		return
+	}
+
+	b.updateHover()
+	switch w.HoverData.UnitType {
+	case 1:
+		if m, found := b.monsterAt(b.pointerWorldPosition()); found {
+			if menu, isNPC := w.npcMenus[m.Name]; isNPC && w.Player.Area.IsTown() {
+				w.openNPCMenu(menu)
+				return
+			}
+			if !m.IsGoodNPC() {
+				w.attack(m)
+			}
+		}
+	case 4:
+		_ = b.pickUp(w.HoverData.UnitID)
+	case 2:
+		if o, found := b.objectAt(b.pointerWorldPosition()); found {
+			b.interactObject(o)
+		}
+	case 5:
+		if lvl, found := b.closestLevel(b.pointerWorldPosition(), true); found {
+			w.enterArea(lvl.Area, data.Position{})
+		}
+	default:
+		dest := b.pointerWorldPosition()
+		w.destination = &dest
+	}
+}
+
+func (b *Backend) interactObject(o *data.Object) {
+	w := b.world
+	switch {
+	case o.IsWaypoint():
+		w.OpenMenus.Waypoint = true
+		w.wpTab = area.WPAddresses[w.Player.Area].Tab
+	case o.Name == object.Bank:
+		w.OpenMenus.Stash = true
+		w.OpenMenus.Inventory = true
+	case o.IsPortal() || o.IsRedPortal():
+		w.enterArea(o.PortalData.DestArea, data.Position{})
+	case o.IsDoor() || o.IsChest() || o.IsSuperChest():
+		o.Mode = mode.ObjectModeOpened
+		o.Selectable = false
+	}
+}
+
+func (b *Backend) clickWaypointMenu() {
+	w := b.world
+	tabStartX, tabStartY, tabSizeX := ui.WpTabStartX, ui.WpTabStartY, ui.WpTabSizeX
+	listX, listStartY, btnHeight := ui.WpListPositionX, ui.WpListStartY, ui.WpAreaBtnHeight
+	if b.legacyGraphics {
+		tabStartX, tabStartY, tabSizeX = ui.WpTabStartXClassic, ui.WpTabStartYClassic, ui.WpTabSizeXClassic
+		listX, listStartY, btnHeight = ui.WpListPositionXClassic, ui.WpListStartYClassic, ui.WpAreaBtnHeightClassic
+	}
+
+	if b.pointer.Y == tabStartY {
+		w.wpTab = (b.pointer.X-tabStartX)/tabSizeX + 1
+		return
+	}
+	if b.pointer.X != listX {
+		return
+	}
+
+	row := (b.pointer.Y-listStartY)/btnHeight + 1
+	for dest, wp := range area.WPAddresses {
+		if wp.Tab == w.wpTab && wp.Row == row {
+			w.enterArea(dest, data.Position{})
+			return
+		}
+	}
+}
+
+// moveItemUnderPointer emulates ctrl+click on an inventory item while stash or vendor are open: the item is moved to the
+// personal stash, or sold if a vendor is open
+func (b *Backend) moveItemUnderPointer() {
+	w := b.world
+	for i, it := range w.Inventory.AllItems {
+		if it.Location.LocationType != item.LocationInventory {
+			continue
+		}
+		center := ui.ScreenCoordsForItem(it, b.legacyGraphics)
+		if abs(center.X-b.pointer.X) > 16 || abs(center.Y-b.pointer.Y) > 16 {
+			continue
+		}
+		if w.OpenMenus.NPCShop {
+			w.Inventory.AllItems = append(w.Inventory.AllItems[:i:i], w.Inventory.AllItems[i+1:]...)
+			return
+		}
+		w.Inventory.AllItems[i].Location = item.Location{LocationType: item.LocationStash}
+		return
+	}
+}
@@ -0,0 +1,86 @@
+package sim
+
+import (
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+)
+
+// MenuAction is what happens when an NPC menu entry is selected
+type MenuAction int
+
+const (
+	MenuActionTalk MenuAction = iota
+	MenuActionTrade
+	MenuActionIdentify
+	MenuActionHeal
+	MenuActionResurrect
+	MenuActionCancel
+)
+
+// NPCMenu is the dialog shown when interacting with an NPC. Entries are selected the same way the bot does it in game:
+// HOME moves the cursor to the first entry, UP/DOWN move it and RETURN selects it.
+type NPCMenu struct {
+	Entries []MenuAction
+	// Vendor items shown when a trade entry is selected, positions are relative to the vendor grid
+	Vendor []data.Item
+}
+
+func (w *World) openNPCMenu(menu NPCMenu) {
+	w.activeMenu = &menu
+	w.menuCursor = 0
+	w.OpenMenus.NPCInteract = true
+}
+
+func (w *World) selectMenuEntry() {
+	if w.activeMenu == nil || w.menuCursor >= len(w.activeMenu.Entries) {
+		return
+	}
+
+	w.OpenMenus.NPCInteract = false
+	switch w.activeMenu.Entries[w.menuCursor] {
+	case MenuActionTrade:
+		w.OpenMenus.NPCShop = true
+		w.OpenMenus.Inventory = true
+		w.showVendorItems(w.activeMenu.Vendor)
+	case MenuActionIdentify:
+		for i, it := range w.Inventory.AllItems {
+			if it.Location.LocationType == item.LocationInventory {
+				w.Inventory.AllItems[i].Identified = true
+			}
+		}
+		w.activeMenu = nil
+	case MenuActionHeal:
+		w.heal()
+		w.activeMenu = nil
+	default:
+		w.activeMenu = nil
+	}
+}
+
+func (w *World) showVendorItems(items []data.Item) {
+	w.hideVendorItems()
+	for _, it := range items {
+		if it.UnitID == 0 {
+			it.UnitID = w.NewUnitID()
+		}
+		it.Location = item.Location{LocationType: item.LocationVendor}
+		w.Inventory.AllItems = append(w.Inventory.AllItems, it)
+	}
+}
+
+func (w *World) hideVendorItems() {
+	items := w.Inventory.AllItems[:0]
+	for _, it := range w.Inventory.AllItems {
+		if it.Location.LocationType != item.LocationVendor {
+			items = append(items, it)
+		}
+	}
+	w.Inventory.AllItems = items
+}
+
+func (w *World) closeMenus() {
+	w.hideVendorItems()
+	w.OpenMenus = data.OpenMenus{}
+	w.activeMenu = nil
+	w.wpTab = 0
+}
@@ -0,0 +1,44 @@
+package sim
+
+import (
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/d2go/pkg/data/skill"
+	"github.com/hectorgimenez/koolo/internal/game"
+)
+
+// OpenArea returns an area without walls, every tile of the grid is walkable
+func OpenArea(id area.ID, offsetX, offsetY, width, height int) game.AreaData {
+	cg := make([][]game.CollisionType, height)
+	for y := range cg {
+		cg[y] = make([]game.CollisionType, width)
+		for x := range cg[y] {
+			cg[y][x] = game.CollisionTypeWalkable
+		}
+	}
+
+	return game.AreaData{Area: id, Grid: game.NewGrid(cg, offsetX, offsetY, false)}
+}
+
+// NewItem returns an item of the given name and quality placed at the position, the item type is looked up by name so
+// its size and description match the real item
+func NewItem(unitID data.UnitID, name item.Name, quality item.Quality, pos data.Position) data.Item {
+	return data.Item{UnitID: unitID, ID: item.GetIDByName(string(name)), Name: name, Quality: quality, Position: pos}
+}
+
+// DefaultKeyBindings binds the keys the bot needs to move, stand still and open the inventory, and the given skills to
+// F1 and the following function keys
+func DefaultKeyBindings(skills ...skill.ID) data.KeyBindings {
+	kb := data.KeyBindings{
+		Inventory:  data.KeyBinding{Key1: [2]byte{'I', 0}},
+		StandStill: data.KeyBinding{Key1: [2]byte{game.VKShift, 0}},
+		ForceMove:  data.KeyBinding{Key1: [2]byte{'E', 0}},
+		ShowItems:  data.KeyBinding{Key1: [2]byte{'A', 0}},
+	}
+	for i, id := range skills[:min(len(skills), len(kb.Skills))] {
+		kb.Skills[i] = data.SkillBinding{SkillID: id, KeyBinding: data.KeyBinding{Key1: [2]byte{byte(game.VKF1 + i), 0}}}
+	}
+
+	return kb
+}
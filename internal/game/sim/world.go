@@ -0,0 +1,316 @@
+package sim
+
+import (
+	"maps"
+	"math/rand"
+	"slices"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/d2go/pkg/data/mode"
+	"github.com/hectorgimenez/d2go/pkg/data/npc"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
+	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/game"
+)
+
+const (
+	defaultGameAreaSizeX = 1280
+	defaultGameAreaSizeY = 720
+	defaultWalkSpeed     = 3 // tiles per tick
+	defaultAttackDamage  = 100
+	hoverRadius          = 2
+	pickupRange          = 6
+)
+
+// MonsterScript is called on every tick for the monster it is registered to, it can move the monster, change its
+// stats or mode. The world is locked while scripts run, so they must not call back into the Backend.
+type MonsterScript func(w *World, m *data.Monster)
+
+// Scenario describes the initial state of a simulated game. Every field is copied when the Backend is created, the
+// same Scenario and Seed always produce the same sequence of states for the same sequence of inputs.
+type Scenario struct {
+	Seed           int64
+	Areas          map[area.ID]game.AreaData
+	Player         data.PlayerUnit
+	Monsters       map[area.ID]data.Monsters
+	Objects        map[area.ID][]data.Object
+	GroundItems    map[area.ID][]data.Item
+	Inventory      data.Inventory
+	MonsterScripts map[data.UnitID]MonsterScript
+	NPCMenus       map[npc.ID]NPCMenu
+	Mercs          []game.MercOption
+	KeyBindings    data.KeyBindings
+	CharacterCfg   config.CharacterCfg
+	LegacyGraphics bool
+	GameAreaSizeX  int
+	GameAreaSizeY  int
+	WalkSpeed      int
+	AttackDamage   int
+}
+
+// World is the mutable game state owned by a Backend
+type World struct {
+	Tick        int
+	Rand        *rand.Rand
+	Areas       map[area.ID]game.AreaData
+	Player      data.PlayerUnit
+	Monsters    map[area.ID]data.Monsters
+	Objects     map[area.ID][]data.Object
+	GroundItems map[area.ID][]data.Item
+	Inventory   data.Inventory
+	OpenMenus   data.OpenMenus
+	HoverData   data.HoverData
+
+	scripts      map[data.UnitID]MonsterScript
+	npcMenus     map[npc.ID]NPCMenu
+	activeMenu   *NPCMenu
+	menuCursor   int
+	wpTab        int
+	destination  *data.Position
+	walkSpeed    int
+	attackDamage int
+	nextUnitID   data.UnitID
+}
+
+func newWorld(s Scenario) *World {
+	w := &World{
+		Rand:         rand.New(rand.NewSource(s.Seed)),
+		Areas:        make(map[area.ID]game.AreaData, len(s.Areas)),
+		Player:       s.Player,
+		Monsters:     make(map[area.ID]data.Monsters),
+		Objects:      make(map[area.ID][]data.Object),
+		GroundItems:  make(map[area.ID][]data.Item),
+		Inventory:    s.Inventory,
+		scripts:      make(map[data.UnitID]MonsterScript),
+		npcMenus:     make(map[npc.ID]NPCMenu),
+		walkSpeed:    s.WalkSpeed,
+		attackDamage: s.AttackDamage,
+		nextUnitID:   1,
+	}
+	w.Player.Stats = slices.Clone(s.Player.Stats)
+	if w.walkSpeed == 0 {
+		w.walkSpeed = defaultWalkSpeed
+	}
+	if w.attackDamage == 0 {
+		w.attackDamage = defaultAttackDamage
+	}
+
+	for id, ad := range s.Areas {
+		w.Areas[id] = ad
+	}
+	for id, monsters := range s.Monsters {
+		w.Monsters[id] = cloneMonsters(monsters)
+	}
+	for id, objects := range s.Objects {
+		w.Objects[id] = append([]data.Object{}, objects...)
+	}
+	for id, items := range s.GroundItems {
+		w.GroundItems[id] = make([]data.Item, len(items))
+		for i, it := range items {
+			it.Location = item.Location{LocationType: item.LocationGround}
+			w.GroundItems[id][i] = it
+		}
+	}
+	w.Inventory.AllItems = append([]data.Item{}, s.Inventory.AllItems...)
+	for id, script := range s.MonsterScripts {
+		w.scripts[id] = script
+	}
+	for id, menu := range s.NPCMenus {
+		w.npcMenus[id] = menu
+	}
+
+	return w
+}
+
+// NewUnitID returns an unit id not used by any scripted entity yet, useful for scripts spawning drops or monsters
+func (w *World) NewUnitID() data.UnitID {
+	w.nextUnitID++
+	for w.unitIDInUse(w.nextUnitID) {
+		w.nextUnitID++
+	}
+
+	return w.nextUnitID
+}
+
+// Drop places an item on the ground at the given position of the current area
+func (w *World) Drop(it data.Item, pos data.Position) {
+	if it.UnitID == 0 {
+		it.UnitID = w.NewUnitID()
+	}
+	it.Position = pos
+	it.Location = item.Location{LocationType: item.LocationGround}
+	w.GroundItems[w.Player.Area] = append(w.GroundItems[w.Player.Area], it)
+}
+
+func (w *World) unitIDInUse(id data.UnitID) bool {
+	for _, monsters := range w.Monsters {
+		for _, m := range monsters {
+			if m.UnitID == id {
+				return true
+			}
+		}
+	}
+	for _, items := range w.GroundItems {
+		for _, it := range items {
+			if it.UnitID == id {
+				return true
+			}
+		}
+	}
+	for _, it := range w.Inventory.AllItems {
+		if it.UnitID == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// step advances the world by one tick: scripted monsters act, and the player moves towards its destination
+func (w *World) step() {
+	w.Tick++
+
+	monsters := w.Monsters[w.Player.Area]
+	for i := range monsters {
+		if script, found := w.scripts[monsters[i].UnitID]; found {
+			script(w, &monsters[i])
+		}
+	}
+
+	if w.destination == nil {
+		return
+	}
+
+	dest := *w.destination
+	for range w.walkSpeed {
+		next := w.Player.Position
+		next.X += sign(dest.X - next.X)
+		next.Y += sign(dest.Y - next.Y)
+		if next == w.Player.Position || !w.walkable(next) {
+			w.destination = nil
+			break
+		}
+		w.Player.Position = next
+		w.crossAreaBorder()
+	}
+
+	if w.destination != nil && w.Player.Position == dest {
+		w.destination = nil
+	}
+	w.Player.Mode = w.idleMode()
+	if w.destination != nil {
+		w.Player.Mode = mode.Walking
+		if w.Player.Area.IsTown() {
+			w.Player.Mode = mode.WalkingInTown
+		}
+	}
+}
+
+func (w *World) idleMode() mode.PlayerMode {
+	if w.Player.Area.IsTown() {
+		return mode.StandingInTown
+	}
+
+	return mode.StandingOutsideTown
+}
+
+// walkable checks the collision grid of any loaded area containing the position, so the player can walk through
+// level borders that are not entrances
+func (w *World) walkable(p data.Position) bool {
+	if ad, found := w.Areas[w.Player.Area]; found && ad.Grid != nil && ad.IsInside(p) {
+		return ad.IsWalkable(p)
+	}
+	for _, ad := range w.Areas {
+		if ad.Grid != nil && ad.IsInside(p) {
+			return ad.IsWalkable(p)
+		}
+	}
+
+	return false
+}
+
+func (w *World) crossAreaBorder() {
+	if ad, found := w.Areas[w.Player.Area]; found && ad.Grid != nil && ad.IsInside(w.Player.Position) {
+		return
+	}
+	for id, ad := range w.Areas {
+		if ad.Grid != nil && ad.IsInside(w.Player.Position) {
+			w.enterArea(id, w.Player.Position)
+			return
+		}
+	}
+}
+
+// enterArea moves the player into another area, if pos is empty the player is placed next to the level link pointing
+// back to the area it comes from, or in the middle of the new area if there is none
+func (w *World) enterArea(id area.ID, pos data.Position) {
+	from := w.Player.Area
+	w.Player.Area = id
+	w.destination = nil
+	w.OpenMenus = data.OpenMenus{}
+	w.activeMenu = nil
+
+	if pos == (data.Position{}) {
+		ad := w.Areas[id]
+		for _, lvl := range ad.AdjacentLevels {
+			if lvl.Area == from {
+				pos = lvl.Position
+				break
+			}
+		}
+		if pos == (data.Position{}) && ad.Grid != nil {
+			pos = data.Position{X: ad.OffsetX + ad.Width/2, Y: ad.OffsetY + ad.Height/2}
+		}
+	}
+	w.Player.Position = pos
+	w.Player.Mode = w.idleMode()
+}
+
+func (w *World) attack(m *data.Monster) {
+	if m.Stats == nil {
+		m.Stats = make(map[stat.ID]int)
+	}
+	m.Stats[stat.Life] = max(m.Stats[stat.Life]-w.attackDamage, 0)
+	if m.Stats[stat.Life] == 0 {
+		m.Mode = mode.NpcDead
+	}
+}
+
+func (w *World) heal() {
+	for _, pair := range [][2]stat.ID{{stat.Life, stat.MaxLife}, {stat.Mana, stat.MaxMana}} {
+		maxValue, found := w.Player.FindStat(pair[1], 0)
+		if !found {
+			continue
+		}
+		for i, st := range w.Player.Stats {
+			if st.ID == pair[0] && st.Layer == 0 {
+				w.Player.Stats[i].Value = maxValue.Value
+			}
+		}
+	}
+}
+
+// cloneMonsters copies the monsters along with their stats and states, attacks change them in place
+func cloneMonsters(monsters data.Monsters) data.Monsters {
+	cloned := make(data.Monsters, len(monsters))
+	for i, m := range monsters {
+		m.Stats = maps.Clone(m.Stats)
+		m.States = slices.Clone(m.States)
+		cloned[i] = m
+	}
+
+	return cloned
+}
+
+func sign(v int) int {
+	switch {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
+	}
+
+	return 0
+}
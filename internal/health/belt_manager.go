@@ -12,12 +12,12 @@ import (
 
 type BeltManager struct {
 	data       *game.Data
-	hid        *game.HID
+	hid        game.Input
 	logger     *slog.Logger
 	supervisor string
 }
 
-func NewBeltManager(data *game.Data, hid *game.HID, logger *slog.Logger, supervisor string) *BeltManager {
+func NewBeltManager(data *game.Data, hid game.Input, logger *slog.Logger, supervisor string) *BeltManager {
 	return &BeltManager{
 		data:       data,
 		hid:        hid,
@@ -2,6 +2,7 @@ package health
 
 import (
 	"log/slog"
+	"sync/atomic"
 	"time"
 )
 
@@ -16,6 +17,7 @@ type PingMonitor struct {
 	Enabled            bool
 	Logger             *slog.Logger
 	OnHighPingDetected func() // Callback when sustained high ping detected
+	lastPing           atomic.Int64
 }
 
 // NewPingMonitor creates a new ping monitor with default settings
@@ -35,6 +37,7 @@ func NewPingMonitor(logger *slog.Logger, threshold int, sustainedDuration time.D
 // Logic: Track start time when ping exceeds threshold, reset when it drops below
 // If ping stays high for longer than sustained duration, trigger action
 func (pm *PingMonitor) CheckPing(currentPing int) bool {
+	pm.lastPing.Store(int64(currentPing))
 	if !pm.Enabled {
 		return false
 	}
@@ -95,6 +98,11 @@ func (pm *PingMonitor) CheckPing(currentPing int) bool {
 	return false
 }
 
+// LastPing returns the last ping passed to CheckPing, even when monitoring is disabled
+func (pm *PingMonitor) LastPing() int {
+	return int(pm.lastPing.Load())
+}
+
 // Reset clears the high ping tracking state
 func (pm *PingMonitor) Reset() {
 	pm.HighPingStart = time.Time{}
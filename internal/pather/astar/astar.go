@@ -1,8 +1,8 @@
 package astar
 
 import (
-	"container/heap"
 	"math"
+	"sync"
 
 	"github.com/hectorgimenez/d2go/pkg/data"
 	"github.com/hectorgimenez/koolo/internal/game"
@@ -19,14 +19,6 @@ var directions = []data.Position{
 	{-1, -1}, // Up-Left (Northwest)
 }
 
-type Node struct {
-	data.Position
-	Cost     int
-	Priority int
-	Index    int
-	TpStreak int
-}
-
 func direction(from, to data.Position) (dx, dy int) {
 	dx = to.X - from.X
 	dy = to.Y - from.Y
@@ -35,54 +27,130 @@ func direction(from, to data.Position) (dx, dy int) {
 
 const MaxConsecutiveTeleportOver = 12
 
+// Search buffers are reused between calls, allocating them for every path was most of the time spent on big grids
+var searchers = sync.Pool{New: func() any { return &searcher{} }}
+
 func CalculatePath(g *game.Grid, start, goal data.Position, canTeleport bool) ([]data.Position, int, bool) {
-	pq := make(PriorityQueue, 0)
-	heap.Init(&pq)
-
-	// Use a 2D slice to store the cost of each node
-	costSoFar := make([][]int, g.Width)
-	cameFrom := make([][]data.Position, g.Width)
-	for i := range costSoFar {
-		costSoFar[i] = make([]int, g.Height)
-		cameFrom[i] = make([]data.Position, g.Height)
-		for j := range costSoFar[i] {
-			costSoFar[i][j] = math.MaxInt32
+	s := searchers.Get().(*searcher)
+	defer searchers.Put(s)
+
+	return s.path(g, start, goal, canTeleport, nil)
+}
+
+// CostLayer adds an extra cost to walk through some tiles, like the ones close to dangerous monsters
+type CostLayer interface {
+	ExtraCost(x, y int) int
+}
+
+// CalculatePathWithLayer is CalculatePath adding the costs of layer to the tiles
+func CalculatePathWithLayer(g *game.Grid, start, goal data.Position, canTeleport bool, layer CostLayer) ([]data.Position, int, bool) {
+	s := searchers.Get().(*searcher)
+	defer searchers.Put(s)
+
+	s.layer = layer
+	defer func() { s.layer = nil }()
+
+	return s.path(g, start, goal, canTeleport, nil)
+}
+
+// searcher holds the scratch buffers of a search, cells are indexed by y*width+x and only the ones stamped with the
+// current generation are valid, so there is no need to clear them between searches
+type searcher struct {
+	gen   uint32
+	stamp []uint32
+	cost  []int32
+	from  []int32
+	open  openSet
+	layer CostLayer // Optional
+}
+
+func (s *searcher) reset(size int) {
+	if cap(s.stamp) < size {
+		s.stamp = make([]uint32, size)
+		s.cost = make([]int32, size)
+		s.from = make([]int32, size)
+		s.gen = 0
+	}
+	s.stamp = s.stamp[:size]
+	s.cost = s.cost[:size]
+	s.from = s.from[:size]
+
+	s.gen++
+	if s.gen == 0 {
+		clear(s.stamp)
+		s.gen = 1
+	}
+	s.open = s.open[:0]
+}
+
+func (s *searcher) costAt(i int) int32 {
+	if s.stamp[i] != s.gen {
+		return math.MaxInt32
+	}
+	return s.cost[i]
+}
+
+func (s *searcher) path(g *game.Grid, start, goal data.Position, canTeleport bool, corridor *clusterMask) ([]data.Position, int, bool) {
+	if !s.search(g, start, goal, true, canTeleport, corridor) {
+		return nil, 0, false
+	}
+
+	// Path is built backwards, teleport over tiles are skipped
+	startIdx := int32(start.Y*g.Width + start.X)
+	var path []data.Position
+	for i := int32(goal.Y*g.Width + goal.X); i != startIdx; i = s.from[i] {
+		x, y := int(i)%g.Width, int(i)/g.Width
+		if g.CollisionGrid[y][x] == game.CollisionTypeTeleportOver {
+			continue
 		}
+		path = append(path, data.Position{X: x, Y: y})
+	}
+	path = append(path, start)
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
 	}
 
-	startNode := &Node{Position: start, Cost: 0, Priority: heuristic(start, goal)}
-	heap.Push(&pq, startNode)
-	costSoFar[start.X][start.Y] = 0
+	return path, len(path), true
+}
+
+// search runs A* from start to goal, without goal it explores every reachable cell (Dijkstra) leaving the costs in
+// the buffers. corridor, when set, limits the search to some clusters of the grid.
+func (s *searcher) search(g *game.Grid, start, goal data.Position, hasGoal, canTeleport bool, corridor *clusterMask) bool {
+	if start.X < 0 || start.X >= g.Width || start.Y < 0 || start.Y >= g.Height {
+		return false
+	}
+	s.reset(g.Width * g.Height)
 
-	neighbors := make([]data.Position, 0, 8)
+	startIdx := start.Y*g.Width + start.X
+	s.stamp[startIdx] = s.gen
+	s.cost[startIdx] = 0
+	s.open.push(node{x: int32(start.X), y: int32(start.Y), priority: int32(heuristic(start, goal))})
 
-	for pq.Len() > 0 {
-		current := heap.Pop(&pq).(*Node)
+	for len(s.open) > 0 {
+		current := s.open.pop()
+		cx, cy := int(current.x), int(current.y)
 
-		// Let's build the path if we reached the goal
-		if current.Position == goal {
-			var path []data.Position
-			for p := goal; p != start; p = cameFrom[p.X][p.Y] {
-				if g.CollisionGrid[p.Y][p.X] == game.CollisionTypeTeleportOver {
-					continue
-				}
-				path = append([]data.Position{p}, path...)
-			}
-			path = append([]data.Position{start}, path...)
-			return path, len(path), true
+		if hasGoal && cx == goal.X && cy == goal.Y {
+			return true
 		}
 
-		updateNeighbors(g, current, &neighbors, canTeleport)
+		currentCost := s.cost[cy*g.Width+cx]
+		for _, d := range directions {
+			nx, ny := cx+d.X, cy+d.Y
+			if isBlocked(g, nx, ny, canTeleport) || !corridor.contains(nx, ny) {
+				continue
+			}
+			// Diagonal moves can't cut corners
+			if d.X != 0 && d.Y != 0 && (isBlocked(g, nx, cy, canTeleport) || isBlocked(g, cx, ny, canTeleport)) {
+				continue
+			}
 
-		for _, neighbor := range neighbors {
-			tileType := g.CollisionGrid[neighbor.Y][neighbor.X]
+			tileType := g.CollisionGrid[ny][nx]
 
 			// Determine teleport streak
-			teleportStreak := 0
+			teleportStreak := int8(0)
 			if tileType == game.CollisionTypeTeleportOver {
-				teleportStreak = current.TpStreak + 1
-			} else {
-				teleportStreak = 0
+				teleportStreak = current.tpStreak + 1
 			}
 
 			// Skip if exceeds allowed consecutive teleport tiles
@@ -90,7 +158,10 @@ func CalculatePath(g *game.Grid, start, goal data.Position, canTeleport bool) ([
 				continue
 			}
 
-			newCost := costSoFar[current.X][current.Y] + getCost(tileType, canTeleport)
+			newCost := currentCost + int32(getCost(tileType, canTeleport))
+			if s.layer != nil {
+				newCost += int32(s.layer.ExtraCost(nx, ny))
+			}
 
 			// Handicap for changing direction, this prevents zig-zagging around obstacles
 			//curDirX, curDirY := direction(cameFrom[current.X][current.Y], current.Position)
@@ -99,51 +170,30 @@ func CalculatePath(g *game.Grid, start, goal data.Position, canTeleport bool) ([
 			//	newCost++
 			//}
 
-			if newCost < costSoFar[neighbor.X][neighbor.Y] {
-				costSoFar[neighbor.X][neighbor.Y] = newCost
-				priority := newCost + int(0.5*float64(heuristic(neighbor, goal)))
-				heap.Push(&pq, &Node{Position: neighbor, Cost: newCost, Priority: priority, TpStreak: teleportStreak})
-				cameFrom[neighbor.X][neighbor.Y] = current.Position
+			ni := ny*g.Width + nx
+			if newCost < s.costAt(ni) {
+				s.stamp[ni] = s.gen
+				s.cost[ni] = newCost
+				s.from[ni] = int32(cy*g.Width + cx)
+
+				priority := newCost
+				if hasGoal {
+					priority += int32(0.5 * float64(heuristic(data.Position{X: nx, Y: ny}, goal)))
+				}
+				s.open.push(node{x: int32(nx), y: int32(ny), priority: priority, tpStreak: teleportStreak})
 			}
 		}
 	}
 
-	return nil, 0, false
+	return false
 }
 
-// Get walkable neighbors of a given node
-func updateNeighbors(grid *game.Grid, node *Node, neighbors *[]data.Position, canTeleport bool) {
-	*neighbors = (*neighbors)[:0]
-
-	x, y := node.X, node.Y
-	gridWidth, gridHeight := grid.Width, grid.Height
-
-	isBlocked := func(px, py int) bool {
-		if px < 0 || px >= gridWidth || py < 0 || py >= gridHeight {
-			return true
-		}
-		collisionType := grid.CollisionGrid[py][px]
-		return collisionType == game.CollisionTypeNonWalkable || (!canTeleport && collisionType == game.CollisionTypeTeleportOver)
-	}
-
-	for _, d := range directions {
-		newX, newY := x+d.X, y+d.Y
-
-		if isBlocked(newX, newY) {
-			continue
-		}
-
-		if d.X != 0 && d.Y != 0 {
-			adj1X, adj1Y := x+d.X, y
-			adj2X, adj2Y := x, y+d.Y
-
-			if isBlocked(adj1X, adj1Y) || isBlocked(adj2X, adj2Y) {
-				continue
-			}
-		}
-
-		*neighbors = append(*neighbors, data.Position{X: newX, Y: newY})
+func isBlocked(g *game.Grid, x, y int, canTeleport bool) bool {
+	if x < 0 || x >= g.Width || y < 0 || y >= g.Height {
+		return true
 	}
+	collisionType := g.CollisionGrid[y][x]
+	return collisionType == game.CollisionTypeNonWalkable || (!canTeleport && collisionType == game.CollisionTypeTeleportOver)
 }
 
 func getCost(tileType game.CollisionType, canTeleport bool) int {
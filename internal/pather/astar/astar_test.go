@@ -1,16 +1,16 @@
 package astar
 
 import (
-	"encoding/gob"
-	"os"
+	"math/rand"
 	"testing"
 
 	"github.com/hectorgimenez/d2go/pkg/data"
 	"github.com/hectorgimenez/koolo/internal/game"
+	"github.com/hectorgimenez/koolo/internal/pather/gridtest"
 )
 
 func BenchmarkAstar(b *testing.B) {
-	grid := loadGrid()
+	grid := gridtest.DuranceOfHate(b)
 
 	start := data.Position{X: 336, Y: 701}
 	goal := data.Position{X: 11, Y: 330}
@@ -21,8 +21,30 @@ func BenchmarkAstar(b *testing.B) {
 	}
 }
 
+func BenchmarkHierarchy(b *testing.B) {
+	grid := gridtest.DuranceOfHate(b)
+	h := NewHierarchy(grid, false)
+
+	start := data.Position{X: 336, Y: 701}
+	goal := data.Position{X: 11, Y: 330}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		h.CalculatePath(grid, start, goal)
+	}
+}
+
+func BenchmarkNewHierarchy(b *testing.B) {
+	grid := gridtest.DuranceOfHate(b)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		NewHierarchy(grid, false)
+	}
+}
+
 func TestAstar(t *testing.T) {
-	grid := loadGrid()
+	grid := gridtest.DuranceOfHate(t)
 
 	start := data.Position{X: 336, Y: 701}
 	goal := data.Position{X: 11, Y: 330}
@@ -39,18 +61,43 @@ func TestAstar(t *testing.T) {
 	}
 }
 
-func loadGrid() *game.Grid {
-	var grid game.Grid
-	file, err := os.Open("durance_of_hate_grid.bin")
-	if err != nil {
-		panic(err)
-	}
-	defer file.Close()
+func TestHierarchy(t *testing.T) {
+	grid := gridtest.DuranceOfHate(t)
+	h := NewHierarchy(grid, false)
 
-	decoder := gob.NewDecoder(file)
-	if err := decoder.Decode(&grid); err != nil {
-		panic(err)
+	// A* results are the oracle, hierarchical paths can be a bit longer but never missing
+	pairs := [][2]data.Position{
+		{{X: 336, Y: 701}, {X: 11, Y: 330}},
+		{{X: 11, Y: 330}, {X: 336, Y: 701}},
+	}
+	rnd := rand.New(rand.NewSource(1))
+	for len(pairs) < 40 {
+		from := data.Position{X: rnd.Intn(grid.Width), Y: rnd.Intn(grid.Height)}
+		to := data.Position{X: rnd.Intn(grid.Width), Y: rnd.Intn(grid.Height)}
+		if !isBlocked(grid, from.X, from.Y, false) && !isBlocked(grid, to.X, to.Y, false) {
+			pairs = append(pairs, [2]data.Position{from, to})
+		}
 	}
 
-	return &grid
+	for _, pair := range pairs {
+		expected, expectedDist, expectedFound := CalculatePath(grid, pair[0], pair[1], false)
+		p, dist, found := h.CalculatePath(grid, pair[0], pair[1])
+		if found != expectedFound {
+			t.Fatalf("%v -> %v: expected found to be %t, got %t", pair[0], pair[1], expectedFound, found)
+		}
+		if !found {
+			continue
+		}
+		if float64(dist) > float64(expectedDist)*1.25 {
+			t.Errorf("%v -> %v: expected distance close to %d, got %d", pair[0], pair[1], expectedDist, dist)
+		}
+		if p[0] != pair[0] || p[len(p)-1] != pair[1] || p[len(p)-1] != expected[len(expected)-1] {
+			t.Errorf("%v -> %v: path doesn't join start and goal", pair[0], pair[1])
+		}
+		for i := 1; i < len(p); i++ {
+			if max(abs(p[i].X-p[i-1].X), abs(p[i].Y-p[i-1].Y)) != 1 || grid.CollisionGrid[p[i].Y][p[i].X] == game.CollisionTypeNonWalkable {
+				t.Fatalf("%v -> %v: invalid step from %v to %v", pair[0], pair[1], p[i-1], p[i])
+			}
+		}
+	}
 }
@@ -0,0 +1,52 @@
+package astar
+
+import (
+	"math"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/koolo/internal/game"
+)
+
+// Tiles explored around the start and the targets of a flood, paths going farther than this are not found
+const floodMargin = 20
+
+// FloodCosts returns the cost of the cheapest path from start to every target, -1 for the ones without path. It's a
+// single Dijkstra flood limited to the clusters around the positions, so it's meant for targets close to each other.
+func FloodCosts(g *game.Grid, start data.Position, targets []data.Position, canTeleport bool) []int {
+	s := searchers.Get().(*searcher)
+	defer searchers.Put(s)
+
+	costs := make([]int, len(targets))
+	s.search(g, start, data.Position{}, false, canTeleport, floodMask(g, start, targets))
+	for i, t := range targets {
+		costs[i] = -1
+		if t.X < 0 || t.X >= g.Width || t.Y < 0 || t.Y >= g.Height {
+			continue
+		}
+		if c := s.costAt(t.Y*g.Width + t.X); c != math.MaxInt32 {
+			costs[i] = int(c)
+		}
+	}
+
+	return costs
+}
+
+func floodMask(g *game.Grid, start data.Position, targets []data.Position) *clusterMask {
+	minX, minY, maxX, maxY := start.X, start.Y, start.X, start.Y
+	for _, t := range targets {
+		minX, minY = min(minX, t.X), min(minY, t.Y)
+		maxX, maxY = max(maxX, t.X), max(maxY, t.Y)
+	}
+
+	cols := (g.Width + ClusterSize - 1) / ClusterSize
+	rows := (g.Height + ClusterSize - 1) / ClusterSize
+	mask := &clusterMask{cols: cols, allowed: make([]bool, cols*rows)}
+	clamp := func(v, size int) int { return min(max(v, 0), size-1) / ClusterSize }
+	for cy := clamp(minY-floodMargin, g.Height); cy <= clamp(maxY+floodMargin, g.Height); cy++ {
+		for cx := clamp(minX-floodMargin, g.Width); cx <= clamp(maxX+floodMargin, g.Width); cx++ {
+			mask.allowed[cy*cols+cx] = true
+		}
+	}
+
+	return mask
+}
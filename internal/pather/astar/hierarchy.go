@@ -0,0 +1,328 @@
+package astar
+
+import (
+	"math"
+	"slices"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/koolo/internal/game"
+)
+
+// ClusterSize is the side, in cells, of the clusters of a Hierarchy
+const ClusterSize = 32
+
+// Entrances followed by every search refining an abstract path, longer windows give better paths but explore more
+const refineWindow = 8
+
+// Hierarchy is a precomputed abstraction of a grid for long paths (HPA*). The grid is split in clusters, the
+// entrances between neighbor clusters are the nodes of a small graph and the costs between the entrances of the same
+// cluster are calculated once. Paths are searched first on the graph, then A* follows it a few entrances at a time.
+// It's safe for concurrent use once built.
+type Hierarchy struct {
+	width, height int
+	cols, rows    int
+	canTeleport   bool
+	nodes         []entrance
+	edges         [][]edge
+	byCluster     [][]int32 // Nodes of every cluster
+}
+
+type entrance struct {
+	x, y    int
+	cluster int
+}
+
+type edge struct {
+	to   int32
+	cost int32
+}
+
+// clusterMask limits a search to some clusters, nil allows the whole grid
+type clusterMask struct {
+	cols    int
+	allowed []bool
+}
+
+func (m *clusterMask) contains(x, y int) bool {
+	return m == nil || m.allowed[(y/ClusterSize)*m.cols+x/ClusterSize]
+}
+
+// NewHierarchy builds the hierarchy of a grid, it takes a while on big grids so it's meant to be built once per map
+func NewHierarchy(g *game.Grid, canTeleport bool) *Hierarchy {
+	h := &Hierarchy{
+		width:       g.Width,
+		height:      g.Height,
+		cols:        (g.Width + ClusterSize - 1) / ClusterSize,
+		rows:        (g.Height + ClusterSize - 1) / ClusterSize,
+		canTeleport: canTeleport,
+	}
+	h.byCluster = make([][]int32, h.cols*h.rows)
+
+	ids := make(map[int]int32)
+	addNode := func(x, y int) int32 {
+		if id, found := ids[y*g.Width+x]; found {
+			return id
+		}
+		id := int32(len(h.nodes))
+		c := h.clusterOf(x, y)
+		h.nodes = append(h.nodes, entrance{x: x, y: y, cluster: c})
+		h.edges = append(h.edges, nil)
+		h.byCluster[c] = append(h.byCluster[c], id)
+		ids[y*g.Width+x] = id
+		return id
+	}
+	link := func(ax, ay, bx, by int) {
+		a, b := addNode(ax, ay), addNode(bx, by)
+		h.edges[a] = append(h.edges[a], edge{to: b, cost: int32(getCost(g.CollisionGrid[by][bx], canTeleport))})
+		h.edges[b] = append(h.edges[b], edge{to: a, cost: int32(getCost(g.CollisionGrid[ay][ax], canTeleport))})
+	}
+	open := func(x, y int) bool { return !isBlocked(g, x, y, canTeleport) }
+
+	// Borders between a cluster and the one on its right
+	for cx := 1; cx < h.cols; cx++ {
+		x := cx * ClusterSize
+		for cy := 0; cy < h.rows; cy++ {
+			openRuns(cy*ClusterSize, min((cy+1)*ClusterSize, g.Height),
+				func(y int) bool { return open(x-1, y) && open(x, y) },
+				func(y int) { link(x-1, y, x, y) })
+		}
+	}
+	// Borders between a cluster and the one below
+	for cy := 1; cy < h.rows; cy++ {
+		y := cy * ClusterSize
+		for cx := 0; cx < h.cols; cx++ {
+			openRuns(cx*ClusterSize, min((cx+1)*ClusterSize, g.Width),
+				func(x int) bool { return open(x, y-1) && open(x, y) },
+				func(x int) { link(x, y-1, x, y) })
+		}
+	}
+
+	// Costs between the entrances of the same cluster. Paths can go through the neighbor clusters, limiting them to the
+	// cluster makes some of them way longer than they are.
+	s := searchers.Get().(*searcher)
+	defer searchers.Put(s)
+	for c, clusterNodes := range h.byCluster {
+		mask := h.around(c)
+		for _, a := range clusterNodes {
+			s.search(g, h.position(a), data.Position{}, false, canTeleport, mask)
+			for _, b := range clusterNodes {
+				if cost := s.costAt(h.index(b)); a != b && cost != math.MaxInt32 {
+					h.edges[a] = append(h.edges[a], edge{to: b, cost: cost})
+				}
+			}
+		}
+	}
+
+	return h
+}
+
+// CalculatePath has the same behavior as the package CalculatePath, using the hierarchy to limit the cells explored.
+// g has to be the grid the hierarchy was built from or a copy with some changes (monsters, objects...). Paths are close
+// to the optimal one but not always the same, it falls back to a full search when the abstract path can't be followed.
+func (h *Hierarchy) CalculatePath(g *game.Grid, start, goal data.Position) ([]data.Position, int, bool) {
+	return h.CalculatePathWithLayer(g, start, goal, nil)
+}
+
+// CalculatePathWithLayer is CalculatePath adding the costs of layer to the tiles. The abstract path doesn't know about
+// the layer, only the real path searches between the entrances do.
+func (h *Hierarchy) CalculatePathWithLayer(g *game.Grid, start, goal data.Position, layer CostLayer) ([]data.Position, int, bool) {
+	s := searchers.Get().(*searcher)
+	defer searchers.Put(s)
+
+	s.layer = layer
+	defer func() { s.layer = nil }()
+
+	if entrances := h.abstractPath(s, g, start, goal); entrances != nil {
+		if path, found := h.refine(s, g, start, goal, entrances); found {
+			return path, len(path), true
+		}
+	}
+
+	return s.path(g, start, goal, h.canTeleport, nil)
+}
+
+// refine searches the real path following the entrances, refineWindow entrances at a time. Every search is limited to
+// the clusters of its entrances and their neighbors.
+func (h *Hierarchy) refine(s *searcher, g *game.Grid, start, goal data.Position, entrances []int32) ([]data.Position, bool) {
+	waypoints := make([]data.Position, 0, len(entrances)+2)
+	waypoints = append(waypoints, start)
+	for _, id := range entrances {
+		waypoints = append(waypoints, h.position(id))
+	}
+	waypoints = append(waypoints, goal)
+
+	path := []data.Position{start}
+	for i := 0; i < len(waypoints)-1; {
+		j := min(i+refineWindow, len(waypoints)-1)
+		mask := h.newMask()
+		for _, p := range waypoints[i : j+1] {
+			h.addAround(mask, h.clusterOf(p.X, p.Y))
+		}
+
+		segment, _, found := s.path(g, waypoints[i], waypoints[j], h.canTeleport, mask)
+		if !found {
+			return nil, false
+		}
+		// Segment starts where the previous one ended
+		path = append(path, segment[1:]...)
+		i = j
+	}
+
+	return path, true
+}
+
+// abstractPath returns the entrances a path from start to goal goes through, nil when a full search is the better choice
+func (h *Hierarchy) abstractPath(s *searcher, g *game.Grid, start, goal data.Position) []int32 {
+	if g.Width != h.width || g.Height != h.height || !h.inside(start) || !h.inside(goal) {
+		return nil
+	}
+	startCluster, goalCluster := h.clusterOf(start.X, start.Y), h.clusterOf(goal.X, goal.Y)
+	// Close enough, a plain search is already cheap
+	if abs(startCluster%h.cols-goalCluster%h.cols) <= 1 && abs(startCluster/h.cols-goalCluster/h.cols) <= 1 {
+		return nil
+	}
+
+	startEdges := h.connect(s, g, start, startCluster)
+	goalEdges := h.connect(s, g, goal, goalCluster)
+	if len(startEdges) == 0 || len(goalEdges) == 0 {
+		return nil
+	}
+	// Cost from the goal is used as cost to the goal, it's only an estimation to pick the entrances
+	toGoal := make(map[int32]int32, len(goalEdges))
+	for _, e := range goalEdges {
+		toGoal[e.to] = e.cost
+	}
+
+	// A* over the entrances, with two extra nodes for start and goal
+	startID, goalID := int32(len(h.nodes)), int32(len(h.nodes)+1)
+	costs := make([]int32, len(h.nodes)+2)
+	parents := make([]int32, len(h.nodes)+2)
+	for i := range costs {
+		costs[i] = math.MaxInt32
+	}
+	costs[startID] = 0
+	var open openSet
+	open.push(node{x: startID})
+
+	relax := func(from int32, e edge) {
+		newCost := costs[from] + e.cost
+		if newCost >= costs[e.to] {
+			return
+		}
+		costs[e.to] = newCost
+		parents[e.to] = from
+		priority := newCost
+		if e.to != goalID {
+			n := h.nodes[e.to]
+			priority += int32(max(abs(n.x-goal.X), abs(n.y-goal.Y)))
+		}
+		open.push(node{x: e.to, priority: priority})
+	}
+
+	for len(open) > 0 {
+		current := open.pop().x
+		if current == goalID {
+			var entrances []int32
+			for n := parents[goalID]; n != startID; n = parents[n] {
+				entrances = append(entrances, n)
+			}
+			slices.Reverse(entrances)
+			return entrances
+		}
+		if current == startID {
+			for _, e := range startEdges {
+				relax(current, e)
+			}
+			continue
+		}
+		for _, e := range h.edges[current] {
+			relax(current, e)
+		}
+		if cost, isGoalEntrance := toGoal[current]; isGoalEntrance {
+			relax(current, edge{to: goalID, cost: cost})
+		}
+	}
+
+	return nil
+}
+
+// connect returns the costs from p to the entrances of its cluster and the neighbor ones
+func (h *Hierarchy) connect(s *searcher, g *game.Grid, p data.Position, cluster int) []edge {
+	mask := h.around(cluster)
+	s.search(g, p, data.Position{}, false, h.canTeleport, mask)
+
+	var edges []edge
+	for c, allowed := range mask.allowed {
+		if !allowed {
+			continue
+		}
+		for _, id := range h.byCluster[c] {
+			if cost := s.costAt(h.index(id)); cost != math.MaxInt32 {
+				edges = append(edges, edge{to: id, cost: cost})
+			}
+		}
+	}
+
+	return edges
+}
+
+func (h *Hierarchy) newMask() *clusterMask {
+	return &clusterMask{cols: h.cols, allowed: make([]bool, h.cols*h.rows)}
+}
+
+// around returns a mask with the cluster and its neighbors
+func (h *Hierarchy) around(cluster int) *clusterMask {
+	mask := h.newMask()
+	h.addAround(mask, cluster)
+
+	return mask
+}
+
+func (h *Hierarchy) addAround(mask *clusterMask, cluster int) {
+	cx, cy := cluster%h.cols, cluster/h.cols
+	for y := max(cy-1, 0); y <= min(cy+1, h.rows-1); y++ {
+		for x := max(cx-1, 0); x <= min(cx+1, h.cols-1); x++ {
+			mask.allowed[y*h.cols+x] = true
+		}
+	}
+}
+
+func (h *Hierarchy) clusterOf(x, y int) int {
+	return (y/ClusterSize)*h.cols + x/ClusterSize
+}
+
+func (h *Hierarchy) inside(p data.Position) bool {
+	return p.X >= 0 && p.X < h.width && p.Y >= 0 && p.Y < h.height
+}
+
+func (h *Hierarchy) position(id int32) data.Position {
+	return data.Position{X: h.nodes[id].x, Y: h.nodes[id].y}
+}
+
+func (h *Hierarchy) index(id int32) int {
+	return h.nodes[id].y*h.width + h.nodes[id].x
+}
+
+// openRuns calls place for the entrances of the open cells between from and to
+func openRuns(from, to int, open func(int) bool, place func(int)) {
+	for i := from; i < to; {
+		if !open(i) {
+			i++
+			continue
+		}
+		j := i
+		for j < to && open(j) {
+			j++
+		}
+		// Ends of the entrance are usually close to walls, which are expensive low priority tiles
+		place((i + j - 1) / 2)
+		i = j
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -1,25 +1,56 @@
 package astar
 
-type PriorityQueue []*Node
-
-func (pq PriorityQueue) Len() int           { return len(pq) }
-func (pq PriorityQueue) Less(i, j int) bool { return pq[i].Priority < pq[j].Priority }
-func (pq PriorityQueue) Swap(i, j int) {
-	pq[i], pq[j] = pq[j], pq[i]
-	pq[i].Index = i
-	pq[j].Index = j
+type node struct {
+	x, y     int32
+	priority int32
+	tpStreak int8
 }
-func (pq *PriorityQueue) Push(x interface{}) {
-	n := x.(*Node)
-	n.Index = len(*pq)
+
+// openSet is a binary min heap of nodes by priority. Nodes are stored by value and it follows the same sift order as
+// container/heap, so paths don't change compared with the original implementation.
+type openSet []node
+
+func (pq *openSet) push(n node) {
 	*pq = append(*pq, n)
+	pq.up(len(*pq) - 1)
 }
-func (pq *PriorityQueue) Pop() interface{} {
+
+func (pq *openSet) pop() node {
 	old := *pq
-	n := len(old)
-	node := old[n-1]
-	old[n-1] = nil
-	node.Index = -1
-	*pq = old[0 : n-1]
-	return node
+	last := len(old) - 1
+	old[0], old[last] = old[last], old[0]
+	pq.down(0, last)
+	n := old[last]
+	*pq = old[:last]
+
+	return n
+}
+
+func (pq openSet) up(j int) {
+	for {
+		i := (j - 1) / 2 // parent
+		if i == j || pq[j].priority >= pq[i].priority {
+			break
+		}
+		pq[i], pq[j] = pq[j], pq[i]
+		j = i
+	}
+}
+
+func (pq openSet) down(i, n int) {
+	for {
+		j1 := 2*i + 1
+		if j1 >= n || j1 < 0 {
+			break
+		}
+		j := j1 // left child
+		if j2 := j1 + 1; j2 < n && pq[j2].priority < pq[j1].priority {
+			j = j2 // right child
+		}
+		if pq[j].priority >= pq[i].priority {
+			break
+		}
+		pq[i], pq[j] = pq[j], pq[i]
+		i = j
+	}
 }
@@ -0,0 +1,80 @@
+package pather
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+	"github.com/hectorgimenez/koolo/internal/game"
+)
+
+// Tiles blocked by the movement recovery are usually a stuck monster or some small object the map data doesn't have,
+// they expire so they don't close the way forever
+const blockedTileTTL = 30 * time.Second
+
+// blockedTiles are positions the pathing avoids for a while, they are dropped on area change and on new games (areas
+// keep their ID but not their layout)
+type blockedTiles struct {
+	mu    sync.Mutex
+	area  area.ID
+	tiles map[data.Position]time.Time
+}
+
+// BlockTile makes the paths avoid the position and the tiles around it for a while, it's used when the player can't
+// get through somewhere the collision grid says it's walkable
+func (pf *PathFinder) BlockTile(position data.Position, radius int) {
+	bt := pf.blocked
+	bt.mu.Lock()
+	defer bt.mu.Unlock()
+
+	if bt.area != pf.data.PlayerUnit.Area || bt.tiles == nil {
+		bt.area = pf.data.PlayerUnit.Area
+		bt.tiles = make(map[data.Position]time.Time)
+	}
+
+	expiresAt := time.Now().Add(blockedTileTTL)
+	for dy := -radius; dy <= radius; dy++ {
+		for dx := -radius; dx <= radius; dx++ {
+			bt.tiles[data.Position{X: position.X + dx, Y: position.Y + dy}] = expiresAt
+		}
+	}
+}
+
+func (bt *blockedTiles) reset() {
+	bt.mu.Lock()
+	defer bt.mu.Unlock()
+
+	bt.tiles = nil
+}
+
+// apply marks the blocked tiles of the area as non walkable, except from and to (relative positions). Returns true if
+// any tile was marked. Tiles of other areas are dropped, the player left the area they were blocked in.
+func (bt *blockedTiles) apply(grid *game.Grid, a area.ID, from, to data.Position) bool {
+	bt.mu.Lock()
+	defer bt.mu.Unlock()
+
+	if bt.area != a {
+		bt.tiles = nil
+	}
+	if len(bt.tiles) == 0 {
+		return false
+	}
+
+	now := time.Now()
+	applied := false
+	for p, expiresAt := range bt.tiles {
+		if now.After(expiresAt) {
+			delete(bt.tiles, p)
+			continue
+		}
+		rel := grid.RelativePosition(p)
+		if rel == from || rel == to || rel.X < 0 || rel.Y < 0 || rel.X >= grid.Width || rel.Y >= grid.Height {
+			continue
+		}
+		grid.CollisionGrid[rel.Y][rel.X] = game.CollisionTypeNonWalkable
+		applied = true
+	}
+
+	return applied
+}
@@ -0,0 +1,213 @@
+package danger
+
+import (
+	"slices"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/skill"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
+	"github.com/hectorgimenez/d2go/pkg/data/state"
+)
+
+// Offensive auras, monsters holding them (or enchanted with them) hit way harder than the rest of the pack
+var auras = []state.State{
+	state.Might,
+	state.Holyfire,
+	state.Holywindcold,
+	state.Holyshock,
+	state.Conviction,
+	state.Fanaticism,
+	state.Concentration,
+	state.Blessedaim,
+	state.Thorns,
+}
+
+// profile is how much and how far a monster increases the cost of the tiles around it
+type profile struct {
+	radius int
+	weight int
+}
+
+var profiles = map[data.MonsterType]profile{
+	data.MonsterTypeNone:        {radius: 3, weight: 6},
+	data.MonsterTypeMinion:      {radius: 5, weight: 10},
+	data.MonsterTypeChampion:    {radius: 5, weight: 12},
+	data.MonsterTypeUnique:      {radius: 7, weight: 16},
+	data.MonsterTypeSuperUnique: {radius: 8, weight: 20},
+}
+
+// Safe paths keep this many tiles farther from every monster, and tiles cost twice as much
+const safeExtraRadius = 3
+
+// Elements of the attack skills, physical ones are left out since there is no way to tell physical immunes apart
+var skillElements = map[skill.ID]stat.Resist{
+	skill.FireArrow:         stat.FireImmune,
+	skill.ImmolationArrow:   stat.FireImmune,
+	skill.ExplodingArrow:    stat.FireImmune,
+	skill.ColdArrow:         stat.ColdImmune,
+	skill.IceArrow:          stat.ColdImmune,
+	skill.FreezingArrow:     stat.ColdImmune,
+	skill.ChargedStrike:     stat.LightImmune,
+	skill.LightningFury:     stat.LightImmune,
+	skill.PoisonJavelin:     stat.PoisonImmune,
+	skill.PlagueJavelin:     stat.PoisonImmune,
+	skill.FireBolt:          stat.FireImmune,
+	skill.FireBall:          stat.FireImmune,
+	skill.FireWall:          stat.FireImmune,
+	skill.Meteor:            stat.FireImmune,
+	skill.Hydra:             stat.FireImmune,
+	skill.IceBolt:           stat.ColdImmune,
+	skill.IceBlast:          stat.ColdImmune,
+	skill.GlacialSpike:      stat.ColdImmune,
+	skill.Blizzard:          stat.ColdImmune,
+	skill.FrozenOrb:         stat.ColdImmune,
+	skill.ChargedBolt:       stat.LightImmune,
+	skill.Nova:              stat.LightImmune,
+	skill.Lightning:         stat.LightImmune,
+	skill.ChainLightning:    stat.LightImmune,
+	skill.Teeth:             stat.MagicImmune,
+	skill.BoneSpear:         stat.MagicImmune,
+	skill.BoneSpirit:        stat.MagicImmune,
+	skill.PoisonNova:        stat.PoisonImmune,
+	skill.HolyBolt:          stat.MagicImmune,
+	skill.BlessedHammer:     stat.MagicImmune,
+	skill.FistOfTheHeavens:  stat.LightImmune,
+	skill.Berserk:           stat.MagicImmune,
+	skill.Firestorm:         stat.FireImmune,
+	skill.MoltenBoulder:     stat.FireImmune,
+	skill.Volcano:           stat.FireImmune,
+	skill.Armageddon:        stat.FireImmune,
+	skill.ArcticBlast:       stat.ColdImmune,
+	skill.Hurricane:         stat.ColdImmune,
+	skill.FireBlast:         stat.FireImmune,
+	skill.WakeOfInferno:     stat.FireImmune,
+	skill.ChargedBoltSentry: stat.LightImmune,
+	skill.LightningSentry:   stat.LightImmune,
+}
+
+// Attack skills need this level to count as one of the character main attacks, lower ones are usually prerequisites
+const mainAttackLevel = 10
+
+// Options tune the layer for a character
+type Options struct {
+	// Elements of the character attack skills, monsters immune to all of them can't be killed fast and get double weight
+	Immunities []stat.Resist
+	// Safe keeps paths farther from the monsters, for walking characters that can't take hits from a whole pack
+	Safe bool
+}
+
+// AttackImmunities returns the elements of the main attack skills in skills, as expected by Options.Immunities
+func AttackImmunities(skills map[skill.ID]skill.Points) []stat.Resist {
+	var immunities []stat.Resist
+	for id, points := range skills {
+		resist, found := skillElements[id]
+		if found && points.Level >= mainAttackLevel && !slices.Contains(immunities, resist) {
+			immunities = append(immunities, resist)
+		}
+	}
+	slices.Sort(immunities)
+
+	return immunities
+}
+
+// Layer is the danger of every tile around the monsters, it's used as extra cost for the path finding. Positions are
+// relative to the grid the layer is built for.
+type Layer struct {
+	minX, minY    int
+	width, height int
+	cost          []int32
+}
+
+// New builds the danger layer for the enemies in monsters, offsetX and offsetY are the offsets of the grid
+func New(monsters data.Monsters, offsetX, offsetY int, opts Options) *Layer {
+	enemies := monsters.Enemies()
+	if len(enemies) == 0 {
+		return &Layer{}
+	}
+
+	type source struct {
+		x, y int
+		profile
+	}
+	sources := make([]source, 0, len(enemies))
+	l := &Layer{}
+	maxX, maxY := 0, 0
+	for i, m := range enemies {
+		s := source{x: m.Position.X - offsetX, y: m.Position.Y - offsetY, profile: monsterProfile(m, opts)}
+		sources = append(sources, s)
+
+		if i == 0 || s.x-s.radius < l.minX {
+			l.minX = s.x - s.radius
+		}
+		if i == 0 || s.y-s.radius < l.minY {
+			l.minY = s.y - s.radius
+		}
+		maxX, maxY = max(maxX, s.x+s.radius), max(maxY, s.y+s.radius)
+	}
+	l.width, l.height = maxX-l.minX+1, maxY-l.minY+1
+	l.cost = make([]int32, l.width*l.height)
+
+	// Cost goes down linearly from the monster position to the edge of its radius, packs add up
+	for _, s := range sources {
+		for dy := -s.radius; dy <= s.radius; dy++ {
+			for dx := -s.radius; dx <= s.radius; dx++ {
+				d := max(abs(dx), abs(dy))
+				l.cost[(s.y+dy-l.minY)*l.width+s.x+dx-l.minX] += int32(s.weight * (s.radius + 1 - d) / (s.radius + 1))
+			}
+		}
+	}
+
+	return l
+}
+
+// ExtraCost implements astar.CostLayer
+func (l *Layer) ExtraCost(x, y int) int {
+	x, y = x-l.minX, y-l.minY
+	if x < 0 || y < 0 || x >= l.width || y >= l.height {
+		return 0
+	}
+
+	return int(l.cost[y*l.width+x])
+}
+
+func monsterProfile(m data.Monster, opts Options) profile {
+	p, found := profiles[m.Type]
+	if !found {
+		p = profiles[data.MonsterTypeNone]
+	}
+
+	for _, aura := range auras {
+		if m.States.HasState(aura) {
+			p.radius += 4
+			p.weight += 8
+			break
+		}
+	}
+
+	if len(opts.Immunities) > 0 {
+		immune := true
+		for _, resist := range opts.Immunities {
+			if !m.IsImmune(resist) {
+				immune = false
+				break
+			}
+		}
+		if immune {
+			p.weight *= 2
+		}
+	}
+
+	if opts.Safe {
+		p.radius += safeExtraRadius
+		p.weight *= 2
+	}
+
+	return p
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,133 @@
+package danger
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/skill"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
+	"github.com/hectorgimenez/d2go/pkg/data/state"
+	"github.com/hectorgimenez/koolo/internal/game"
+	"github.com/hectorgimenez/koolo/internal/pather/astar"
+)
+
+func monster(x, y int, t data.MonsterType) data.Monster {
+	return data.Monster{
+		UnitID:   data.UnitID(x*1000 + y),
+		Position: data.Position{X: x, Y: y},
+		Type:     t,
+		Stats:    map[stat.ID]int{stat.Life: 100},
+	}
+}
+
+func TestLayer(t *testing.T) {
+	unique := monster(120, 220, data.MonsterTypeUnique)
+	aura := monster(150, 250, data.MonsterTypeNone)
+	aura.States = state.States{state.Fanaticism}
+	dead := monster(170, 270, data.MonsterTypeSuperUnique)
+	dead.Stats[stat.Life] = 0
+
+	l := New(data.Monsters{unique, aura, dead}, 100, 200, Options{})
+
+	if cost := l.ExtraCost(20, 20); cost != profiles[data.MonsterTypeUnique].weight {
+		t.Errorf("Expected full weight on the monster tile, got %d", cost)
+	}
+	if far, near := l.ExtraCost(26, 20), l.ExtraCost(21, 20); far >= near || far <= 0 {
+		t.Errorf("Expected cost to go down with distance, got %d near and %d far", near, far)
+	}
+	if cost := l.ExtraCost(28, 20); cost != 0 {
+		t.Errorf("Expected no cost outside the radius, got %d", cost)
+	}
+	// Aura holders reach farther than normal monsters
+	if cost := l.ExtraCost(50+profiles[data.MonsterTypeNone].radius+2, 50); cost == 0 {
+		t.Error("Expected aura holder to have a bigger radius")
+	}
+	if cost := l.ExtraCost(70, 70); cost != 0 {
+		t.Errorf("Expected dead monsters to be ignored, got %d", cost)
+	}
+}
+
+func TestImmuneMonstersWeightMore(t *testing.T) {
+	immune := monster(10, 10, data.MonsterTypeChampion)
+	immune.Stats[stat.ColdResist] = 100
+
+	normal := New(data.Monsters{immune}, 0, 0, Options{Immunities: []stat.Resist{stat.FireImmune}})
+	doubled := New(data.Monsters{immune}, 0, 0, Options{Immunities: []stat.Resist{stat.ColdImmune}})
+	if normal.ExtraCost(10, 10)*2 != doubled.ExtraCost(10, 10) {
+		t.Errorf("Expected immune monster to have double weight, got %d and %d", normal.ExtraCost(10, 10), doubled.ExtraCost(10, 10))
+	}
+}
+
+func TestSafeLayerReachesFarther(t *testing.T) {
+	m := data.Monsters{monster(10, 10, data.MonsterTypeNone)}
+	normal := New(m, 0, 0, Options{})
+	safe := New(m, 0, 0, Options{Safe: true})
+
+	if normal.ExtraCost(10, 10)*2 != safe.ExtraCost(10, 10) {
+		t.Errorf("Expected safe layer to have double weight, got %d and %d", normal.ExtraCost(10, 10), safe.ExtraCost(10, 10))
+	}
+	edge := 10 + profiles[data.MonsterTypeNone].radius + 1
+	if normal.ExtraCost(edge, 10) != 0 || safe.ExtraCost(edge, 10) == 0 {
+		t.Errorf("Expected only the safe layer to reach %d tiles, got %d and %d", edge-10, normal.ExtraCost(edge, 10), safe.ExtraCost(edge, 10))
+	}
+}
+
+func TestAttackImmunities(t *testing.T) {
+	tests := []struct {
+		name   string
+		skills map[skill.ID]skill.Points
+		want   []stat.Resist
+	}{
+		{name: "no skills"},
+		{
+			name:   "blizzard with prerequisites",
+			skills: map[skill.ID]skill.Points{skill.Blizzard: {Level: 20}, skill.IceBolt: {Level: 20}, skill.FireBolt: {Level: 1}, skill.Teleport: {Level: 1}},
+			want:   []stat.Resist{stat.ColdImmune},
+		},
+		{
+			name:   "hydra orb",
+			skills: map[skill.ID]skill.Points{skill.FrozenOrb: {Level: 20}, skill.Hydra: {Level: 20}},
+			want:   []stat.Resist{stat.ColdImmune, stat.FireImmune},
+		},
+		{
+			name:   "physical attacks",
+			skills: map[skill.ID]skill.Points{skill.Zeal: {Level: 20}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AttackImmunities(tt.skills); !slices.Equal(got, tt.want) {
+				t.Errorf("AttackImmunities = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSafePathGoesAroundPacks(t *testing.T) {
+	cg := make([][]game.CollisionType, 60)
+	for y := range cg {
+		cg[y] = make([]game.CollisionType, 60)
+		for x := range cg[y] {
+			cg[y][x] = game.CollisionTypeWalkable
+		}
+	}
+	g := &game.Grid{Width: 60, Height: 60, CollisionGrid: cg}
+	start, goal := data.Position{X: 5, Y: 30}, data.Position{X: 55, Y: 30}
+	pack := data.Monsters{
+		monster(30, 29, data.MonsterTypeUnique),
+		monster(30, 30, data.MonsterTypeMinion),
+		monster(30, 31, data.MonsterTypeMinion),
+	}
+
+	path, _, found := astar.CalculatePathWithLayer(g, start, goal, false, New(pack, 0, 0, Options{}))
+	if !found {
+		t.Fatal("Expected a path to be found")
+	}
+	for _, p := range path {
+		if max(abs(p.X-30), abs(p.Y-30)) < 5 {
+			t.Fatalf("Expected the path to keep away from the pack, it goes through %v", p)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package pather
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/koolo/internal/game"
+)
+
+const (
+	DebugMapPNG = "png"
+	DebugMapSVG = "svg"
+)
+
+var (
+	colorWalkable     = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	colorLowPriority  = color.RGBA{R: 200, G: 200, B: 200, A: 255}
+	colorTeleportOver = color.RGBA{R: 150, G: 190, B: 255, A: 255}
+	colorNonWalkable  = color.RGBA{A: 255}
+	colorMonster      = color.RGBA{R: 255, A: 255}
+	colorElite        = color.RGBA{R: 255, G: 140, A: 255}
+	colorObject       = color.RGBA{R: 160, G: 32, B: 240, A: 255}
+	colorRoom         = color.RGBA{R: 204, G: 204, A: 255}
+	colorPlayer       = color.RGBA{R: 158, A: 255}
+	colorPathTarget   = color.RGBA{B: 255, A: 255}
+)
+
+// RenderDebugMap draws the current level with the player, monsters, objects, the rooms visit order and the last paths
+// computed in the level (newest is the brightest one). Format is DebugMapPNG or DebugMapSVG.
+func (pf *PathFinder) RenderDebugMap(w io.Writer, format string, paths int) error {
+	grid := pf.data.AreaData.Grid
+	if grid == nil {
+		return errors.New("map data not loaded yet")
+	}
+
+	var history []PathRecord
+	for _, r := range pf.history.last(paths) {
+		if r.Area == pf.data.PlayerUnit.Area && r.Found {
+			history = append(history, r)
+		}
+	}
+
+	switch format {
+	case DebugMapPNG:
+		img := pf.debugMapBase(grid)
+		for i := len(history) - 1; i >= 0; i-- {
+			c := pathColor(i, len(history))
+			for _, p := range history[i].Path {
+				setRelative(img, grid, p, c)
+			}
+			drawSquare(img, grid, history[i].To, 1, colorPathTarget)
+		}
+		for i, r := range pf.history.roomOrder(pf.data.PlayerUnit.Area) {
+			// Brighter the sooner the room is visited
+			drawSquare(img, grid, r.GetCenter(), 1, color.RGBA{R: colorRoom.R, G: uint8(max(0, int(colorRoom.G)-i*2)), A: 255})
+		}
+		pf.drawUnits(img, grid)
+
+		return png.Encode(w, img)
+	case DebugMapSVG:
+		return pf.renderDebugMapSVG(w, grid, history)
+	}
+
+	return fmt.Errorf("unknown map format %q", format)
+}
+
+// debugMapBase draws the collision grid, objects and monsters are drawn from the live data instead
+func (pf *PathFinder) debugMapBase(grid *game.Grid) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, grid.Width, grid.Height))
+	for y := 0; y < grid.Height; y++ {
+		for x := 0; x < grid.Width; x++ {
+			c := colorNonWalkable
+			switch grid.CollisionGrid[y][x] {
+			case game.CollisionTypeWalkable, game.CollisionTypeMonster, game.CollisionTypeObject:
+				c = colorWalkable
+			case game.CollisionTypeLowPriority:
+				c = colorLowPriority
+			case game.CollisionTypeTeleportOver:
+				c = colorTeleportOver
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+
+	return img
+}
+
+func (pf *PathFinder) drawUnits(img *image.RGBA, grid *game.Grid) {
+	for _, o := range pf.data.Objects {
+		drawSquare(img, grid, o.Position, 1, colorObject)
+	}
+	for _, m := range pf.data.Monsters.Enemies() {
+		if m.IsElite() {
+			drawSquare(img, grid, m.Position, 2, colorElite)
+		} else {
+			drawSquare(img, grid, m.Position, 1, colorMonster)
+		}
+	}
+	drawSquare(img, grid, pf.data.PlayerUnit.Position, 2, colorPlayer)
+}
+
+func (pf *PathFinder) renderDebugMapSVG(w io.Writer, grid *game.Grid, history []PathRecord) error {
+	base := pf.debugMapBase(grid)
+	pf.drawUnits(base, grid)
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, base); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, grid.Width, grid.Height, grid.Width, grid.Height)
+	fmt.Fprintf(w, `<image width="%d" height="%d" style="image-rendering:pixelated" href="data:image/png;base64,%s"/>`, grid.Width, grid.Height, base64.StdEncoding.EncodeToString(buf.Bytes()))
+
+	for i := len(history) - 1; i >= 0; i-- {
+		r := history[i]
+		c := pathColor(i, len(history))
+		fmt.Fprintf(w, `<polyline fill="none" stroke="%s" stroke-width="1" points="`, hexColor(c))
+		for _, p := range r.Path {
+			rel := grid.RelativePosition(p)
+			fmt.Fprintf(w, "%d,%d ", rel.X, rel.Y)
+		}
+		fmt.Fprintf(w, `"><title>%s -> %v (%d tiles)</title></polyline>`, r.At.Format("15:04:05.000"), r.To, len(r.Path))
+	}
+
+	for i, r := range pf.history.roomOrder(pf.data.PlayerUnit.Area) {
+		c := grid.RelativePosition(r.GetCenter())
+		fmt.Fprintf(w, `<text x="%d" y="%d" font-size="8" fill="%s" stroke="black" stroke-width="0.3">%d</text>`, c.X, c.Y, hexColor(colorRoom), i+1)
+	}
+
+	_, err := io.WriteString(w, "</svg>")
+	return err
+}
+
+// pathColor fades the old paths, i is 0 for the newest one
+func pathColor(i, total int) color.RGBA {
+	if total <= 1 {
+		return color.RGBA{R: 36, G: 255, A: 255}
+	}
+	fade := uint8(155 * i / (total - 1))
+	return color.RGBA{R: 36, G: 255 - fade, B: fade / 2, A: 255}
+}
+
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+func setRelative(img *image.RGBA, grid *game.Grid, p data.Position, c color.RGBA) {
+	rel := grid.RelativePosition(p)
+	if image.Pt(rel.X, rel.Y).In(img.Bounds()) {
+		img.SetRGBA(rel.X, rel.Y, c)
+	}
+}
+
+func drawSquare(img *image.RGBA, grid *game.Grid, p data.Position, radius int, c color.RGBA) {
+	for dy := -radius; dy <= radius; dy++ {
+		for dx := -radius; dx <= radius; dx++ {
+			setRelative(img, grid, data.Position{X: p.X + dx, Y: p.Y + dy}, c)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package explore
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/koolo/internal/game"
+)
+
+// Points farther than this from a walkable tile are unreachable
+const snapRadius = 10
+
+// Distances has the walking distances, in tiles, between points of a grid. It's expensive to build (a BFS for every
+// point) so it's meant to be built once per level and reused.
+type Distances struct {
+	canTeleport bool
+	points      []data.Position // Snapped to the closest walkable tile
+	dist        [][]int32       // -1 when there is no path
+}
+
+// passability of every tile of a grid, checked way faster than the collision types
+type passability struct {
+	width, height int
+	open          []bool
+	component     []int32 // Tiles with the same component are connected, 0 for the closed ones
+}
+
+func newPassability(g *game.Grid, canTeleport bool) passability {
+	p := passability{width: g.Width, height: g.Height, open: make([]bool, g.Width*g.Height)}
+	for y := 0; y < g.Height; y++ {
+		for x := 0; x < g.Width; x++ {
+			p.open[y*g.Width+x] = passable(g, x, y, canTeleport)
+		}
+	}
+
+	// Targets in other components are never reached, the BFS can stop without them
+	p.component = make([]int32, len(p.open))
+	var queue []int32
+	next := int32(0)
+	for i, open := range p.open {
+		if !open || p.component[i] != 0 {
+			continue
+		}
+		next++
+		p.component[i] = next
+		queue = append(queue[:0], int32(i))
+		for head := 0; head < len(queue); head++ {
+			p.neighbors(int(queue[head]), func(ni int) {
+				if p.component[ni] == 0 {
+					p.component[ni] = next
+					queue = append(queue, int32(ni))
+				}
+			})
+		}
+	}
+
+	return p
+}
+
+// NewDistances calculates the distances between points, positions are relative to the grid. Tiles that can be
+// teleported over are crossed when canTeleport is set.
+func NewDistances(g *game.Grid, points []data.Position, canTeleport bool) *Distances {
+	d := &Distances{
+		canTeleport: canTeleport,
+		points:      make([]data.Position, len(points)),
+		dist:        make([][]int32, len(points)),
+	}
+	for i, p := range points {
+		d.points[i] = snap(g, p, canTeleport)
+	}
+	grid := newPassability(g, canTeleport)
+	for i := range d.dist {
+		d.dist[i] = make([]int32, len(points))
+	}
+
+	// Every BFS is independent, spread them between the cores. Distances are symmetric, so every BFS only needs to
+	// reach the points after its own.
+	next := make(chan int)
+	wg := sync.WaitGroup{}
+	for w := 0; w < min(runtime.NumCPU(), len(points)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			f := &field{}
+			for i := range next {
+				f.fill(grid, d.points[i], d.points[i+1:])
+				for j := i + 1; j < len(d.points); j++ {
+					d.dist[i][j] = f.at(grid, d.points[j])
+					d.dist[j][i] = d.dist[i][j]
+				}
+			}
+		}()
+	}
+	for i := range points {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	return d
+}
+
+// Order returns the indexes of the points in the order they should be visited starting from start, walking the
+// minimum distance. Priority points are visited before the rest, points that can't be reached go last.
+func (d *Distances) Order(g *game.Grid, start data.Position, priority []bool) []int {
+	fromStart := d.fromStart(g, start)
+
+	var first, rest, unreachable []int
+	for i := range d.points {
+		switch {
+		case fromStart[i] < 0:
+			unreachable = append(unreachable, i)
+		case i < len(priority) && priority[i]:
+			first = append(first, i)
+		default:
+			rest = append(rest, i)
+		}
+	}
+
+	t := tour{from: fromStart, dist: d.dist}
+	order := t.solve(-1, first)
+	last := -1
+	if len(order) > 0 {
+		last = order[len(order)-1]
+	}
+	order = append(order, t.solve(last, rest)...)
+
+	return append(order, unreachable...)
+}
+
+// Length is the walking distance of visiting the points in order from start, unreachable points are skipped
+func (d *Distances) Length(g *game.Grid, start data.Position, order []int) int {
+	fromStart := d.fromStart(g, start)
+
+	length := 0
+	prev := -1
+	for _, i := range order {
+		var step int32
+		if prev < 0 {
+			step = fromStart[i]
+		} else {
+			step = d.dist[prev][i]
+		}
+		if step < 0 {
+			continue
+		}
+		length += int(step)
+		prev = i
+	}
+
+	return length
+}
+
+func (d *Distances) fromStart(g *game.Grid, start data.Position) []int32 {
+	grid := newPassability(g, d.canTeleport)
+	f := &field{}
+	f.fill(grid, snap(g, start, d.canTeleport), d.points)
+
+	distances := make([]int32, len(d.points))
+	for i, p := range d.points {
+		distances[i] = f.at(grid, p)
+	}
+
+	return distances
+}
+
+// snap returns the closest walkable tile to p, p itself when there is none close enough
+func snap(g *game.Grid, p data.Position, canTeleport bool) data.Position {
+	if passable(g, p.X, p.Y, canTeleport) {
+		return p
+	}
+	for radius := 1; radius <= snapRadius; radius++ {
+		for dy := -radius; dy <= radius; dy++ {
+			for dx := -radius; dx <= radius; dx++ {
+				if max(abs(dx), abs(dy)) == radius && passable(g, p.X+dx, p.Y+dy, canTeleport) {
+					return data.Position{X: p.X + dx, Y: p.Y + dy}
+				}
+			}
+		}
+	}
+
+	return p
+}
+
+func passable(g *game.Grid, x, y int, canTeleport bool) bool {
+	if x < 0 || y < 0 || x >= g.Width || y >= g.Height {
+		return false
+	}
+	switch g.CollisionGrid[y][x] {
+	case game.CollisionTypeNonWalkable:
+		return false
+	case game.CollisionTypeTeleportOver:
+		return canTeleport
+	}
+
+	return true
+}
+
+// field is a BFS distance field, the buffers are reused between fills
+type field struct {
+	dist    []int32
+	queue   []int32
+	targets []bool
+}
+
+// fill calculates the distances from p, it stops as soon as all the targets are reached
+func (f *field) fill(grid passability, p data.Position, targets []data.Position) {
+	size := grid.width * grid.height
+	if cap(f.dist) < size {
+		f.dist = make([]int32, size)
+		f.targets = make([]bool, size)
+	}
+	f.dist, f.targets = f.dist[:size], f.targets[:size]
+	for i := range f.dist {
+		f.dist[i] = -1
+	}
+	start := p.Y*grid.width + p.X
+	if !grid.inside(p) || !grid.open[start] {
+		return
+	}
+
+	pending := 0
+	for _, t := range targets {
+		if ti := t.Y*grid.width + t.X; grid.inside(t) && grid.component[ti] == grid.component[start] && !f.targets[ti] {
+			f.targets[ti] = true
+			pending++
+		}
+	}
+	defer func() {
+		for _, t := range targets {
+			if grid.inside(t) {
+				f.targets[t.Y*grid.width+t.X] = false
+			}
+		}
+	}()
+
+	f.queue = append(f.queue[:0], int32(start))
+	f.dist[start] = 0
+	for head := 0; head < len(f.queue) && pending > 0; head++ {
+		i := int(f.queue[head])
+		if f.targets[i] {
+			pending--
+		}
+		grid.neighbors(i, func(ni int) {
+			if f.dist[ni] < 0 {
+				f.dist[ni] = f.dist[i] + 1
+				f.queue = append(f.queue, int32(ni))
+			}
+		})
+	}
+}
+
+func (f *field) at(grid passability, p data.Position) int32 {
+	if !grid.inside(p) {
+		return -1
+	}
+
+	return f.dist[p.Y*grid.width+p.X]
+}
+
+// neighbors calls fn for the open tiles around the tile i
+func (p passability) neighbors(i int, fn func(int)) {
+	x, y := i%p.width, i/p.width
+	for ny := max(y-1, 0); ny <= min(y+1, p.height-1); ny++ {
+		for nx := max(x-1, 0); nx <= min(x+1, p.width-1); nx++ {
+			if ni := ny*p.width + nx; ni != i && p.open[ni] {
+				fn(ni)
+			}
+		}
+	}
+}
+
+func (p passability) inside(pos data.Position) bool {
+	return pos.X >= 0 && pos.Y >= 0 && pos.X < p.width && pos.Y < p.height
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
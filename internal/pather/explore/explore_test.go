@@ -0,0 +1,165 @@
+package explore
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/koolo/internal/game"
+	"github.com/hectorgimenez/koolo/internal/pather/gridtest"
+)
+
+func TestTourImprovesNearestNeighbor(t *testing.T) {
+	// Points on a line, nearest neighbor goes right first and has to come back
+	start, xs := 0, []int{1, 2, -3, 10}
+	tr := tour{from: make([]int32, len(xs)), dist: make([][]int32, len(xs))}
+	for i, a := range xs {
+		tr.from[i] = int32(abs(a - start))
+		tr.dist[i] = make([]int32, len(xs))
+		for j, b := range xs {
+			tr.dist[i][j] = int32(abs(a - b))
+		}
+	}
+	nodes := []int{0, 1, 2, 3}
+
+	if greedy := tourLength(tr, tr.nearestNeighbor(none, nodes)); greedy != 20 {
+		t.Fatalf("Expected nearest neighbor length to be 20, got %d", greedy)
+	}
+	if length := tourLength(tr, tr.solve(none, nodes)); length != 16 {
+		t.Errorf("Expected length to be 16, got %d", length)
+	}
+}
+
+func TestOrder(t *testing.T) {
+	g := gridtest.DuranceOfHate(t)
+	points := gridPoints(g)
+	start := data.Position{X: 336, Y: 701}
+	d := NewDistances(g, points, false)
+
+	order := d.Order(g, start, nil)
+	sorted := slices.Clone(order)
+	slices.Sort(sorted)
+	for i, p := range sorted {
+		if p != i {
+			t.Fatalf("Expected every point to be visited once, got %v", order)
+		}
+	}
+
+	// Straight line nearest neighbor, how rooms used to be ordered
+	greedy := straightLineOrder(start, points)
+	if length, greedyLength := d.Length(g, start, order), d.Length(g, start, greedy); length >= greedyLength {
+		t.Errorf("Expected order to be shorter than straight line nearest neighbor: %d >= %d", length, greedyLength)
+	}
+}
+
+func TestOrderPriority(t *testing.T) {
+	g := gridtest.DuranceOfHate(t)
+	points := gridPoints(g)
+	d := NewDistances(g, points, false)
+
+	priority := make([]bool, len(points))
+	priority[len(points)-1], priority[len(points)/2] = true, true
+
+	order := d.Order(g, data.Position{X: 336, Y: 701}, priority)
+	for _, i := range order[:2] {
+		if !priority[i] {
+			t.Fatalf("Expected priority points first, got %v", order[:2])
+		}
+	}
+}
+
+func TestGoals(t *testing.T) {
+	g := gridtest.DuranceOfHate(t)
+	start := data.Position{X: 40, Y: 270}
+	// Items spread around a big room after a pack kill, the last one is inside a wall with nothing walkable around
+	goals := []data.Position{{X: 15, Y: 260}, {X: 60, Y: 290}, {X: 20, Y: 285}, {X: 55, Y: 258}, {X: 42, Y: 262}, {X: 25, Y: 262}, {X: 0, Y: 0}}
+	gd := NewGoals(g, start, goals, false)
+
+	costs := gd.FromStart()
+	for i, c := range costs[:len(goals)-1] {
+		if c <= 0 {
+			t.Errorf("Expected goal %d to be reachable, got %d", i, c)
+		}
+	}
+	if c := costs[len(goals)-1]; c != -1 {
+		t.Errorf("Expected goal inside the wall to be unreachable, got %d", c)
+	}
+
+	order := gd.Order()
+	if len(order) != len(goals) || order[len(order)-1] != len(goals)-1 {
+		t.Fatalf("Expected every goal once and the unreachable one last, got %v", order)
+	}
+	if length, greedyLength := gd.Length(order), gd.Length(straightLineOrder(start, goals)); length > greedyLength {
+		t.Errorf("Expected order to be at most as long as straight line nearest neighbor: %d > %d", length, greedyLength)
+	}
+}
+
+func TestGoalGroups(t *testing.T) {
+	points := []data.Position{{X: 10, Y: 10}, {X: 40, Y: 10}, {X: 12, Y: 13}, {X: 0, Y: 0}, {X: 14, Y: 10}, {X: 41, Y: 9}}
+	from := []int32{5, 30, 6, -1, 8, 31}
+
+	groups := goalGroups(points, from)
+	want := [][]int{{0, 2}, {1, 5}, {4}}
+	if len(groups) != len(want) {
+		t.Fatalf("Expected groups %v, got %v", want, groups)
+	}
+	for i := range want {
+		if !slices.Equal(groups[i], want[i]) {
+			t.Errorf("Expected groups %v, got %v", want, groups)
+		}
+	}
+}
+
+func BenchmarkNewDistances(b *testing.B) {
+	g := gridtest.DuranceOfHate(b)
+	points := gridPoints(g)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		NewDistances(g, points, false)
+	}
+}
+
+func tourLength(tr tour, order []int) int {
+	length, prev := 0, none
+	for _, i := range order {
+		length += tr.cost(prev, i)
+		prev = i
+	}
+
+	return length
+}
+
+// gridPoints returns a walkable point every 40 tiles, like room centers
+func gridPoints(g *game.Grid) []data.Position {
+	var points []data.Position
+	for y := 20; y < g.Height; y += 40 {
+		for x := 20; x < g.Width; x += 40 {
+			if p := snap(g, data.Position{X: x, Y: y}, false); passable(g, p.X, p.Y, false) {
+				points = append(points, p)
+			}
+		}
+	}
+
+	return points
+}
+
+func straightLineOrder(start data.Position, points []data.Position) []int {
+	visited := make([]bool, len(points))
+	order := make([]int, 0, len(points))
+	current := start
+	for len(order) < len(points) {
+		best, bestDistance := 0, math.MaxFloat64
+		for i, p := range points {
+			if d := math.Hypot(float64(p.X-current.X), float64(p.Y-current.Y)); !visited[i] && d < bestDistance {
+				best, bestDistance = i, d
+			}
+		}
+		visited[best] = true
+		order = append(order, best)
+		current = points[best]
+	}
+
+	return order
+}
@@ -0,0 +1,163 @@
+package explore
+
+import (
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/koolo/internal/game"
+	"github.com/hectorgimenez/koolo/internal/pather/astar"
+)
+
+// Goals closer than this to the first goal of a group share its flood, items of a pack kill drop in a few spots
+const goalGroupRadius = 3
+
+// Goals has the path costs from a start position to a few nearby goals (items, chests...) and between them. It's a
+// single Dijkstra flood from the start plus one per group of goals, every flood limited to the area around the
+// positions, so it's cheap enough to build for every batch of goals, unlike Distances.
+type Goals struct {
+	from []int32   // -1 when there is no path
+	dist [][]int32 // -1 when there is no path
+}
+
+// NewGoals floods from start and from the first goal of every group, positions are relative to the grid. Goals of the
+// same group are a straight walk apart, goals without path from start are not flooded. Goals on blocked tiles
+// (objects) are moved to the closest walkable tile.
+func NewGoals(g *game.Grid, start data.Position, goals []data.Position, canTeleport bool) *Goals {
+	points := snapAll(g, goals, canTeleport)
+
+	gd := &Goals{from: toInt32(astar.FloodCosts(g, snap(g, start, canTeleport), points, canTeleport))}
+	gd.dist = make([][]int32, len(points))
+	for i := range gd.dist {
+		gd.dist[i] = make([]int32, len(points))
+		for j := range gd.dist[i] {
+			if i != j && (gd.from[i] < 0 || gd.from[j] < 0) {
+				gd.dist[i][j] = -1
+			}
+		}
+	}
+	groups := goalGroups(points, gd.from)
+	for _, group := range groups {
+		for _, i := range group {
+			for _, j := range group {
+				gd.dist[i][j] = int32(max(abs(points[i].X-points[j].X), abs(points[i].Y-points[j].Y)))
+			}
+		}
+	}
+	// Costs are almost symmetric (entering a tile is what costs), every flood only needs the groups after its own
+	for gi, group := range groups {
+		var targets []int
+		for _, later := range groups[gi+1:] {
+			targets = append(targets, later...)
+		}
+		if len(targets) == 0 {
+			break
+		}
+
+		positions := make([]data.Position, len(targets))
+		for k, j := range targets {
+			positions[k] = points[j]
+		}
+		costs := astar.FloodCosts(g, points[group[0]], positions, canTeleport)
+		for _, i := range group {
+			for k, j := range targets {
+				gd.dist[i][j] = int32(costs[k])
+				gd.dist[j][i] = int32(costs[k])
+			}
+		}
+	}
+
+	return gd
+}
+
+// GoalCosts returns the path cost from start to every goal with a single flood, -1 for the ones without path. Goals on
+// blocked tiles are moved like in NewGoals.
+func GoalCosts(g *game.Grid, start data.Position, goals []data.Position, canTeleport bool) []int {
+	return astar.FloodCosts(g, snap(g, start, canTeleport), snapAll(g, goals, canTeleport), canTeleport)
+}
+
+func snapAll(g *game.Grid, positions []data.Position, canTeleport bool) []data.Position {
+	snapped := make([]data.Position, len(positions))
+	for i, p := range positions {
+		snapped[i] = snap(g, p, canTeleport)
+	}
+
+	return snapped
+}
+
+// goalGroups returns the indexes of the points grouped around the first point of every group, points without path
+// from the start are left out
+func goalGroups(points []data.Position, from []int32) [][]int {
+	var groups [][]int
+	for i, p := range points {
+		if from[i] < 0 {
+			continue
+		}
+		grouped := false
+		for gi, group := range groups {
+			first := points[group[0]]
+			if max(abs(p.X-first.X), abs(p.Y-first.Y)) <= goalGroupRadius {
+				groups[gi] = append(groups[gi], i)
+				grouped = true
+				break
+			}
+		}
+		if !grouped {
+			groups = append(groups, []int{i})
+		}
+	}
+
+	return groups
+}
+
+// FromStart returns the path cost from the start to every goal, -1 for the ones without path
+func (gd *Goals) FromStart() []int {
+	costs := make([]int, len(gd.from))
+	for i, c := range gd.from {
+		costs[i] = int(c)
+	}
+
+	return costs
+}
+
+// Order returns the indexes of the goals in the order they should be visited from the start, goals without path go
+// last
+func (gd *Goals) Order() []int {
+	var reachable, unreachable []int
+	for i, c := range gd.from {
+		if c < 0 {
+			unreachable = append(unreachable, i)
+		} else {
+			reachable = append(reachable, i)
+		}
+	}
+
+	order := tour{from: gd.from, dist: gd.dist}.solve(none, reachable)
+
+	return append(order, unreachable...)
+}
+
+// Length is the path cost of visiting the goals in order from the start, goals without path are skipped
+func (gd *Goals) Length(order []int) int {
+	length := 0
+	prev := none
+	for _, i := range order {
+		c := gd.from[i]
+		if prev != none {
+			c = gd.dist[prev][i]
+		}
+		if c < 0 {
+			continue
+		}
+		length += int(c)
+		prev = i
+	}
+
+	return length
+}
+
+func toInt32(values []int) []int32 {
+	result := make([]int32, len(values))
+	for i, v := range values {
+		result[i] = int32(v)
+	}
+
+	return result
+}
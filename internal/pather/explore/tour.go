@@ -0,0 +1,148 @@
+package explore
+
+import "slices"
+
+// Cost used for a pair of points without a path, big enough to never be picked but still safe to add
+const noPath = 1 << 24
+
+// Improvement passes, every pass tries all the moves so it usually stops way before
+const maxPasses = 50
+
+// none is the node of the tour start and the missing neighbor after the last point
+const none = -1
+
+// tour finds a short open path visiting points once, starting from a fixed position
+type tour struct {
+	from []int32   // Distance from the tour start
+	dist [][]int32 // Distance between points
+}
+
+func (t tour) cost(a, b int) int {
+	if b == none {
+		return 0
+	}
+	d := t.dist
+	var c int32
+	if a == none {
+		c = t.from[b]
+	} else {
+		c = d[a][b]
+	}
+	if c < 0 {
+		return noPath
+	}
+
+	return int(c)
+}
+
+// solve returns the visiting order of nodes starting from start (none for the tour start): nearest neighbor followed
+// by 2-opt and Or-opt improvements
+func (t tour) solve(start int, nodes []int) []int {
+	order := t.nearestNeighbor(start, nodes)
+	for pass := 0; pass < maxPasses; pass++ {
+		improved := t.twoOpt(start, order)
+		if t.orOpt(start, order) {
+			improved = true
+		}
+		if !improved {
+			break
+		}
+	}
+
+	return order
+}
+
+func (t tour) nearestNeighbor(start int, nodes []int) []int {
+	remaining := slices.Clone(nodes)
+	order := make([]int, 0, len(nodes))
+	current := start
+	for len(remaining) > 0 {
+		best := 0
+		for i := range remaining {
+			if t.cost(current, remaining[i]) < t.cost(current, remaining[best]) {
+				best = i
+			}
+		}
+		current = remaining[best]
+		order = append(order, current)
+		remaining = slices.Delete(remaining, best, best+1)
+	}
+
+	return order
+}
+
+// twoOpt reverses the sections of the order that make it shorter, distances are symmetric so only the ends change
+func (t tour) twoOpt(start int, order []int) bool {
+	improved := false
+	for i := 0; i < len(order)-1; i++ {
+		for j := i + 1; j < len(order); j++ {
+			prev, next := t.before(start, order, i), t.after(order, j)
+			delta := t.cost(prev, order[j]) + t.cost(order[i], next) - t.cost(prev, order[i]) - t.cost(order[j], next)
+			if delta < 0 {
+				slices.Reverse(order[i : j+1])
+				improved = true
+			}
+		}
+	}
+
+	return improved
+}
+
+// orOpt moves sections of up to 3 points, reversed or not, to the place where they make the order shorter
+func (t tour) orOpt(start int, order []int) bool {
+	improved := false
+	for length := 1; length <= 3; length++ {
+		for i := 0; i+length <= len(order); i++ {
+			segment := slices.Clone(order[i : i+length])
+			prev, next := t.before(start, order, i), t.after(order, i+length-1)
+			removed := t.cost(prev, segment[0]) + t.cost(segment[length-1], next) - t.cost(prev, next)
+
+			rest := slices.Concat(order[:i], order[i+length:])
+			bestGain, bestAt, bestReversed := 0, 0, false
+			for k := 0; k <= len(rest); k++ {
+				if k == i {
+					continue
+				}
+				a, b := t.before(start, rest, k), none
+				if k < len(rest) {
+					b = rest[k]
+				}
+				for _, reversed := range []bool{false, true} {
+					first, last := segment[0], segment[length-1]
+					if reversed {
+						first, last = last, first
+					}
+					added := t.cost(a, first) + t.cost(last, b) - t.cost(a, b)
+					if gain := removed - added; gain > bestGain {
+						bestGain, bestAt, bestReversed = gain, k, reversed
+					}
+				}
+			}
+			if bestGain == 0 {
+				continue
+			}
+
+			if bestReversed {
+				slices.Reverse(segment)
+			}
+			copy(order, slices.Concat(rest[:bestAt], segment, rest[bestAt:]))
+			improved = true
+		}
+	}
+
+	return improved
+}
+
+func (t tour) before(start int, order []int, i int) int {
+	if i == 0 {
+		return start
+	}
+	return order[i-1]
+}
+
+func (t tour) after(order []int, i int) int {
+	if i == len(order)-1 {
+		return none
+	}
+	return order[i+1]
+}
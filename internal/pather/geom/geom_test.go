@@ -0,0 +1,212 @@
+package geom
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/koolo/internal/game"
+	"github.com/hectorgimenez/koolo/internal/pather/gridtest"
+)
+
+func TestLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to data.Position
+		expected []data.Position
+	}{
+		{"same tile", data.Position{X: 3, Y: 3}, data.Position{X: 3, Y: 3}, []data.Position{{X: 3, Y: 3}}},
+		{"horizontal", data.Position{X: 0, Y: 0}, data.Position{X: 3, Y: 0}, []data.Position{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0}, {X: 3, Y: 0}}},
+		{"diagonal backwards", data.Position{X: 2, Y: 2}, data.Position{X: 0, Y: 0}, []data.Position{{X: 2, Y: 2}, {X: 1, Y: 1}, {X: 0, Y: 0}}},
+		{"shallow", data.Position{X: 0, Y: 0}, data.Position{X: 4, Y: 2}, []data.Position{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 1}, {X: 3, Y: 1}, {X: 4, Y: 2}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Line(tt.from, tt.to); !slices.Equal(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSupercover(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to data.Position
+		expected []data.Position
+	}{
+		{"vertical", data.Position{X: 0, Y: 0}, data.Position{X: 0, Y: -2}, []data.Position{{X: 0, Y: 0}, {X: 0, Y: -1}, {X: 0, Y: -2}}},
+		{"diagonal includes corners", data.Position{X: 0, Y: 0}, data.Position{X: 2, Y: 2}, []data.Position{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 1}, {X: 2, Y: 1}, {X: 1, Y: 2}, {X: 2, Y: 2}}},
+		{"shallow", data.Position{X: 0, Y: 0}, data.Position{X: 4, Y: 1}, []data.Position{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0}, {X: 2, Y: 1}, {X: 3, Y: 1}, {X: 4, Y: 1}}},
+		{"through a corner", data.Position{X: 0, Y: 0}, data.Position{X: 3, Y: 1}, []data.Position{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0}, {X: 1, Y: 1}, {X: 2, Y: 1}, {X: 3, Y: 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Supercover(tt.from, tt.to); !slices.Equal(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+// Positions in the tests are relative to the Durance of Hate fixture, there is a big room with a 5x5 pillar around
+// 32,262 (corners cut) and the room is walled at x 10
+func TestLineOfSight(t *testing.T) {
+	g := gridtest.DuranceOfHate(t)
+	tests := []struct {
+		name           string
+		from, to       data.Position
+		expected       bool
+		expectedStrict bool
+	}{
+		{"open room", rel(g, 15, 270), rel(g, 55, 270), true, true},
+		{"behind pillar", rel(g, 20, 262), rel(g, 45, 262), false, false},
+		{"diagonal next to the pillar corner", rel(g, 28, 262), rel(g, 32, 258), true, false},
+		{"into the wall", rel(g, 15, 270), rel(g, 10, 270), false, false},
+		{"outside the grid", rel(g, 15, 270), rel(g, -5, 270), false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LineOfSight(g, tt.from, tt.to); got != tt.expected {
+				t.Errorf("Expected line of sight %v, got %v", tt.expected, got)
+			}
+			if got := LineOfSightStrict(g, tt.from, tt.to); got != tt.expectedStrict {
+				t.Errorf("Expected strict line of sight %v, got %v", tt.expectedStrict, got)
+			}
+		})
+	}
+}
+
+func TestRaycast(t *testing.T) {
+	g := gridtest.DuranceOfHate(t)
+	tests := []struct {
+		name     string
+		from, to data.Position
+		last     data.Position
+		reached  bool
+	}{
+		{"reaches the destination", rel(g, 15, 270), rel(g, 55, 270), rel(g, 55, 270), true},
+		{"stops before the pillar", rel(g, 20, 262), rel(g, 45, 262), rel(g, 29, 262), false},
+		{"stops before the wall", rel(g, 15, 270), rel(g, 5, 270), rel(g, 11, 270), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			last, reached := Raycast(g, tt.from, tt.to)
+			if last != tt.last || reached != tt.reached {
+				t.Errorf("Expected %v %v, got %v %v", tt.last, tt.reached, last, reached)
+			}
+		})
+	}
+}
+
+func TestFieldOfView(t *testing.T) {
+	g := gridtest.DuranceOfHate(t)
+	origin := rel(g, 20, 262)
+	fov := FieldOfView(g, origin, 25)
+
+	tests := []struct {
+		name     string
+		p        data.Position
+		expected bool
+	}{
+		{"origin", origin, true},
+		{"open room", rel(g, 40, 270), true},
+		{"pillar", rel(g, 32, 262), false},
+		{"behind pillar", rel(g, 40, 262), false},
+		{"out of radius", rel(g, 50, 270), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if fov[tt.p] != tt.expected {
+				t.Errorf("Expected %v visible: %v", tt.p, tt.expected)
+			}
+		})
+	}
+
+	for p := range fov {
+		if !LineOfSight(g, origin, p) {
+			t.Fatalf("Position %v is in the field of view without line of sight", p)
+		}
+	}
+}
+
+func TestCastPositions(t *testing.T) {
+	g := gridtest.DuranceOfHate(t)
+	from, target := rel(g, 15, 262), rel(g, 40, 262)
+
+	positions := CastPositions(g, from, target, 5, 12)
+	if len(positions) == 0 {
+		t.Fatal("Expected cast positions")
+	}
+	for i, p := range positions {
+		if d := Distance(target, p); d < 5 || d > 12 {
+			t.Errorf("Position %v out of range: %d", p, d)
+		}
+		if !LineOfSight(g, p, target) {
+			t.Errorf("Position %v without line of sight", p)
+		}
+		if i > 0 && Distance(from, p) < Distance(from, positions[i-1]) {
+			t.Errorf("Positions not sorted by distance at %d", i)
+		}
+	}
+	// Straight west of the target is behind the pillar
+	if slices.Contains(positions, rel(g, 29, 262)) {
+		t.Error("Expected position behind the pillar to be discarded")
+	}
+}
+
+func TestSafeSpot(t *testing.T) {
+	g := gridtest.DuranceOfHate(t)
+	// Every position around is close to a threat
+	everywhere := []data.Position{rel(g, 45, 270)}
+	for x := 0; x <= 80; x += 4 {
+		for y := 240; y <= 300; y += 4 {
+			everywhere = append(everywhere, rel(g, x, y))
+		}
+	}
+
+	tests := []struct {
+		name    string
+		q       SafeSpotQuery
+		expects bool
+	}{
+		{
+			name: "open room",
+			q: SafeSpotQuery{
+				Player: rel(g, 40, 270), Target: rel(g, 45, 270), Threats: []data.Position{rel(g, 45, 270), rel(g, 47, 272)},
+				DangerDistance: 10, SafeDistance: 15, MinAttackDistance: 5, MaxAttackDistance: 20,
+			},
+			expects: true,
+		},
+		{
+			name: "surrounded",
+			q: SafeSpotQuery{
+				Player: rel(g, 40, 270), Target: rel(g, 45, 270), Threats: everywhere,
+				DangerDistance: 10, SafeDistance: 15, MinAttackDistance: 5, MaxAttackDistance: 20,
+			},
+			expects: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pos, _, found := SafeSpot(g, tt.q)
+			if found != tt.expects {
+				t.Fatalf("Expected found %v, got %v", tt.expects, found)
+			}
+			if !found {
+				return
+			}
+			if !g.IsWalkable(pos) || !LineOfSight(g, pos, tt.q.Target) {
+				t.Errorf("Expected walkable position with line of sight, got %v", pos)
+			}
+			if d := closestThreatDistance(pos, tt.q.Threats); d < float64(tt.q.SafeDistance+tt.q.DangerDistance)/2 {
+				t.Errorf("Expected position away from threats, got %v at %.0f", pos, d)
+			}
+		})
+	}
+}
+
+func rel(g *game.Grid, x, y int) data.Position {
+	return data.Position{X: x + g.OffsetX, Y: y + g.OffsetY}
+}
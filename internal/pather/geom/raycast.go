@@ -0,0 +1,120 @@
+// Package geom has line of sight and visibility queries working only on a collision grid, positions are absolute
+// like the ones from game data
+package geom
+
+import (
+	"math"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/koolo/internal/game"
+)
+
+// Line returns the tiles of the Bresenham line between both positions, both included
+func Line(from, to data.Position) []data.Position {
+	dx, dy := abs(to.X-from.X), abs(to.Y-from.Y)
+	sx, sy := sign(to.X-from.X), sign(to.Y-from.Y)
+	points := make([]data.Position, 0, max(dx, dy)+1)
+
+	err := dx - dy
+	x, y := from.X, from.Y
+	for {
+		points = append(points, data.Position{X: x, Y: y})
+		if x == to.X && y == to.Y {
+			return points
+		}
+		e2 := 2 * err
+		if e2 > -dy {
+			err -= dy
+			x += sx
+		}
+		if e2 < dx {
+			err += dx
+			y += sy
+		}
+	}
+}
+
+// Supercover returns every tile the segment between the center of both tiles goes through, unlike Line it never cuts
+// corners. When the segment goes exactly through a corner both neighbours are included.
+func Supercover(from, to data.Position) []data.Position {
+	nx, ny := abs(to.X-from.X), abs(to.Y-from.Y)
+	sx, sy := sign(to.X-from.X), sign(to.Y-from.Y)
+	points := make([]data.Position, 0, nx+ny+1)
+
+	p := from
+	points = append(points, p)
+	for ix, iy := 0, 0; ix < nx || iy < ny; {
+		switch decision := (1+2*ix)*ny - (1+2*iy)*nx; {
+		case decision == 0:
+			points = append(points, data.Position{X: p.X + sx, Y: p.Y}, data.Position{X: p.X, Y: p.Y + sy})
+			p.X += sx
+			p.Y += sy
+			ix++
+			iy++
+		case decision < 0:
+			p.X += sx
+			ix++
+		default:
+			p.Y += sy
+			iy++
+		}
+		points = append(points, p)
+	}
+
+	return points
+}
+
+// Raycast walks the Bresenham line from origin to destination and returns the last walkable tile before hitting
+// something, the boolean is true when the destination is reached
+func Raycast(g *game.Grid, from, to data.Position) (data.Position, bool) {
+	last := from
+	for _, p := range Line(from, to) {
+		if !g.IsWalkable(p) {
+			return last, false
+		}
+		last = p
+	}
+
+	return last, true
+}
+
+// LineOfSight is true when every tile of the Bresenham line between both positions is walkable, it's the check the
+// game uses for most of the skills
+func LineOfSight(g *game.Grid, from, to data.Position) bool {
+	_, reached := Raycast(g, from, to)
+	return reached
+}
+
+// LineOfSightStrict is LineOfSight without cutting wall corners, every tile touched by the segment must be walkable
+func LineOfSightStrict(g *game.Grid, from, to data.Position) bool {
+	for _, p := range Supercover(from, to) {
+		if !g.IsWalkable(p) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Distance is the euclidean distance truncated to tiles, same as pather.DistanceFromPoint
+func Distance(from, to data.Position) int {
+	dx, dy := float64(to.X-from.X), float64(to.Y-from.Y)
+	return int(math.Sqrt(dx*dx + dy*dy))
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func sign(v int) int {
+	switch {
+	case v < 0:
+		return -1
+	case v > 0:
+		return 1
+	}
+	return 0
+}
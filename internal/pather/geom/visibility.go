@@ -0,0 +1,149 @@
+package geom
+
+import (
+	"math"
+	"slices"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/koolo/internal/game"
+)
+
+// FieldOfView returns the walkable tiles within radius with line of sight from the origin
+func FieldOfView(g *game.Grid, origin data.Position, radius int) map[data.Position]bool {
+	visible := make(map[data.Position]bool)
+	if !g.IsWalkable(origin) {
+		return visible
+	}
+
+	for y := origin.Y - radius; y <= origin.Y+radius; y++ {
+		for x := origin.X - radius; x <= origin.X+radius; x++ {
+			p := data.Position{X: x, Y: y}
+			if Distance(origin, p) > radius || !g.IsWalkable(p) {
+				continue
+			}
+			if LineOfSight(g, origin, p) {
+				visible[p] = true
+			}
+		}
+	}
+
+	return visible
+}
+
+// CastPositions returns the walkable tiles between minRange and maxRange from the target with line of sight to it,
+// closest to from first. It's where a ranged character can attack the target from.
+func CastPositions(g *game.Grid, from, target data.Position, minRange, maxRange int) []data.Position {
+	var positions []data.Position
+	for y := target.Y - maxRange; y <= target.Y+maxRange; y++ {
+		for x := target.X - maxRange; x <= target.X+maxRange; x++ {
+			p := data.Position{X: x, Y: y}
+			if d := Distance(target, p); d < minRange || d > maxRange {
+				continue
+			}
+			if g.IsWalkable(p) && LineOfSight(g, p, target) {
+				positions = append(positions, p)
+			}
+		}
+	}
+
+	slices.SortStableFunc(positions, func(a, b data.Position) int {
+		return Distance(from, a) - Distance(from, b)
+	})
+
+	return positions
+}
+
+// SafeSpotQuery describes where the player is and what it's fighting, distances are in tiles
+type SafeSpotQuery struct {
+	Player            data.Position
+	Target            data.Position
+	Threats           []data.Position // Alive enemies, the target included
+	DangerDistance    int
+	SafeDistance      int
+	MinAttackDistance int
+	MaxAttackDistance int
+}
+
+// SafeSpot looks for a walkable position around the player away from the threats, in attack range and with line of
+// sight to the target. Positions are scored by the distance to the closest threat first, then by the attack range and
+// how far the player has to move. Returns the best position and its score.
+func SafeSpot(g *game.Grid, q SafeSpotQuery) (data.Position, float64, bool) {
+	// Stricter minimum safe distance from monsters
+	minSafeThreatDistance := int(math.Floor((float64(q.SafeDistance) + float64(q.DangerDistance)) / 2))
+
+	best, bestScore, found := data.Position{}, 0.0, false
+	for _, pos := range safeSpotCandidates(g, q, minSafeThreatDistance) {
+		if !LineOfSight(g, pos, q.Target) {
+			continue
+		}
+
+		threatDistance := closestThreatDistance(pos, q.Threats)
+		if threatDistance < float64(minSafeThreatDistance) {
+			continue
+		}
+
+		// Highest when in the optimal attack range, out of range positions are penalized
+		targetDistance := Distance(pos, q.Target)
+		attackRangeScore := 10.0
+		if targetDistance < q.MinAttackDistance || targetDistance > q.MaxAttackDistance {
+			attackRangeScore = -math.Abs(float64(targetDistance) - float64(q.MinAttackDistance+q.MaxAttackDistance)/2.0)
+		}
+
+		// Heavily weight threat distance for safety
+		score := threatDistance*3.0 + attackRangeScore*2.0 - float64(Distance(pos, q.Player))*0.5
+		if threatDistance > float64(q.DangerDistance) {
+			score += 5.0
+		}
+
+		if !found || score > bestScore {
+			best, bestScore, found = pos, score, true
+		}
+	}
+
+	return best, bestScore, found
+}
+
+func safeSpotCandidates(g *game.Grid, q SafeSpotQuery, minSafeThreatDistance int) []data.Position {
+	var candidates []data.Position
+	addAround := func(center data.Position, radius int) {
+		for offsetX := -radius; offsetX <= radius; offsetX++ {
+			for offsetY := -radius; offsetY <= radius; offsetY++ {
+				p := data.Position{X: center.X + offsetX, Y: center.Y + offsetY}
+				if g.IsWalkable(p) {
+					candidates = append(candidates, p)
+				}
+			}
+		}
+	}
+
+	// First the positions in the opposite direction from the target
+	vectorX, vectorY := q.Player.X-q.Target.X, q.Player.Y-q.Target.Y
+	if length := math.Sqrt(float64(vectorX*vectorX + vectorY*vectorY)); length > 0 {
+		addAround(data.Position{
+			X: q.Player.X + int(float64(vectorX)/length*float64(q.SafeDistance)),
+			Y: q.Player.Y + int(float64(vectorY)/length*float64(q.SafeDistance)),
+		}, 3)
+	}
+
+	// Then a circle around the player at different distances
+	for angle := 0; angle < 360; angle += 5 {
+		radians := float64(angle) * math.Pi / 180
+		for distance := minSafeThreatDistance; distance <= q.SafeDistance+5; distance += 2 {
+			addAround(data.Position{
+				X: q.Player.X + int(math.Cos(radians)*float64(distance)),
+				Y: q.Player.Y + int(math.Sin(radians)*float64(distance)),
+			}, 1)
+		}
+	}
+
+	return candidates
+}
+
+func closestThreatDistance(pos data.Position, threats []data.Position) float64 {
+	closest := math.MaxFloat64
+	for _, t := range threats {
+		closest = min(closest, float64(Distance(pos, t)))
+	}
+
+	return closest
+}
@@ -0,0 +1,45 @@
+package pather
+
+import (
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/koolo/internal/pather/explore"
+)
+
+// GoalDistances returns the path cost from the player to every goal, -1 for the ones without path. It's a single
+// flood instead of a path per goal, goals should be close to the player (items after a pack kill, chests of a room).
+func (pf *PathFinder) GoalDistances(goals []data.Position) []int {
+	grid := pf.data.AreaData.Grid
+	if grid == nil {
+		costs := make([]int, len(goals))
+		for i := range costs {
+			costs[i] = -1
+		}
+		return costs
+	}
+
+	return explore.GoalCosts(grid, grid.RelativePosition(pf.data.PlayerUnit.Position), pf.relativeGoals(goals), pf.data.CanTeleport())
+}
+
+// VisitOrder returns the indexes of the goals in the order that visits all of them with the shortest walk from the
+// player, goals without path go last. It floods once from the player and once per group of goals close together.
+func (pf *PathFinder) VisitOrder(goals []data.Position) []int {
+	grid := pf.data.AreaData.Grid
+	if grid == nil || len(goals) < 2 {
+		order := make([]int, len(goals))
+		for i := range order {
+			order[i] = i
+		}
+		return order
+	}
+
+	return explore.NewGoals(grid, grid.RelativePosition(pf.data.PlayerUnit.Position), pf.relativeGoals(goals), pf.data.CanTeleport()).Order()
+}
+
+func (pf *PathFinder) relativeGoals(goals []data.Position) []data.Position {
+	relative := make([]data.Position, len(goals))
+	for i, g := range goals {
+		relative[i] = pf.data.AreaData.Grid.RelativePosition(g)
+	}
+
+	return relative
+}
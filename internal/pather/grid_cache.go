@@ -0,0 +1,72 @@
+package pather
+
+import (
+	"sync"
+
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+	"github.com/hectorgimenez/koolo/internal/game"
+	"github.com/hectorgimenez/koolo/internal/pather/astar"
+)
+
+// Grids with more cells than this get a hierarchy for long paths, plain A* is fast enough on smaller ones
+const hierarchyMinCells = 250 * 250
+
+type gridKey struct {
+	origin      area.ID
+	destination area.ID // Same as origin when the grid is not merged with an adjacent level
+	canTeleport bool
+}
+
+type cachedGrid struct {
+	origin, destination *game.Grid // Source grids, every FetchMapData creates new ones
+	grid                *game.Grid
+	hierarchy           *astar.Hierarchy // Nil until it's built in the background
+}
+
+// gridCache keeps the merged grids and their hierarchies, so they are built once per game instead of once per path
+type gridCache struct {
+	mu      sync.Mutex
+	entries map[gridKey]*cachedGrid
+}
+
+func newGridCache() *gridCache {
+	return &gridCache{entries: make(map[gridKey]*cachedGrid)}
+}
+
+// get returns the cached grid for the key, calling build when there is no grid for the current map yet. The returned
+// grid is shared, it has to be copied before changing it.
+func (gc *gridCache) get(key gridKey, origin, destination *game.Grid, build func() *game.Grid) *cachedGrid {
+	gc.mu.Lock()
+	defer gc.mu.Unlock()
+
+	if cg, found := gc.entries[key]; found && cg.origin == origin && cg.destination == destination {
+		return cg
+	}
+
+	cg := &cachedGrid{origin: origin, destination: destination, grid: build()}
+	gc.entries[key] = cg
+	if cg.grid.Width*cg.grid.Height >= hierarchyMinCells {
+		go func() {
+			h := astar.NewHierarchy(cg.grid, key.canTeleport)
+			gc.mu.Lock()
+			cg.hierarchy = h
+			gc.mu.Unlock()
+		}()
+	}
+
+	return cg
+}
+
+func (gc *gridCache) hierarchy(cg *cachedGrid) *astar.Hierarchy {
+	gc.mu.Lock()
+	defer gc.mu.Unlock()
+
+	return cg.hierarchy
+}
+
+func (gc *gridCache) reset() {
+	gc.mu.Lock()
+	defer gc.mu.Unlock()
+
+	clear(gc.entries)
+}
@@ -0,0 +1,27 @@
+// Package gridtest has the collision grid fixtures shared by the pathing tests
+package gridtest
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/gob"
+	"testing"
+
+	"github.com/hectorgimenez/koolo/internal/game"
+)
+
+//go:embed durance_of_hate_grid.bin
+var duranceOfHate []byte
+
+// DuranceOfHate returns the collision grid of Durance of Hate Level 3, every call decodes a new copy so tests can
+// modify it
+func DuranceOfHate(tb testing.TB) *game.Grid {
+	tb.Helper()
+
+	var grid game.Grid
+	if err := gob.NewDecoder(bytes.NewReader(duranceOfHate)).Decode(&grid); err != nil {
+		tb.Fatal(err)
+	}
+
+	return &grid
+}
@@ -10,34 +10,69 @@ import (
 	"github.com/hectorgimenez/koolo/internal/config"
 	"github.com/hectorgimenez/koolo/internal/game"
 	"github.com/hectorgimenez/koolo/internal/pather/astar"
+	"github.com/hectorgimenez/koolo/internal/pather/danger"
 )
 
 type PathFinder struct {
-	gr   *game.MemoryReader
-	data *game.Data
-	hid  *game.HID
-	cfg  *config.CharacterCfg
+	gr      game.Reader
+	data    *game.Data
+	hid     game.Input
+	cfg     *config.CharacterCfg
+	grids   *gridCache
+	rooms   *roomDistances
+	history *pathHistory
+	blocked *blockedTiles
 }
 
-func NewPathFinder(gr *game.MemoryReader, data *game.Data, hid *game.HID, cfg *config.CharacterCfg) *PathFinder {
+func NewPathFinder(gr game.Reader, data *game.Data, hid game.Input, cfg *config.CharacterCfg) *PathFinder {
 	return &PathFinder{
-		gr:   gr,
-		data: data,
-		hid:  hid,
-		cfg:  cfg,
+		gr:      gr,
+		data:    data,
+		hid:     hid,
+		cfg:     cfg,
+		grids:   newGridCache(),
+		rooms:   &roomDistances{},
+		history: &pathHistory{},
+		blocked: &blockedTiles{},
 	}
 }
 
+// Prepare drops the grids and blocked tiles of the previous game and starts building the hierarchy of the current
+// area, it should be called after fetching the map data
+func (pf *PathFinder) Prepare() {
+	pf.grids.reset()
+	pf.blocked.reset()
+	if pf.data.AreaData.Grid != nil {
+		pf.areaGrid(pf.data.CanTeleport())
+	}
+}
+
+func (pf *PathFinder) areaGrid(canTeleport bool) *cachedGrid {
+	a := pf.data.AreaData
+	key := gridKey{origin: a.Area, destination: a.Area, canTeleport: canTeleport}
+
+	return pf.grids.get(key, a.Grid, a.Grid, func() *game.Grid { return a.Grid })
+}
+
 func (pf *PathFinder) GetPath(to data.Position) (Path, int, bool) {
+	return pf.getPath(to, false)
+}
+
+// GetSafePath is GetPath keeping farther away from monsters than usual. Meant for walking characters.
+func (pf *PathFinder) GetSafePath(to data.Position) (Path, int, bool) {
+	return pf.getPath(to, true)
+}
+
+func (pf *PathFinder) getPath(to data.Position, safe bool) (Path, int, bool) {
 	// First try direct path
-	if path, distance, found := pf.GetPathFrom(pf.data.PlayerUnit.Position, to); found {
+	if path, distance, found := pf.getPathFrom(pf.data.PlayerUnit.Position, to, safe); found {
 		return path, distance, true
 	}
 
 	walkableTo, foundTo := pf.findNearbyWalkablePosition(to)
 	// If direct path fails, try to find nearby to walkable position
 	if foundTo {
-		path, distance, found := pf.GetPathFrom(pf.data.PlayerUnit.Position, walkableTo)
+		path, distance, found := pf.getPathFrom(pf.data.PlayerUnit.Position, walkableTo, safe)
 		if found {
 			return path, distance, true
 		}
@@ -48,14 +83,22 @@ func (pf *PathFinder) GetPath(to data.Position) (Path, int, bool) {
 }
 
 func (pf *PathFinder) GetPathFrom(from, to data.Position) (Path, int, bool) {
+	return pf.getPathFrom(from, to, false)
+}
+
+func (pf *PathFinder) getPathFrom(from, to data.Position, safe bool) (Path, int, bool) {
 	a := pf.data.AreaData
 	canTeleport := pf.data.CanTeleport()
 
+	base := pf.areaGrid(canTeleport)
 	// We don't want to modify the original grid
-	grid := a.Grid.Copy()
+	grid := base.grid.Copy()
+	useHierarchy := true
 
 	// Special handling for Arcane Sanctuary (to allow pathing with platforms)
 	if pf.data.PlayerUnit.Area == area.ArcaneSanctuary && pf.data.CanTeleport() {
+		// Too different from the grid the hierarchy was built from
+		useHierarchy = false
 		// Make all non-walkable tiles into low priority tiles for teleport pathing
 		for y := 0; y < len(grid.CollisionGrid); y++ {
 			for x := 0; x < len(grid.CollisionGrid[y]); x++ {
@@ -75,7 +118,9 @@ func (pf *PathFinder) GetPathFrom(from, to data.Position) (Path, int, bool) {
 		if err != nil {
 			return nil, 0, false
 		}
-		grid = expandedGrid
+		base = expandedGrid
+		grid = expandedGrid.grid.Copy()
+		useHierarchy = true
 	}
 
 	if !grid.IsWalkable(to) {
@@ -109,15 +154,6 @@ func (pf *PathFinder) GetPathFrom(from, to data.Position) (Path, int, bool) {
 		}
 	}
 
-	// Add monsters to the collision grid as obstacles
-	for _, m := range pf.data.Monsters {
-		if !grid.IsWalkable(m.Position) {
-			continue
-		}
-		relativePos := grid.RelativePosition(m.Position)
-		grid.CollisionGrid[relativePos.Y][relativePos.X] = game.CollisionTypeMonster
-	}
-
 	// set barricade tower as non walkable in act 5
 	if a.Area == area.FrigidHighlands || a.Area == area.FrozenTundra || a.Area == area.ArreatPlateau {
 		towerCount := 0
@@ -150,50 +186,76 @@ func (pf *PathFinder) GetPathFrom(from, to data.Position) (Path, int, bool) {
 		}
 	}
 
-	path, distance, found := astar.CalculatePath(grid, from, to, canTeleport)
+	// The hierarchy was built without the blocked tiles, it could go through them
+	if pf.blocked.apply(grid, pf.data.PlayerUnit.Area, from, to) {
+		useHierarchy = false
+	}
+
+	// Tiles around monsters cost more, the closer to elites, aura holders and monsters immune to our attacks the more
+	layer := danger.New(pf.data.Monsters, grid.OffsetX, grid.OffsetY, danger.Options{
+		Immunities: danger.AttackImmunities(pf.data.PlayerUnit.Skills),
+		Safe:       safe,
+	})
+
+	var path Path
+	var distance int
+	var found bool
+	if h := pf.grids.hierarchy(base); h != nil && useHierarchy {
+		path, distance, found = h.CalculatePathWithLayer(grid, from, to, layer)
+	} else {
+		path, distance, found = astar.CalculatePathWithLayer(grid, from, to, canTeleport, layer)
+	}
 
 	if config.Koolo.Debug.RenderMap {
 		pf.renderMap(grid, from, to, path)
 	}
+	pf.recordPath(grid, from, to, path, found, safe)
 
 	return path, distance, found
 }
 
-func (pf *PathFinder) mergeGrids(to data.Position, canTeleport bool) (*game.Grid, error) {
+// mergeGrids returns the current area grid merged with the adjacent level containing the destination, merged grids
+// are cached until the next game
+func (pf *PathFinder) mergeGrids(to data.Position, canTeleport bool) (*cachedGrid, error) {
 	for _, a := range pf.data.AreaData.AdjacentLevels {
 		destination := pf.data.Areas[a.Area]
 		if destination.IsInside(to) {
 			origin := pf.data.AreaData
+			key := gridKey{origin: origin.Area, destination: destination.Area, canTeleport: canTeleport}
 
-			endX1 := origin.OffsetX + len(origin.Grid.CollisionGrid[0])
-			endY1 := origin.OffsetY + len(origin.Grid.CollisionGrid)
-			endX2 := destination.OffsetX + len(destination.Grid.CollisionGrid[0])
-			endY2 := destination.OffsetY + len(destination.Grid.CollisionGrid)
-
-			minX := min(origin.OffsetX, destination.OffsetX)
-			minY := min(origin.OffsetY, destination.OffsetY)
-			maxX := max(endX1, endX2)
-			maxY := max(endY1, endY2)
+			return pf.grids.get(key, origin.Grid, destination.Grid, func() *game.Grid {
+				return mergedGrid(origin, destination, canTeleport)
+			}), nil
+		}
+	}
 
-			width := maxX - minX
-			height := maxY - minY
+	return nil, fmt.Errorf("destination grid not found")
+}
 
-			resultGrid := make([][]game.CollisionType, height)
-			for i := range resultGrid {
-				resultGrid[i] = make([]game.CollisionType, width)
-			}
+func mergedGrid(origin, destination game.AreaData, canTeleport bool) *game.Grid {
+	endX1 := origin.OffsetX + len(origin.Grid.CollisionGrid[0])
+	endY1 := origin.OffsetY + len(origin.Grid.CollisionGrid)
+	endX2 := destination.OffsetX + len(destination.Grid.CollisionGrid[0])
+	endY2 := destination.OffsetY + len(destination.Grid.CollisionGrid)
 
-			// Let's copy both grids into the result grid
-			copyGrid(resultGrid, origin.CollisionGrid, origin.OffsetX-minX, origin.OffsetY-minY)
-			copyGrid(resultGrid, destination.CollisionGrid, destination.OffsetX-minX, destination.OffsetY-minY)
+	minX := min(origin.OffsetX, destination.OffsetX)
+	minY := min(origin.OffsetY, destination.OffsetY)
+	maxX := max(endX1, endX2)
+	maxY := max(endY1, endY2)
 
-			grid := game.NewGrid(resultGrid, minX, minY, canTeleport)
+	width := maxX - minX
+	height := maxY - minY
 
-			return grid, nil
-		}
+	resultGrid := make([][]game.CollisionType, height)
+	for i := range resultGrid {
+		resultGrid[i] = make([]game.CollisionType, width)
 	}
 
-	return nil, fmt.Errorf("destination grid not found")
+	// Let's copy both grids into the result grid
+	copyGrid(resultGrid, origin.CollisionGrid, origin.OffsetX-minX, origin.OffsetY-minY)
+	copyGrid(resultGrid, destination.CollisionGrid, destination.OffsetX-minX, destination.OffsetY-minY)
+
+	return game.NewGrid(resultGrid, minX, minY, canTeleport)
 }
 
 func copyGrid(dest [][]game.CollisionType, src [][]game.CollisionType, offsetX, offsetY int) {
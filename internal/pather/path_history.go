@@ -0,0 +1,97 @@
+package pather
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+	"github.com/hectorgimenez/koolo/internal/game"
+)
+
+// Paths kept for debugging, enough to see the last few seconds of movement
+const pathHistorySize = 30
+
+// PathRecord is a computed path, positions are absolute
+type PathRecord struct {
+	At    time.Time       `json:"at"`
+	Area  area.ID         `json:"area"`
+	From  data.Position   `json:"from"`
+	To    data.Position   `json:"to"`
+	Path  []data.Position `json:"path"`
+	Found bool            `json:"found"`
+	Safe  bool            `json:"safe"`
+}
+
+// pathHistory is a ring buffer of the last computed paths, it's read by the debug endpoints from other goroutines
+type pathHistory struct {
+	mu      sync.Mutex
+	records []PathRecord
+	next    int
+	rooms   []data.Room // Last rooms visit order
+	roomsOf area.ID     // Level of rooms
+}
+
+func (ph *pathHistory) add(r PathRecord) {
+	ph.mu.Lock()
+	defer ph.mu.Unlock()
+
+	if len(ph.records) < pathHistorySize {
+		ph.records = append(ph.records, r)
+		return
+	}
+	ph.records[ph.next] = r
+	ph.next = (ph.next + 1) % pathHistorySize
+}
+
+// last returns up to n records, newest first
+func (ph *pathHistory) last(n int) []PathRecord {
+	ph.mu.Lock()
+	defer ph.mu.Unlock()
+
+	n = min(n, len(ph.records))
+	result := make([]PathRecord, 0, n)
+	for i := 0; i < n; i++ {
+		idx := (ph.next - 1 - i + 2*len(ph.records)) % len(ph.records)
+		result = append(result, ph.records[idx])
+	}
+
+	return result
+}
+
+func (ph *pathHistory) setRooms(a area.ID, rooms []data.Room) {
+	ph.mu.Lock()
+	defer ph.mu.Unlock()
+
+	ph.rooms, ph.roomsOf = rooms, a
+}
+
+// roomOrder returns the last rooms visit order of level a, nil when the last order was for another level
+func (ph *pathHistory) roomOrder(a area.ID) []data.Room {
+	ph.mu.Lock()
+	defer ph.mu.Unlock()
+
+	if ph.roomsOf != a {
+		return nil
+	}
+
+	return ph.rooms
+}
+
+// PathHistory returns up to n of the last computed paths, newest first
+func (pf *PathFinder) PathHistory(n int) []PathRecord {
+	return pf.history.last(n)
+}
+
+func (pf *PathFinder) recordPath(grid *game.Grid, from, to data.Position, path Path, found, safe bool) {
+	absolute := func(p data.Position) data.Position {
+		return data.Position{X: p.X + grid.OffsetX, Y: p.Y + grid.OffsetY}
+	}
+
+	r := PathRecord{At: time.Now(), Area: pf.data.PlayerUnit.Area, From: absolute(from), To: absolute(to), Found: found, Safe: safe}
+	r.Path = make([]data.Position, 0, len(path))
+	for _, p := range path {
+		r.Path = append(r.Path, absolute(p))
+	}
+	pf.history.add(r)
+}
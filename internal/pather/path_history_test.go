@@ -0,0 +1,74 @@
+package pather
+
+import (
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+)
+
+func TestPathHistoryLast(t *testing.T) {
+	record := func(i int) PathRecord {
+		return PathRecord{To: data.Position{X: i}}
+	}
+
+	tests := []struct {
+		name  string
+		added int
+		n     int
+		want  []int // To.X of the records, newest first
+	}{
+		{name: "empty", added: 0, n: 5},
+		{name: "less than n", added: 3, n: 5, want: []int{2, 1, 0}},
+		{name: "less than added", added: 5, n: 2, want: []int{4, 3}},
+		{name: "full", added: pathHistorySize, n: 2, want: []int{pathHistorySize - 1, pathHistorySize - 2}},
+		{name: "wrapped", added: pathHistorySize + 3, n: 4, want: []int{pathHistorySize + 2, pathHistorySize + 1, pathHistorySize, pathHistorySize - 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ph := &pathHistory{}
+			for i := range tt.added {
+				ph.add(record(i))
+			}
+
+			got := ph.last(tt.n)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d records, want %d", len(got), len(tt.want))
+			}
+			for i, r := range got {
+				if r.To.X != tt.want[i] {
+					t.Errorf("record %d = %d, want %d", i, r.To.X, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestPathHistoryKeepsTheNewest(t *testing.T) {
+	ph := &pathHistory{}
+	for i := range pathHistorySize + 3 {
+		ph.add(PathRecord{To: data.Position{X: i}})
+	}
+
+	got := ph.last(pathHistorySize + 10)
+	if len(got) != pathHistorySize {
+		t.Fatalf("got %d records, want %d", len(got), pathHistorySize)
+	}
+	if newest, oldest := got[0].To.X, got[len(got)-1].To.X; newest != pathHistorySize+2 || oldest != 3 {
+		t.Errorf("records go from %d to %d, want from %d to 3", newest, oldest, pathHistorySize+2)
+	}
+}
+
+func TestPathHistoryRoomOrder(t *testing.T) {
+	ph := &pathHistory{}
+	rooms := []data.Room{{Position: data.Position{X: 1, Y: 1}}}
+	ph.setRooms(area.CatacombsLevel2, rooms)
+
+	if got := ph.roomOrder(area.CatacombsLevel2); len(got) != 1 {
+		t.Errorf("room order = %v, want %v", got, rooms)
+	}
+	if got := ph.roomOrder(area.CatacombsLevel3); got != nil {
+		t.Errorf("room order of another level = %v, want none", got)
+	}
+}
@@ -0,0 +1,65 @@
+package pather
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/koolo/internal/game"
+	"github.com/hectorgimenez/koolo/internal/pather/explore"
+)
+
+// roomDistances keeps the walking distances between the rooms of the last explored level
+type roomDistances struct {
+	mu          sync.Mutex
+	grid        *game.Grid // Every FetchMapData creates new grids
+	rooms       []data.Room
+	canTeleport bool
+	distances   *explore.Distances
+}
+
+func (rd *roomDistances) get(grid *game.Grid, rooms []data.Room, canTeleport bool) *explore.Distances {
+	rd.mu.Lock()
+	defer rd.mu.Unlock()
+
+	if rd.distances != nil && rd.grid == grid && slices.Equal(rd.rooms, rooms) && rd.canTeleport == canTeleport {
+		return rd.distances
+	}
+
+	centers := make([]data.Position, len(rooms))
+	for i, r := range rooms {
+		centers[i] = grid.RelativePosition(r.GetCenter())
+	}
+	rd.grid, rd.rooms, rd.canTeleport = grid, slices.Clone(rooms), canTeleport
+	rd.distances = explore.NewDistances(grid, centers, canTeleport)
+
+	return rd.distances
+}
+
+// ExploreRooms returns the rooms of the current level in the order they should be visited, following the shortest
+// walking route from the player position. Rooms matching priority (it can be nil) are visited first.
+func (pf *PathFinder) ExploreRooms(priority func(data.Room) bool) []data.Room {
+	rooms := pf.data.Rooms
+	grid := pf.data.AreaData.Grid
+	if len(rooms) == 0 || grid == nil {
+		return rooms
+	}
+
+	prioritized := make([]bool, len(rooms))
+	if priority != nil {
+		for i, r := range rooms {
+			prioritized[i] = priority(r)
+		}
+	}
+
+	distances := pf.rooms.get(grid, rooms, pf.data.CanTeleport())
+	order := distances.Order(grid, grid.RelativePosition(pf.data.PlayerUnit.Position), prioritized)
+
+	result := make([]data.Room, 0, len(order))
+	for _, i := range order {
+		result = append(result, rooms[i])
+	}
+	pf.history.setRooms(pf.data.PlayerUnit.Area, result)
+
+	return result
+}
@@ -0,0 +1,210 @@
+package route
+
+import (
+	"container/heap"
+	"math"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+)
+
+// Costs are in game units walked, the fixed ones are roughly the distance walked in the time the action takes
+const (
+	waypointCost    = 100 // Open the menu, pick the area and wait for the loading screen
+	townPortalCost  = 120 // Cast the portal, enter it and wait for the loading screen
+	entranceCost    = 30  // Click the entrance and wait for the loading screen
+	unknownDistance = 150 // Walking distance used when a position is not known
+)
+
+type Kind int
+
+const (
+	Walk       Kind = iota // Walk through the level exit
+	Entrance               // Interact with the level entrance
+	Waypoint               // Use the waypoint, always from town
+	TownPortal             // Go back to the town of the current act
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Walk:
+		return "walk"
+	case Entrance:
+		return "entrance"
+	case Waypoint:
+		return "waypoint"
+	case TownPortal:
+		return "town portal"
+	}
+
+	return "unknown"
+}
+
+// Step moves the player to Area, Position is where the step starts (the exit, entrance or waypoint), empty when
+// it's not known
+type Step struct {
+	Kind     Kind
+	Area     area.ID
+	Position data.Position
+}
+
+// Level is the data of a level needed to plan routes
+type Level struct {
+	Exits    []data.Level
+	Waypoint data.Position // Empty when the level has no waypoint or the position is not known
+}
+
+// Levels connected through special portals not listed as exits, MoveToArea knows how to use them
+var portals = map[area.ID][]area.ID{
+	area.PalaceCellarLevel3: {area.ArcaneSanctuary},
+	area.ArcaneSanctuary:    {area.CanyonOfTheMagi},
+}
+
+var towns = map[int]area.ID{
+	1: area.RogueEncampment,
+	2: area.LutGholein,
+	3: area.KurastDocks,
+	4: area.ThePandemoniumFortress,
+	5: area.Harrogath,
+}
+
+// Planner searches the cheapest sequence of level exits, waypoints and town portals between two levels
+type Planner struct {
+	levels      map[area.ID]Level
+	waypoints   []area.ID
+	townPortals bool
+}
+
+// NewPlanner creates a planner for the levels of a game, waypoints are the ones the character has discovered and
+// townPortals tells if the character has a tome or scroll to go back to town
+func NewPlanner(levels map[area.ID]Level, waypoints []area.ID, townPortals bool) *Planner {
+	return &Planner{levels: levels, waypoints: waypoints, townPortals: townPortals}
+}
+
+type location struct {
+	area     area.ID
+	position data.Position
+}
+
+type visit struct {
+	cost int
+	from location
+	step Step
+}
+
+// Plan returns the steps from the player level and position to the destination level and the estimated cost of the
+// route, false when the destination can't be reached
+func (p *Planner) Plan(from area.ID, position data.Position, to area.ID) ([]Step, int, bool) {
+	start := location{area: from, position: position}
+	visited := map[location]visit{start: {}}
+	open := &queue{}
+	heap.Push(open, &queueNode{location: start})
+
+	for open.Len() > 0 {
+		current := heap.Pop(open).(*queueNode)
+		cv := visited[current.location]
+		if current.cost > cv.cost {
+			continue
+		}
+		if current.area == to {
+			return buildSteps(visited, start, current.location), cv.cost, true
+		}
+
+		p.neighbors(current.location, func(next location, step Step, cost int) {
+			cost += cv.cost
+			if v, found := visited[next]; found && v.cost <= cost {
+				return
+			}
+			visited[next] = visit{cost: cost, from: current.location, step: step}
+			heap.Push(open, &queueNode{location: next, cost: cost})
+		})
+	}
+
+	return nil, 0, false
+}
+
+// neighbors calls add for every location reachable with a single step from l
+func (p *Planner) neighbors(l location, add func(next location, step Step, cost int)) {
+	lvl := p.levels[l.area]
+	for _, exit := range lvl.Exits {
+		step := Step{Kind: Walk, Area: exit.Area, Position: exit.Position}
+		cost := distance(l.position, exit.Position)
+		if exit.IsEntrance {
+			step.Kind = Entrance
+			cost += entranceCost
+		}
+		add(location{area: exit.Area, position: p.arrival(exit, l.area)}, step, cost)
+	}
+
+	for _, dst := range portals[l.area] {
+		add(location{area: dst}, Step{Kind: Entrance, Area: dst}, unknownDistance+entranceCost)
+	}
+
+	if !l.area.IsTown() {
+		if town, found := towns[l.area.Act()]; found && p.townPortals {
+			add(location{area: town, position: p.levels[town].Waypoint}, Step{Kind: TownPortal, Area: town, Position: l.position}, townPortalCost)
+		}
+		return
+	}
+
+	for _, dst := range p.waypoints {
+		if _, found := area.WPAddresses[dst]; !found || dst == l.area {
+			continue
+		}
+		add(location{area: dst, position: p.levels[dst].Waypoint}, Step{Kind: Waypoint, Area: dst, Position: lvl.Waypoint}, distance(l.position, lvl.Waypoint)+waypointCost)
+	}
+}
+
+// arrival returns where the player appears after going through the exit: next to the exit of the destination level
+// pointing back, or the same position for levels joined without a loading screen
+func (p *Planner) arrival(exit data.Level, from area.ID) data.Position {
+	if exit.IsEntrance {
+		for _, back := range p.levels[exit.Area].Exits {
+			if back.Area == from {
+				return back.Position
+			}
+		}
+	}
+
+	return exit.Position
+}
+
+func buildSteps(visited map[location]visit, start, goal location) []Step {
+	var steps []Step
+	for l := goal; l != start; l = visited[l].from {
+		steps = append(steps, visited[l].step)
+	}
+	for i, j := 0, len(steps)-1; i < j; i, j = i+1, j-1 {
+		steps[i], steps[j] = steps[j], steps[i]
+	}
+
+	return steps
+}
+
+func distance(from, to data.Position) int {
+	if from == (data.Position{}) || to == (data.Position{}) {
+		return unknownDistance
+	}
+
+	return int(math.Hypot(float64(from.X-to.X), float64(from.Y-to.Y)))
+}
+
+type queueNode struct {
+	location
+	cost int
+}
+
+type queue []*queueNode
+
+func (q queue) Len() int            { return len(q) }
+func (q queue) Less(i, j int) bool  { return q[i].cost < q[j].cost }
+func (q queue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *queue) Push(x interface{}) { *q = append(*q, x.(*queueNode)) }
+func (q *queue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	node := old[n-1]
+	old[n-1] = nil
+	*q = old[0 : n-1]
+	return node
+}
@@ -0,0 +1,133 @@
+package route
+
+import (
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+)
+
+// Part of act 2, every outdoor level is 200 units wide
+func act2Levels() map[area.ID]Level {
+	pos := func(x int) data.Position { return data.Position{X: x, Y: 5000} }
+
+	return map[area.ID]Level{
+		area.LutGholein: {
+			Exits:    []data.Level{{Area: area.RockyWaste, Position: pos(200)}},
+			Waypoint: pos(100),
+		},
+		area.RockyWaste: {
+			Exits: []data.Level{{Area: area.LutGholein, Position: pos(200)}, {Area: area.DryHills, Position: pos(400)}},
+		},
+		area.DryHills: {
+			Exits:    []data.Level{{Area: area.RockyWaste, Position: pos(400)}, {Area: area.FarOasis, Position: pos(600)}},
+			Waypoint: pos(500),
+		},
+		area.FarOasis: {
+			Exits:    []data.Level{{Area: area.DryHills, Position: pos(600)}, {Area: area.LostCity, Position: pos(800)}},
+			Waypoint: pos(700),
+		},
+		area.LostCity: {
+			Exits: []data.Level{
+				{Area: area.FarOasis, Position: pos(800)},
+				{Area: area.AncientTunnels, Position: pos(950), IsEntrance: true},
+			},
+			Waypoint: pos(900),
+		},
+		area.AncientTunnels: {
+			Exits: []data.Level{{Area: area.LostCity, Position: data.Position{X: 20000, Y: 20000}, IsEntrance: true}},
+		},
+	}
+}
+
+func TestPlan(t *testing.T) {
+	tests := []struct {
+		name      string
+		from      area.ID
+		position  data.Position
+		waypoints []area.ID
+		// Character without tome or scrolls
+		noTownPortal bool
+		expected     []Kind
+	}{
+		{
+			name:      "Waypoint next to the destination",
+			from:      area.LutGholein,
+			position:  data.Position{X: 110, Y: 5000},
+			waypoints: []area.ID{area.LutGholein, area.DryHills, area.FarOasis, area.LostCity},
+			expected:  []Kind{Waypoint, Entrance},
+		},
+		{
+			name:      "Walk from the closest waypoint",
+			from:      area.LutGholein,
+			position:  data.Position{X: 110, Y: 5000},
+			waypoints: []area.ID{area.LutGholein, area.DryHills, area.FarOasis},
+			expected:  []Kind{Waypoint, Walk, Entrance},
+		},
+		{
+			name:      "Walk when it's cheaper than going back to town",
+			from:      area.FarOasis,
+			position:  data.Position{X: 780, Y: 5000},
+			waypoints: []area.ID{area.LutGholein, area.LostCity},
+			expected:  []Kind{Walk, Entrance},
+		},
+		{
+			name:      "Town portal from a far level",
+			from:      area.RockyWaste,
+			position:  data.Position{X: 390, Y: 5000},
+			waypoints: []area.ID{area.LutGholein, area.LostCity},
+			expected:  []Kind{TownPortal, Waypoint, Entrance},
+		},
+		{
+			name:         "Walk back to town without town portals",
+			from:         area.RockyWaste,
+			position:     data.Position{X: 390, Y: 5000},
+			waypoints:    []area.ID{area.LutGholein, area.LostCity},
+			noTownPortal: true,
+			expected:     []Kind{Walk, Waypoint, Entrance},
+		},
+		{
+			name:      "Only walking",
+			from:      area.LutGholein,
+			position:  data.Position{X: 110, Y: 5000},
+			waypoints: []area.ID{area.LutGholein},
+			expected:  []Kind{Walk, Walk, Walk, Walk, Entrance},
+		},
+		{
+			name:     "No waypoints known",
+			from:     area.RockyWaste,
+			position: data.Position{X: 390, Y: 5000},
+			expected: []Kind{Walk, Walk, Walk, Entrance},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			steps, _, found := NewPlanner(act2Levels(), tt.waypoints, !tt.noTownPortal).Plan(tt.from, tt.position, area.AncientTunnels)
+			if !found {
+				t.Fatal("Expected a route to be found")
+			}
+			if len(steps) != len(tt.expected) {
+				t.Fatalf("Expected %v, got %v", tt.expected, steps)
+			}
+			for i, s := range steps {
+				if s.Kind != tt.expected[i] {
+					t.Errorf("Expected %v, got %v", tt.expected, steps)
+					break
+				}
+			}
+			if last := steps[len(steps)-1]; last.Area != area.AncientTunnels {
+				t.Errorf("Expected the route to end at Ancient Tunnels, ended at %v", last.Area)
+			}
+		})
+	}
+}
+
+func TestPlanUnreachable(t *testing.T) {
+	levels := act2Levels()
+	delete(levels, area.LostCity)
+
+	if steps, _, found := NewPlanner(levels, []area.ID{area.LutGholein}, true).Plan(area.LutGholein, data.Position{}, area.AncientTunnels); found {
+		t.Errorf("Expected no route, got %v", steps)
+	}
+}
@@ -0,0 +1,299 @@
+package teleport
+
+import (
+	"container/heap"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/koolo/internal/game"
+)
+
+// DefaultRadius is the teleport distance used when no radius is given, in game units. It's a bit shorter than the
+// real one, so the destination is always on screen.
+const DefaultRadius = 20
+
+// Directions tried from every landing position
+const rays = 48
+
+// Landing positions explored before giving up, it keeps the planner cheap on unreachable destinations
+const maxExpansions = 20000
+
+// Weighted A*, plans can take a cast or two more than the best one but the search explores way less positions
+const heuristicWeight = 1.5
+
+// Hop is a single teleport cast
+type Hop struct {
+	From data.Position
+	To   data.Position
+}
+
+type Options struct {
+	Radius    int  // Max distance of a cast, in game units
+	OverWalls bool // Hops can cross non walkable tiles, like Arcane Sanctuary platforms
+	// CanLand is an optional extra check for every hop, like the destination being clickable on screen
+	CanLand func(from, to data.Position) bool
+}
+
+// Plan searches the hops from start to goal, positions are relative to the grid. Plans try to use the minimum number
+// of casts, every cast takes the same time (FCR), and the shorter total distance wins between plans with the same
+// casts. Crossing teleport over tiles is allowed, landing on them is not.
+func Plan(g *game.Grid, start, goal data.Position, opts Options) ([]Hop, bool) {
+	if !walkable(g, start.X, start.Y) || !walkable(g, goal.X, goal.Y) {
+		return nil, false
+	}
+	if start == goal {
+		return []Hop{}, true
+	}
+	radius := opts.Radius
+	if radius <= 0 {
+		radius = DefaultRadius
+	}
+
+	// Walking distance to the goal guides the search, a cast can't get closer to the goal than radius tiles
+	field := newDistanceField(g, goal, opts.OverWalls)
+	defer field.release()
+	if field.at(start) < 0 {
+		return nil, false
+	}
+	estimate := func(p data.Position) float64 {
+		return heuristicWeight * float64(field.at(p)) / float64(radius)
+	}
+
+	type visit struct {
+		casts    int
+		distance float64
+		from     data.Position
+	}
+	visited := map[data.Position]visit{start: {}}
+	open := &hopQueue{}
+	heap.Push(open, &hopNode{position: start, priority: estimate(start)})
+
+	for expansions := 0; open.Len() > 0 && expansions < maxExpansions; expansions++ {
+		current := heap.Pop(open).(*hopNode)
+		if current.position == goal {
+			return buildHops(start, goal, func(p data.Position) data.Position { return visited[p].from }), true
+		}
+		cv := visited[current.position]
+		if current.casts > cv.casts || (current.casts == cv.casts && current.distance > cv.distance) {
+			// Stale entry, a better one was already expanded
+			continue
+		}
+
+		for _, to := range landings(g, current.position, goal, radius, opts) {
+			if field.at(to) < 0 {
+				continue
+			}
+			casts := cv.casts + 1
+			distance := cv.distance + euclidean(current.position, to)
+			if v, found := visited[to]; found && (v.casts < casts || (v.casts == casts && v.distance <= distance)) {
+				continue
+			}
+			visited[to] = visit{casts: casts, distance: distance, from: current.position}
+			heap.Push(open, &hopNode{
+				position: to,
+				casts:    casts,
+				distance: distance,
+				priority: float64(casts) + estimate(to),
+			})
+		}
+	}
+
+	return nil, false
+}
+
+// Duration is the estimated time to complete the hops, castDuration depends on the character FCR
+func Duration(hops []Hop, castDuration time.Duration) time.Duration {
+	return time.Duration(len(hops)) * castDuration
+}
+
+// landings returns where a cast from p can land: the farthest valid position on every ray and the goal when it's in
+// range
+func landings(g *game.Grid, p, goal data.Position, radius int, opts Options) []data.Position {
+	var result []data.Position
+	if euclidean(p, goal) <= float64(radius) && clearLine(g, p, goal, opts.OverWalls) && canLand(opts, p, goal) {
+		result = append(result, goal)
+	}
+
+	seen := make(map[data.Position]bool, rays)
+	for i := 0; i < rays; i++ {
+		angle := 2 * math.Pi * float64(i) / rays
+		dx, dy := math.Cos(angle), math.Sin(angle)
+
+		var best data.Position
+		found := false
+		for step := 1; step <= radius; step++ {
+			x := p.X + int(math.Round(dx*float64(step)))
+			y := p.Y + int(math.Round(dy*float64(step)))
+			if x < 0 || y < 0 || x >= g.Width || y >= g.Height {
+				break
+			}
+			if g.CollisionGrid[y][x] == game.CollisionTypeNonWalkable && !opts.OverWalls {
+				break
+			}
+			to := data.Position{X: x, Y: y}
+			if walkable(g, x, y) && euclidean(p, to) <= float64(radius) && canLand(opts, p, to) {
+				best, found = to, true
+			}
+		}
+		if found && !seen[best] {
+			seen[best] = true
+			result = append(result, best)
+		}
+	}
+
+	return result
+}
+
+// clearLine checks there are no walls between both positions
+func clearLine(g *game.Grid, from, to data.Position, overWalls bool) bool {
+	if overWalls {
+		return true
+	}
+	steps := max(abs(to.X-from.X), abs(to.Y-from.Y))
+	for i := 1; i <= steps; i++ {
+		x := from.X + int(math.Round(float64((to.X-from.X)*i)/float64(steps)))
+		y := from.Y + int(math.Round(float64((to.Y-from.Y)*i)/float64(steps)))
+		if g.CollisionGrid[y][x] == game.CollisionTypeNonWalkable {
+			return false
+		}
+	}
+
+	return true
+}
+
+func buildHops(start, goal data.Position, from func(data.Position) data.Position) []Hop {
+	var hops []Hop
+	for p := goal; p != start; p = from(p) {
+		hops = append(hops, Hop{From: from(p), To: p})
+	}
+	for i, j := 0, len(hops)-1; i < j; i, j = i+1, j-1 {
+		hops[i], hops[j] = hops[j], hops[i]
+	}
+
+	return hops
+}
+
+func canLand(opts Options, from, to data.Position) bool {
+	return opts.CanLand == nil || opts.CanLand(from, to)
+}
+
+func walkable(g *game.Grid, x, y int) bool {
+	if x < 0 || y < 0 || x >= g.Width || y >= g.Height {
+		return false
+	}
+	ct := g.CollisionGrid[y][x]
+	return ct != game.CollisionTypeNonWalkable && ct != game.CollisionTypeTeleportOver
+}
+
+var fieldBuffers = sync.Pool{New: func() any { return &distanceField{} }}
+
+// distanceField has the distance in tiles from every position to the goal moving through the tiles a cast can cross,
+// -1 when the goal can't be reached. Without walls the distance is the straight one.
+type distanceField struct {
+	width     int
+	goal      data.Position
+	overWalls bool
+	dist      []int32
+	queue     []int32
+}
+
+func newDistanceField(g *game.Grid, goal data.Position, overWalls bool) *distanceField {
+	f := fieldBuffers.Get().(*distanceField)
+	f.width, f.goal, f.overWalls = g.Width, goal, overWalls
+	if overWalls {
+		return f
+	}
+
+	size := g.Width * g.Height
+	if cap(f.dist) < size {
+		f.dist = make([]int32, size)
+	}
+	f.dist = f.dist[:size]
+	for i := range f.dist {
+		f.dist[i] = -1
+	}
+
+	// BFS allowing corner cutting, so a straight cast line is never shorter than the distance in the field
+	f.queue = append(f.queue[:0], int32(goal.Y*g.Width+goal.X))
+	f.dist[goal.Y*g.Width+goal.X] = 0
+	for head := 0; head < len(f.queue); head++ {
+		i := int(f.queue[head])
+		x, y := i%g.Width, i/g.Width
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				nx, ny := x+dx, y+dy
+				if nx < 0 || ny < 0 || nx >= g.Width || ny >= g.Height || g.CollisionGrid[ny][nx] == game.CollisionTypeNonWalkable {
+					continue
+				}
+				if ni := ny*g.Width + nx; f.dist[ni] < 0 {
+					f.dist[ni] = f.dist[i] + 1
+					f.queue = append(f.queue, int32(ni))
+				}
+			}
+		}
+	}
+
+	return f
+}
+
+func (f *distanceField) at(p data.Position) int {
+	if f.overWalls {
+		return max(abs(p.X-f.goal.X), abs(p.Y-f.goal.Y))
+	}
+	return int(f.dist[p.Y*f.width+p.X])
+}
+
+func (f *distanceField) release() {
+	fieldBuffers.Put(f)
+}
+
+func euclidean(a, b data.Position) float64 {
+	return math.Hypot(float64(a.X-b.X), float64(a.Y-b.Y))
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+type hopNode struct {
+	position data.Position
+	casts    int
+	distance float64
+	priority float64
+	index    int
+}
+
+// hopQueue orders by estimated casts, then by distance flown
+type hopQueue []*hopNode
+
+func (q hopQueue) Len() int { return len(q) }
+func (q hopQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority < q[j].priority
+	}
+	return q[i].distance < q[j].distance
+}
+func (q hopQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+func (q *hopQueue) Push(x interface{}) {
+	n := x.(*hopNode)
+	n.index = len(*q)
+	*q = append(*q, n)
+}
+func (q *hopQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	node := old[n-1]
+	old[n-1] = nil
+	node.index = -1
+	*q = old[0 : n-1]
+	return node
+}
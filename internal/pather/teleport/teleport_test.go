@@ -0,0 +1,132 @@
+package teleport
+
+import (
+	"math"
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/koolo/internal/game"
+	"github.com/hectorgimenez/koolo/internal/pather/gridtest"
+)
+
+func TestPlanOpenField(t *testing.T) {
+	g := gridFromRows(make([]string, 10), 100)
+
+	hops, found := Plan(g, data.Position{X: 1, Y: 5}, data.Position{X: 91, Y: 5}, Options{Radius: 20})
+	if !found {
+		t.Fatal("Expected hops to be found")
+	}
+	// 90 units with 20 units per cast
+	if len(hops) != 5 {
+		t.Errorf("Expected 5 hops, got %d", len(hops))
+	}
+	checkHops(t, g, hops, data.Position{X: 1, Y: 5}, data.Position{X: 91, Y: 5}, 20)
+}
+
+func TestPlanGoesAroundWalls(t *testing.T) {
+	// Wall in the middle with a gap at the bottom
+	rows := make([]string, 40)
+	for y := range rows {
+		row := []byte(" ")
+		for x := 1; x < 40; x++ {
+			if x == 20 && y < 35 {
+				row = append(row, '#')
+			} else {
+				row = append(row, ' ')
+			}
+		}
+		rows[y] = string(row)
+	}
+	g := gridFromRows(rows, 40)
+	start, goal := data.Position{X: 10, Y: 2}, data.Position{X: 30, Y: 2}
+
+	hops, found := Plan(g, start, goal, Options{Radius: 20})
+	if !found {
+		t.Fatal("Expected hops to be found")
+	}
+	if len(hops) < 2 {
+		t.Errorf("Expected the hops to go around the wall, got %v", hops)
+	}
+	checkHops(t, g, hops, start, goal, 20)
+
+	if _, found = Plan(g, start, goal, Options{Radius: 20, OverWalls: true}); !found {
+		t.Error("Expected hops over the wall to be found")
+	}
+}
+
+func TestPlanHonorsCanLand(t *testing.T) {
+	g := gridFromRows(make([]string, 10), 100)
+	start, goal := data.Position{X: 1, Y: 5}, data.Position{X: 91, Y: 5}
+
+	hops, found := Plan(g, start, goal, Options{Radius: 20, CanLand: func(from, to data.Position) bool {
+		return euclidean(from, to) <= 10
+	}})
+	if !found {
+		t.Fatal("Expected hops to be found")
+	}
+	if len(hops) != 9 {
+		t.Errorf("Expected 9 hops, got %d", len(hops))
+	}
+	checkHops(t, g, hops, start, goal, 10)
+}
+
+func TestPlanDuranceOfHate(t *testing.T) {
+	g := gridtest.DuranceOfHate(t)
+	start, goal := data.Position{X: 336, Y: 701}, data.Position{X: 11, Y: 330}
+
+	hops, found := Plan(g, start, goal, Options{Radius: DefaultRadius})
+	if !found {
+		t.Fatal("Expected hops to be found")
+	}
+	checkHops(t, g, hops, start, goal, DefaultRadius)
+	if minHops := int(math.Ceil(euclidean(start, goal) / DefaultRadius)); len(hops) < minHops {
+		t.Errorf("Expected at least %d hops, got %d", minHops, len(hops))
+	}
+}
+
+func BenchmarkPlan(b *testing.B) {
+	g := gridtest.DuranceOfHate(b)
+	start, goal := data.Position{X: 336, Y: 701}, data.Position{X: 11, Y: 330}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Plan(g, start, goal, Options{Radius: DefaultRadius})
+	}
+}
+
+func checkHops(t *testing.T, g *game.Grid, hops []Hop, start, goal data.Position, radius int) {
+	t.Helper()
+
+	from := start
+	for _, h := range hops {
+		if h.From != from {
+			t.Fatalf("Hop %v doesn't start where the previous one landed", h)
+		}
+		if euclidean(h.From, h.To) > float64(radius) {
+			t.Errorf("Hop %v is longer than %d", h, radius)
+		}
+		if !walkable(g, h.To.X, h.To.Y) {
+			t.Errorf("Hop %v lands on a non walkable tile", h)
+		}
+		from = h.To
+	}
+	if from != goal {
+		t.Errorf("Expected hops to end at %v, ended at %v", goal, from)
+	}
+}
+
+// gridFromRows builds a walkable grid, # are walls
+func gridFromRows(rows []string, width int) *game.Grid {
+	cg := make([][]game.CollisionType, len(rows))
+	for y, row := range rows {
+		cg[y] = make([]game.CollisionType, width)
+		for x := range cg[y] {
+			cg[y][x] = game.CollisionTypeWalkable
+			if x < len(row) && row[x] == '#' {
+				cg[y][x] = game.CollisionTypeNonWalkable
+			}
+		}
+	}
+
+	return &game.Grid{Width: width, Height: len(rows), CollisionGrid: cg}
+}
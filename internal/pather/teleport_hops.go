@@ -0,0 +1,62 @@
+package pather
+
+import (
+	"math"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+	"github.com/hectorgimenez/koolo/internal/pather/teleport"
+)
+
+// GetTeleportHops plans the teleport casts from the player position to the destination, hops use absolute positions.
+// Every hop lands on a position visible on screen, so it can be clicked.
+func (pf *PathFinder) GetTeleportHops(to data.Position) ([]teleport.Hop, bool) {
+	base := pf.areaGrid(true)
+	if !pf.data.AreaData.IsInside(to) {
+		merged, err := pf.mergeGrids(to, true)
+		if err != nil {
+			return nil, false
+		}
+		base = merged
+	}
+	grid := base.grid
+
+	if !grid.IsWalkable(to) {
+		if walkableTo, found := pf.findNearbyWalkablePositionInGrid(grid, to); found {
+			to = walkableTo
+		}
+	}
+
+	gameAreaSizeX, gameAreaSizeY := pf.gr.GameAreaSize()
+	hudBoundary := int(float32(gameAreaSizeY) / 1.19)
+	hops, found := teleport.Plan(grid, grid.RelativePosition(pf.data.PlayerUnit.Position), grid.RelativePosition(to), teleport.Options{
+		Radius:    pf.teleportRadius(),
+		OverWalls: pf.data.PlayerUnit.Area == area.ArcaneSanctuary,
+		CanLand: func(from, to data.Position) bool {
+			screenX, screenY := pf.gameCoordsToScreenCords(from.X, from.Y, to.X, to.Y)
+			return screenX >= 0 && screenY >= 0 && screenX <= gameAreaSizeX && screenY <= hudBoundary
+		},
+	})
+	if !found {
+		return nil, false
+	}
+
+	for i := range hops {
+		hops[i].From = data.Position{X: hops[i].From.X + grid.OffsetX, Y: hops[i].From.Y + grid.OffsetY}
+		hops[i].To = data.Position{X: hops[i].To.X + grid.OffsetX, Y: hops[i].To.Y + grid.OffsetY}
+	}
+
+	return hops, true
+}
+
+// teleportRadius is the configured teleport distance or, when it's not set, the farthest a cast can reach clicking
+// inside the game area. Teleport range is bound by what can be clicked, not by the skill level.
+func (pf *PathFinder) teleportRadius() int {
+	if pf.cfg.Character.TeleportRadius > 0 {
+		return pf.cfg.Character.TeleportRadius
+	}
+
+	// The horizontal screen axis is the diagonal of the game grid, the longest distance on screen
+	gameAreaSizeX, _ := pf.gr.GameAreaSize()
+	return int(float64(gameAreaSizeX) / 2 / (19.8 * math.Sqrt2))
+}
@@ -9,12 +9,14 @@ import (
 	"github.com/hectorgimenez/d2go/pkg/data/area"
 	"github.com/hectorgimenez/d2go/pkg/data/object"
 	"github.com/hectorgimenez/koolo/internal/game"
+	"github.com/hectorgimenez/koolo/internal/pather/geom"
 	"github.com/hectorgimenez/koolo/internal/utils"
 )
 
 func (pf *PathFinder) RandomMovement() {
-	midGameX := pf.gr.GameAreaSizeX / 2
-	midGameY := pf.gr.GameAreaSizeY / 2
+	gameAreaSizeX, gameAreaSizeY := pf.gr.GameAreaSize()
+	midGameX := gameAreaSizeX / 2
+	midGameY := gameAreaSizeY / 2
 	x := midGameX + rand.Intn(midGameX) - (midGameX / 2)
 	y := midGameY + rand.Intn(midGameY) - (midGameY / 2)
 	pf.hid.MovePointer(x, y)
@@ -27,50 +29,7 @@ func (pf *PathFinder) DistanceFromMe(p data.Position) int {
 }
 
 func (pf *PathFinder) OptimizeRoomsTraverseOrder() []data.Room {
-	distanceMatrix := make(map[data.Room]map[data.Room]int)
-
-	for _, room1 := range pf.data.Rooms {
-		distanceMatrix[room1] = make(map[data.Room]int)
-		for _, room2 := range pf.data.Rooms {
-			if room1 != room2 {
-				distance := DistanceFromPoint(room1.GetCenter(), room2.GetCenter())
-				distanceMatrix[room1][room2] = distance
-			} else {
-				distanceMatrix[room1][room2] = 0
-			}
-		}
-	}
-
-	currentRoom := data.Room{}
-	for _, r := range pf.data.Rooms {
-		if r.IsInside(pf.data.PlayerUnit.Position) {
-			currentRoom = r
-		}
-	}
-
-	visited := make(map[data.Room]bool)
-	order := []data.Room{currentRoom}
-	visited[currentRoom] = true
-
-	for len(order) < len(pf.data.Rooms) {
-		nextRoom := data.Room{}
-		minDistance := math.MaxInt
-
-		// Find the nearest unvisited room
-		for _, room := range pf.data.Rooms {
-			if !visited[room] && distanceMatrix[currentRoom][room] < minDistance {
-				nextRoom = room
-				minDistance = distanceMatrix[currentRoom][room]
-			}
-		}
-
-		// Add the next room to the order of visit
-		order = append(order, nextRoom)
-		visited[nextRoom] = true
-		currentRoom = nextRoom
-	}
-
-	return order
+	return pf.ExploreRooms(nil)
 }
 
 func (pf *PathFinder) MoveThroughPath(p Path, walkDuration time.Duration) {
@@ -84,6 +43,7 @@ func (pf *PathFinder) MoveThroughPath(p Path, walkDuration time.Duration) {
 func (pf *PathFinder) moveThroughPathWalk(p Path, walkDuration time.Duration) {
 	// Calculate the max distance we can walk in the given duration
 	maxDistance := int(float64(25) * walkDuration.Seconds())
+	gameAreaSizeX, gameAreaSizeY := pf.gr.GameAreaSize()
 
 	// Let's try to calculate how close to the window border we can go
 	screenCords := data.Position{}
@@ -96,12 +56,12 @@ func (pf *PathFinder) moveThroughPathWalk(p Path, walkDuration time.Duration) {
 		}
 
 		// Prevent mouse overlap the HUD
-		if screenY > int(float32(pf.gr.GameAreaSizeY)/1.19) {
+		if screenY > int(float32(gameAreaSizeY)/1.19) {
 			break
 		}
 
 		// We are getting out of the window, let's stop
-		if screenX < 0 || screenY < 0 || screenX > pf.gr.GameAreaSizeX || screenY > pf.gr.GameAreaSizeY {
+		if screenX < 0 || screenY < 0 || screenX > gameAreaSizeX || screenY > gameAreaSizeY {
 			break
 		}
 		screenCords = data.Position{X: screenX, Y: screenY}
@@ -111,7 +71,8 @@ func (pf *PathFinder) moveThroughPathWalk(p Path, walkDuration time.Duration) {
 }
 
 func (pf *PathFinder) moveThroughPathTeleport(p Path) {
-	hudBoundary := int(float32(pf.gr.GameAreaSizeY) / 1.19)
+	gameAreaSizeX, gameAreaSizeY := pf.gr.GameAreaSize()
+	hudBoundary := int(float32(gameAreaSizeY) / 1.19)
 	fromX, fromY := p.From().X, p.From().Y
 
 	for i := len(p) - 1; i >= 0; i-- {
@@ -124,7 +85,7 @@ func (pf *PathFinder) moveThroughPathTeleport(p Path) {
 		}
 
 		// Check if coordinates are within screen bounds
-		if screenX >= 0 && screenY >= 0 && screenX <= pf.gr.GameAreaSizeX && screenY <= pf.gr.GameAreaSizeY {
+		if screenX >= 0 && screenY >= 0 && screenX <= gameAreaSizeX && screenY <= gameAreaSizeY {
 			pf.MoveCharacter(screenX, screenY)
 			return
 		}
@@ -132,7 +93,8 @@ func (pf *PathFinder) moveThroughPathTeleport(p Path) {
 }
 
 func (pf *PathFinder) GetLastPathIndexOnScreen(p Path) int {
-	hudBoundary := int(float32(pf.gr.GameAreaSizeY) / 1.19)
+	gameAreaSizeX, gameAreaSizeY := pf.gr.GameAreaSize()
+	hudBoundary := int(float32(gameAreaSizeY) / 1.19)
 	fromX, fromY := p.From().X, p.From().Y
 
 	for i := len(p) - 1; i >= 0; i-- {
@@ -145,7 +107,7 @@ func (pf *PathFinder) GetLastPathIndexOnScreen(p Path) int {
 		}
 
 		// Check if coordinates are within screen bounds
-		if screenX >= 0 && screenY >= 0 && screenX <= pf.gr.GameAreaSizeX && screenY <= pf.gr.GameAreaSizeY {
+		if screenX >= 0 && screenY >= 0 && screenX <= gameAreaSizeX && screenY <= gameAreaSizeY {
 			return i
 		}
 	}
@@ -174,8 +136,9 @@ func (pf *PathFinder) gameCoordsToScreenCords(playerX, playerY, destinationX, de
 
 	// Transform cartesian movement (World) to isometric (screen)
 	// Helpful documentation: https://clintbellanger.net/articles/isometric_math/
-	screenX := int((float32(diffX-diffY) * 19.8) + float32(pf.gr.GameAreaSizeX/2))
-	screenY := int((float32(diffX+diffY) * 9.9) + float32(pf.gr.GameAreaSizeY/2))
+	gameAreaSizeX, gameAreaSizeY := pf.gr.GameAreaSize()
+	screenX := int((float32(diffX-diffY) * 19.8) + float32(gameAreaSizeX/2))
+	screenY := int((float32(diffX+diffY) * 9.9) + float32(gameAreaSizeY/2))
 
 	return screenX, screenY
 }
@@ -197,40 +160,7 @@ func DistanceFromPoint(from data.Position, to data.Position) int {
 }
 
 func (pf *PathFinder) LineOfSight(origin data.Position, destination data.Position) bool {
-	dx := int(math.Abs(float64(destination.X - origin.X)))
-	dy := int(math.Abs(float64(destination.Y - origin.Y)))
-	sx, sy := 1, 1
-
-	if origin.X > destination.X {
-		sx = -1
-	}
-	if origin.Y > destination.Y {
-		sy = -1
-	}
-
-	err := dx - dy
-
-	x, y := origin.X, origin.Y
-
-	for {
-		if !pf.data.AreaData.Grid.IsWalkable(data.Position{X: x, Y: y}) {
-			return false
-		}
-		if x == destination.X && y == destination.Y {
-			break
-		}
-		e2 := 2 * err
-		if e2 > -dy {
-			err -= dy
-			x += sx
-		}
-		if e2 < dx {
-			err += dx
-			y += sy
-		}
-	}
-
-	return true
+	return geom.LineOfSight(pf.data.AreaData.Grid, origin, destination)
 }
 
 func (pf *PathFinder) HasDoorBetween(origin data.Position, destination data.Position) (bool, *data.Object) {
@@ -0,0 +1,255 @@
+package pickit
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	d2nip "github.com/hectorgimenez/d2go/pkg/nip"
+	"github.com/hectorgimenez/koolo/internal/pickit/nip"
+)
+
+// Properties allowed before the first #, the stats section takes any stat alias
+var itemProperties = map[string]bool{
+	"type": true, "quality": true, "class": true, "name": true, "flag": true, "color": true, "prefix": true, "suffix": true,
+}
+
+// Properties allowed after the second #
+var ruleProperties = map[string]bool{"maxquantity": true, "tier": true, "merctier": true}
+
+type parsedRule struct {
+	rule PickitRule
+	line *nip.Line
+	tier bool
+	// [name] or [type] the rule requires, empty when it doesn't
+	classProperty, classValue string
+}
+
+// DetectConflicts checks the rules one by one (syntax, unknown properties and names, contradictions) and against
+// each other (duplicates, subsumed rules, tier overlaps and max quantities). Rules are expected in the order the bot
+// loads them, disabled ones are skipped.
+func DetectConflicts(rules []PickitRule) []ConflictDetection {
+	conflicts := []ConflictDetection{}
+
+	var parsed []parsedRule
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+		l, err := nip.Parse(rule.GeneratedNIP)
+		if err != nil {
+			conflicts = append(conflicts, newConflict("invalid", "error",
+				fmt.Sprintf("Syntax error: %v", err),
+				"Fix the syntax, the bot fails to load the rule", rule))
+			continue
+		}
+		if !l.IsRule() {
+			continue
+		}
+
+		conflicts = append(conflicts, ruleConflicts(rule, l)...)
+		r := parsedRule{rule: rule, line: l, tier: nip.HasProperty(l, "tier", "merctier")}
+		r.classProperty, r.classValue, _ = itemClass(l)
+		parsed = append(parsed, r)
+	}
+
+	conflicts = append(conflicts, overlapConflicts(parsed)...)
+	conflicts = append(conflicts, maxQuantityConflicts(parsed)...)
+
+	return conflicts
+}
+
+// ruleConflicts returns the problems of a single rule
+func ruleConflicts(rule PickitRule, l *nip.Line) []ConflictDetection {
+	var conflicts []ConflictDetection
+
+	for _, property := range unknownProperties(l) {
+		conflicts = append(conflicts, newConflict("unknown_property", "error",
+			fmt.Sprintf("Unknown property [%s] in %s", property, rule.ID),
+			"Check the spelling, stats only go between the first and the second #", rule))
+	}
+
+	for _, name := range unknownNames(l) {
+		conflicts = append(conflicts, newConflict("unknown_name", "error",
+			fmt.Sprintf("Unknown item name '%s' in %s, the rule never matches", name, rule.ID),
+			"Use the item name without spaces, like berrune or shako", rule))
+	}
+
+	if property, found := nip.Contradiction(l); found {
+		conflicts = append(conflicts, newConflict("contradiction", "error",
+			fmt.Sprintf("Conditions on [%s] in %s can't be true at the same time, the rule never matches", property, rule.ID),
+			"Fix the ranges or use || between the conditions", rule))
+	}
+
+	return conflicts
+}
+
+// overlapConflicts compares every rule with the ones after it
+func overlapConflicts(rules []parsedRule) []ConflictDetection {
+	var conflicts []ConflictDetection
+
+	for i, a := range rules {
+		for _, b := range rules[i+1:] {
+			// Different items, most of the pairs are discarded here
+			if a.classProperty == b.classProperty && a.classValue != b.classValue {
+				continue
+			}
+
+			switch {
+			case a.tier && b.tier:
+				continue
+			case a.tier || b.tier:
+				tier, keep := a, b
+				if b.tier {
+					tier, keep = b, a
+				}
+				// A [name] can't be compared with a [type]
+				if tier.classProperty != keep.classProperty || nip.Disjoint(tier.line, keep.line) {
+					continue
+				}
+				conflicts = append(conflicts, newConflict("tier_overlap", "warning",
+					fmt.Sprintf("Items matching %s and %s are handled as tier items, kept only when they are better than the equipped ones", tier.rule.ID, keep.rule.ID),
+					"Make the rules exclusive, for example with different [quality] conditions", tier.rule, keep.rule))
+			case nip.Subsumes(a.line, b.line) && nip.Subsumes(b.line, a.line):
+				conflicts = append(conflicts, newConflict("duplicate", "warning",
+					fmt.Sprintf("%s and %s match the same items, %s is never used", a.rule.ID, b.rule.ID, b.rule.ID),
+					"Remove one of the rules", a.rule, b.rule))
+			case nip.Subsumes(a.line, b.line):
+				conflicts = append(conflicts, newConflict("subsumed", "warning",
+					fmt.Sprintf("%s matches everything %s matches and goes first, %s is never used", a.rule.ID, b.rule.ID, b.rule.ID),
+					"Remove the later rule or move it before the broader one", a.rule, b.rule))
+			case nip.Subsumes(b.line, a.line):
+				conflicts = append(conflicts, newConflict("subsumed", "warning",
+					fmt.Sprintf("%s matches everything %s matches", b.rule.ID, a.rule.ID),
+					"Remove the narrower rule unless it's there for its max quantity", b.rule, a.rule))
+			}
+		}
+	}
+
+	return conflicts
+}
+
+// maxQuantityConflicts finds item classes with max quantities in several overlapping rules, every rule counts the
+// stashed items it matches on its own
+func maxQuantityConflicts(rules []parsedRule) []ConflictDetection {
+	var conflicts []ConflictDetection
+
+	groups := make(map[string][]parsedRule)
+	var classes []string
+	for _, r := range rules {
+		if !nip.HasProperty(r.line, "maxquantity") {
+			continue
+		}
+		if r.classProperty == "" {
+			continue
+		}
+		class := fmt.Sprintf("[%s] == %s", r.classProperty, r.classValue)
+		if _, found := groups[class]; !found {
+			classes = append(classes, class)
+		}
+		groups[class] = append(groups[class], r)
+	}
+
+	for _, class := range classes {
+		var overlapping []PickitRule
+		group := groups[class]
+		for i, a := range group {
+			for j, b := range group {
+				if i != j && !nip.Disjoint(a.line, b.line) {
+					overlapping = append(overlapping, a.rule)
+					break
+				}
+			}
+		}
+		if len(overlapping) < 2 {
+			continue
+		}
+
+		conflicts = append(conflicts, newConflict("maxquantity", "warning",
+			fmt.Sprintf("Max quantity for %s is set in %d rules, each one counts the stashed items it matches", class, len(overlapping)),
+			"Keep the max quantity in a single rule", overlapping...))
+	}
+
+	return conflicts
+}
+
+// itemClass returns the [name] or [type] the rule requires, [name] goes first
+func itemClass(l *nip.Line) (string, string, bool) {
+	var property, value string
+	for _, c := range nip.Conjuncts(l.Section(0)) {
+		b, ok := c.(*nip.BinaryExpr)
+		if !ok || b.Op.Text != "==" {
+			continue
+		}
+		p, ok := b.X.(*nip.PropertyExpr)
+		if !ok || (p.Name() != "name" && p.Name() != "type") || property == "name" {
+			continue
+		}
+		if v, ok := b.Y.(*nip.IdentLit); ok {
+			property, value = p.Name(), v.Name()
+		}
+	}
+
+	return property, value, property != ""
+}
+
+func unknownProperties(l *nip.Line) []string {
+	var unknown []string
+	for i, s := range l.Sections {
+		nip.Walk(s.Expr, func(n nip.Node) {
+			p, ok := n.(*nip.PropertyExpr)
+			if !ok {
+				return
+			}
+			known := false
+			switch i {
+			case 0:
+				known = itemProperties[p.Name()]
+			case 1:
+				_, known = d2nip.StatAliases[p.Name()]
+			default:
+				known = ruleProperties[p.Name()]
+			}
+			if !known && !slices.Contains(unknown, p.Name()) {
+				unknown = append(unknown, p.Name())
+			}
+		})
+	}
+
+	return unknown
+}
+
+// unknownNames returns the [name] values without an item, they don't make the rule invalid but it never matches
+func unknownNames(l *nip.Line) []string {
+	var unknown []string
+	nip.Walk(l.Section(0), func(n nip.Node) {
+		b, ok := n.(*nip.BinaryExpr)
+		if !ok || b.Op.Kind != nip.Compare {
+			return
+		}
+		p, ok := b.X.(*nip.PropertyExpr)
+		if !ok || p.Name() != "name" {
+			return
+		}
+		if v, ok := b.Y.(*nip.IdentLit); ok && item.GetIDByName(v.Name()) < 0 {
+			unknown = append(unknown, v.Tok.Text)
+		}
+	})
+
+	return unknown
+}
+
+func newConflict(conflictType, severity, description, suggestion string, rules ...PickitRule) ConflictDetection {
+	c := ConflictDetection{
+		Type:        conflictType,
+		Severity:    severity,
+		Description: description,
+		Suggestion:  suggestion,
+	}
+	for _, r := range rules {
+		c.Rules = append(c.Rules, r.ID)
+		c.Lines = append(c.Lines, r.GeneratedNIP)
+	}
+
+	return c
+}
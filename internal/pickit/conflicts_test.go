@@ -0,0 +1,100 @@
+package pickit
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDetectConflicts(t *testing.T) {
+	tests := []struct {
+		name     string
+		lines    []string
+		expected []string // Type and rules of every conflict, like "subsumed 1,2"
+	}{
+		{
+			name:     "no conflicts",
+			lines:    []string{"[name] == berrune", "[name] == ring && [quality] == unique", "[type] == armor && [flag] == ethereal && [flag] == runeword"},
+			expected: nil,
+		},
+		{
+			name:     "syntax error",
+			lines:    []string{"[name] == berrune &&"},
+			expected: []string{"invalid 1"},
+		},
+		{
+			name:     "unknown property and name",
+			lines:    []string{"[name] == shakko # [fcrr] >= 10"},
+			expected: []string{"unknown_property 1", "unknown_name 1"},
+		},
+		{
+			name:     "contradiction",
+			lines:    []string{"[name] == ring # [fcr] >= 10 && [fcr] < 5"},
+			expected: []string{"contradiction 1"},
+		},
+		{
+			name:     "duplicate",
+			lines:    []string{"[name] == berrune", "[name] == berrune"},
+			expected: []string{"duplicate 1,2"},
+		},
+		{
+			name:     "later rule never used",
+			lines:    []string{"[name] == ring", "[name] == ring && [quality] == unique"},
+			expected: []string{"subsumed 1,2"},
+		},
+		{
+			name:     "narrower rule first",
+			lines:    []string{"[name] == ring && [quality] == unique", "[name] == ring"},
+			expected: []string{"subsumed 2,1"},
+		},
+		{
+			name:     "tier overlap",
+			lines:    []string{"[name] == ring && [quality] == unique", "[name] == ring # # [tier] == 5"},
+			expected: []string{"tier_overlap 2,1"},
+		},
+		{
+			name:     "exclusive tier rule",
+			lines:    []string{"[name] == ring && [quality] == unique", "[name] == ring && [quality] == rare # # [tier] == 5"},
+			expected: nil,
+		},
+		{
+			name:     "max quantity in several rules",
+			lines:    []string{"[name] == ring && [quality] == unique # # [maxquantity] == 2", "[name] == ring && [quality] >= rare # [fcr] >= 10 # [maxquantity] == 5"},
+			expected: []string{"maxquantity 1,2"},
+		},
+		{
+			name:     "different items",
+			lines:    []string{"[name] == berrune # # [maxquantity] == 2", "[name] == jahrune # # [maxquantity] == 2"},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rules []PickitRule
+			for i, line := range tt.lines {
+				rules = append(rules, PickitRule{ID: fmt.Sprint(i + 1), GeneratedNIP: line, Enabled: true})
+			}
+
+			var got []string
+			for _, c := range DetectConflicts(rules) {
+				got = append(got, fmt.Sprintf("%s %s", c.Type, strings.Join(c.Rules, ",")))
+			}
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestDetectConflictsSkipsDisabledRules(t *testing.T) {
+	conflicts := DetectConflicts([]PickitRule{
+		{ID: "1", GeneratedNIP: "[name] == berrune", Enabled: true},
+		{ID: "2", GeneratedNIP: "[name] == berrune", Enabled: false},
+		{ID: "3", GeneratedNIP: "[name] == berrune &&", Enabled: false},
+	})
+	if len(conflicts) != 0 {
+		t.Errorf("expected disabled rules to be skipped, got %+v", conflicts)
+	}
+}
@@ -0,0 +1,106 @@
+package pickit
+
+import (
+	"github.com/hectorgimenez/d2go/pkg/data"
+	d2nip "github.com/hectorgimenez/d2go/pkg/nip"
+	"github.com/hectorgimenez/koolo/internal/pickit/nip"
+)
+
+// RuleCoverage is how many historical drops a rule matched
+type RuleCoverage struct {
+	Location   string `json:"location"`   // file:line
+	Rule       string `json:"rule"`       // NIP line
+	Matched    int    `json:"matched"`    // Drops matching the rule
+	Fired      int    `json:"fired"`      // Drops decided by the rule, earlier rules go first
+	ShadowedBy string `json:"shadowedBy"` // file:line of an earlier rule matching everything this one matches
+}
+
+// StaleDrop is a stashed item that doesn't match the rules anymore
+type StaleDrop struct {
+	Item     SimulatedItem `json:"item"`
+	Rule     string        `json:"rule"`     // Rule that stashed it
+	RuleFile string        `json:"ruleFile"` // file:line of that rule when it was stashed
+}
+
+// CoverageReport tells which rules are useful according to the drops stashed so far
+type CoverageReport struct {
+	Drops        int            `json:"drops"`
+	Rules        []RuleCoverage `json:"rules"`
+	NeverMatched []RuleCoverage `json:"neverMatched"`
+	Shadowed     []RuleCoverage `json:"shadowed"`
+	StaleDrops   []StaleDrop    `json:"staleDrops"`
+}
+
+// Coverage runs the drops through the rules, counting the matches of every rule
+func (s *Simulator) Coverage(drops []data.Drop) CoverageReport {
+	report := CoverageReport{
+		Drops:        len(drops),
+		Rules:        make([]RuleCoverage, len(s.rules)),
+		NeverMatched: []RuleCoverage{},
+		Shadowed:     []RuleCoverage{},
+		StaleDrops:   []StaleDrop{},
+	}
+
+	byLocation := make(map[string]int, len(s.rules))
+	for i, rule := range s.rules {
+		report.Rules[i] = RuleCoverage{Location: ruleLocation(rule), Rule: rule.RawLine}
+		byLocation[report.Rules[i].Location] = i
+	}
+
+	for _, drop := range drops {
+		for i, rule := range s.rules {
+			if res, err := rule.Evaluate(drop.Item); err == nil && res == d2nip.RuleResultFullMatch {
+				report.Rules[i].Matched++
+			}
+		}
+
+		result := s.Evaluate(drop.Item, "droplog")
+		if i, found := byLocation[result.RuleFile]; found && result.Outcome != OutcomeIgnore {
+			report.Rules[i].Fired++
+		}
+		if result.Outcome == OutcomeIgnore || result.AfterIdentify == OutcomeIgnore {
+			report.StaleDrops = append(report.StaleDrops, StaleDrop{Item: result, Rule: drop.Rule, RuleFile: drop.RuleFile})
+		}
+	}
+
+	for i, shadowedBy := range s.shadowedRules() {
+		if shadowedBy >= 0 {
+			report.Rules[i].ShadowedBy = report.Rules[shadowedBy].Location
+			report.Shadowed = append(report.Shadowed, report.Rules[i])
+		}
+		if report.Rules[i].Matched == 0 {
+			report.NeverMatched = append(report.NeverMatched, report.Rules[i])
+		}
+	}
+
+	return report
+}
+
+// shadowedRules returns for every rule the index of the first enabled rule before it matching everything it matches,
+// -1 when there isn't any. Tier rules are evaluated apart so they don't shadow the others.
+func (s *Simulator) shadowedRules() []int {
+	lines := make([]*nip.Line, len(s.rules))
+	candidates := make([]bool, len(s.rules))
+	for i, rule := range s.rules {
+		if l, err := nip.Parse(rule.RawLine); err == nil && l.IsRule() {
+			lines[i] = l
+			candidates[i] = rule.Enabled && !nip.HasProperty(l, "tier", "merctier")
+		}
+	}
+
+	shadowedBy := make([]int, len(s.rules))
+	for i := range s.rules {
+		shadowedBy[i] = -1
+		if lines[i] == nil {
+			continue
+		}
+		for j := 0; j < i; j++ {
+			if candidates[j] && nip.Subsumes(lines[j], lines[i]) {
+				shadowedBy[i] = j
+				break
+			}
+		}
+	}
+
+	return shadowedBy
+}
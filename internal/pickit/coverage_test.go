@@ -0,0 +1,139 @@
+package pickit
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
+)
+
+// testItem returns an item of the given base name, like Ring or JahRune
+func testItem(t *testing.T, name string, quality item.Quality, identified bool, stats ...stat.Data) data.Item {
+	t.Helper()
+
+	id := item.GetIDByName(name)
+	if id < 0 {
+		t.Fatalf("unknown item %s", name)
+	}
+
+	return data.Item{
+		ID:         id,
+		Name:       item.Name(item.GetNameByEnum(uint(id))),
+		Quality:    quality,
+		Identified: identified,
+		Stats:      stats,
+	}
+}
+
+func TestCoverage(t *testing.T) {
+	s, err := NewSimulatorFromLines([]string{
+		"[name] == ring && [quality] == unique",
+		"[name] == ring && [quality] == unique # [maxhp] >= 30",
+		"[name] == amulet && [quality] == rare",
+		"[name] == jahrune",
+	}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := s.Coverage([]data.Drop{
+		{Item: testItem(t, "Ring", item.QualityUnique, true)},
+		{Item: testItem(t, "JahRune", item.QualityNormal, true)},
+		{Item: testItem(t, "JahRune", item.QualityNormal, true)},
+		// Stashed by a rule removed since then
+		{Item: testItem(t, "Ring", item.QualityMagic, true), Rule: "[name] == ring", RuleFile: "old.nip:3"},
+	})
+
+	if report.Drops != 4 {
+		t.Errorf("expected 4 drops, got %d", report.Drops)
+	}
+	for i, expected := range []struct{ matched, fired int }{{1, 1}, {0, 0}, {0, 0}, {2, 2}} {
+		if r := report.Rules[i]; r.Matched != expected.matched || r.Fired != expected.fired {
+			t.Errorf("rule %s: expected %d matched and %d fired, got %d and %d", r.Location, expected.matched, expected.fired, r.Matched, r.Fired)
+		}
+	}
+
+	var neverMatched []string
+	for _, r := range report.NeverMatched {
+		neverMatched = append(neverMatched, r.Location)
+	}
+	if !slices.Equal(neverMatched, []string{"simulation:2", "simulation:3"}) {
+		t.Errorf("unexpected never matched rules %v", neverMatched)
+	}
+
+	if len(report.Shadowed) != 1 || report.Shadowed[0].Location != "simulation:2" || report.Shadowed[0].ShadowedBy != "simulation:1" {
+		t.Errorf("expected the ring rule with stats to be shadowed by the first one, got %+v", report.Shadowed)
+	}
+
+	if len(report.StaleDrops) != 1 || report.StaleDrops[0].RuleFile != "old.nip:3" || report.StaleDrops[0].Item.Outcome != OutcomeIgnore {
+		t.Errorf("expected the magic ring to be stale, got %+v", report.StaleDrops)
+	}
+}
+
+func TestShadowedRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		lines    []string
+		expected []int
+	}{
+		{
+			name:     "broader rule first",
+			lines:    []string{"[name] == ring", "[name] == ring && [quality] == unique"},
+			expected: []int{-1, 0},
+		},
+		{
+			name:     "narrower rule first",
+			lines:    []string{"[name] == ring && [quality] == unique", "[name] == ring"},
+			expected: []int{-1, -1},
+		},
+		{
+			name:     "duplicates are shadowed by the first one",
+			lines:    []string{"[name] == berrune", "[name] == berrune", "[name] == berrune"},
+			expected: []int{-1, 0, 0},
+		},
+		{
+			name:     "different items",
+			lines:    []string{"[name] == berrune", "[name] == jahrune"},
+			expected: []int{-1, -1},
+		},
+		{
+			name:     "tier rules don't shadow",
+			lines:    []string{"[name] == ring # # [tier] == 5", "[name] == ring && [quality] == unique"},
+			expected: []int{-1, -1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewSimulatorFromLines(tt.lines, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if shadowedBy := s.shadowedRules(); !slices.Equal(shadowedBy, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, shadowedBy)
+			}
+		})
+	}
+}
+
+func TestNewSimulatorFromDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "general.nip"), []byte("[name] == jahrune\n[type] == ring # # [tier] == 5\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := NewSimulatorFromDir(dir, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.rules) != 2 || !slices.Equal(s.tierRules, []int{1}) {
+		t.Fatalf("expected 2 rules with the second one as tier rule, got %d rules and tier rules %v", len(s.rules), s.tierRules)
+	}
+	if result := s.Evaluate(testItem(t, "JahRune", item.QualityNormal, true), "test"); result.Outcome != OutcomeStash {
+		t.Errorf("expected the jah rune to be stashed, got %+v", result)
+	}
+}
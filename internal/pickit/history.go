@@ -0,0 +1,468 @@
+package pickit
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Larger diffs are shown as the old lines removed and the new ones added
+const maxDiffCells = 4_000_000
+
+// Writes of every pickit directory go through the same lock, the editor handlers run concurrently
+var historyMu sync.Mutex
+
+// Revision is a version of a pickit file. The first revision of a file is the content it had before the editor
+// touched it, so it can always be rolled back.
+type Revision struct {
+	ID        int       `json:"id"`
+	File      string    `json:"file"`
+	Author    string    `json:"author"`
+	Message   string    `json:"message"`
+	Timestamp time.Time `json:"timestamp"`
+	Hash      string    `json:"hash,omitempty"`    // SHA-256 of the content, the name of its snapshot
+	Content   string    `json:"content,omitempty"` // Only loaded for a single revision
+	Deleted   bool      `json:"deleted,omitempty"` // The file didn't exist
+}
+
+// DiffLine is a line of a diff, Op is "=" for unchanged lines, "-" for removed and "+" for added ones
+type DiffLine struct {
+	Op      string `json:"op"`
+	Text    string `json:"text"`
+	OldLine int    `json:"oldLine,omitempty"`
+	NewLine int    `json:"newLine,omitempty"`
+}
+
+// History keeps every version of the files of a pickit directory. The revisions are listed next to it in
+// <dir>_history.jsonl, their content is stored once per distinct version in <dir>_history, named by its hash.
+type History struct {
+	dir       string
+	path      string
+	snapshots string
+}
+
+// NewHistory creates the history of a pickit directory
+func NewHistory(pickitDir string) *History {
+	dir := filepath.Clean(pickitDir)
+
+	return &History{dir: dir, path: dir + "_history.jsonl", snapshots: dir + "_history"}
+}
+
+// WriteFile writes the file and records it as a new revision, nothing is recorded when the content doesn't change
+func (h *History) WriteFile(file, content, author, message string) (Revision, error) {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	revisions, err := h.read()
+	if err != nil {
+		return Revision{}, err
+	}
+
+	return h.write(&revisions, file, content, false, author, message)
+}
+
+// Revisions returns the revisions of the file, of every file when it's empty, newest first and without content
+func (h *History) Revisions(file string) ([]Revision, error) {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	revisions, err := h.read()
+	if err != nil {
+		return nil, err
+	}
+
+	result := []Revision{}
+	for _, rev := range slices.Backward(revisions) {
+		if file == "" || rev.File == file {
+			result = append(result, rev)
+		}
+	}
+
+	return result, nil
+}
+
+// Revision returns a revision with its content
+func (h *History) Revision(id int) (Revision, error) {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	revisions, err := h.read()
+	if err != nil {
+		return Revision{}, err
+	}
+	rev, err := findRevision(revisions, id)
+	if err != nil {
+		return Revision{}, err
+	}
+	rev.Content, err = h.content(rev)
+
+	return rev, err
+}
+
+// Diff compares the content of two revisions line by line
+func (h *History) Diff(from, to int) ([]DiffLine, error) {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	revisions, err := h.read()
+	if err != nil {
+		return nil, err
+	}
+	a, err := findRevision(revisions, from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := findRevision(revisions, to)
+	if err != nil {
+		return nil, err
+	}
+	oldContent, err := h.content(a)
+	if err != nil {
+		return nil, err
+	}
+	newContent, err := h.content(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return DiffLines(oldContent, newContent), nil
+}
+
+// Rollback restores the file as it was at the revision, every file of the directory when file is empty. Each restored
+// file gets a new revision so the rollback can be undone as well.
+func (h *History) Rollback(file string, id int, author string) ([]Revision, error) {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	revisions, err := h.read()
+	if err != nil {
+		return nil, err
+	}
+	if _, err = findRevision(revisions, id); err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, rev := range revisions {
+		if (file == "" || rev.File == file) && !slices.Contains(files, rev.File) {
+			files = append(files, rev.File)
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no revisions for %s", file)
+	}
+
+	restored := []Revision{}
+	for _, f := range files {
+		state := stateAt(revisions, f, id)
+		current, err := h.current(f)
+		if err != nil {
+			return restored, err
+		}
+		if current.Deleted == state.Deleted && current.Hash == state.Hash {
+			continue
+		}
+		content, err := h.content(state)
+		if err != nil {
+			return restored, err
+		}
+
+		rev, err := h.write(&revisions, f, content, state.Deleted, author, fmt.Sprintf("Rollback to revision %d", id))
+		if err != nil {
+			return restored, err
+		}
+		restored = append(restored, rev)
+	}
+
+	return restored, nil
+}
+
+// write changes the file and appends the revision, recording first the content on disk when it's not the last
+// revision: the file isn't tracked yet or it was changed outside the editor
+func (h *History) write(revisions *[]Revision, file, content string, deleted bool, author, message string) (Revision, error) {
+	if file == "" || filepath.Base(file) != file {
+		return Revision{}, fmt.Errorf("invalid file name %q", file)
+	}
+
+	current, err := h.current(file)
+	if err != nil {
+		return Revision{}, err
+	}
+	last, found := lastRevision(*revisions, file)
+	tracked := found && last.Deleted == current.Deleted && last.Hash == current.Hash
+	if tracked && current.Deleted == deleted && current.Content == content {
+		return last, nil
+	}
+	if !tracked {
+		current.Message = "Version before editing"
+		if err = h.append(revisions, current); err != nil {
+			return Revision{}, err
+		}
+	}
+
+	path := filepath.Join(h.dir, file)
+	if deleted {
+		err = os.Remove(path)
+	} else {
+		if err = os.MkdirAll(h.dir, 0755); err == nil {
+			err = os.WriteFile(path, []byte(content), 0644)
+		}
+	}
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return Revision{}, fmt.Errorf("failed to write pickit file: %w", err)
+	}
+
+	rev := Revision{
+		File:      file,
+		Author:    author,
+		Message:   message,
+		Timestamp: time.Now(),
+		Content:   content,
+		Deleted:   deleted,
+	}
+	if err = h.append(revisions, rev); err != nil {
+		return Revision{}, err
+	}
+
+	return (*revisions)[len(*revisions)-1], nil
+}
+
+// current returns the file as it is on disk
+func (h *History) current(file string) (Revision, error) {
+	rev := Revision{File: file, Timestamp: time.Now()}
+	content, err := os.ReadFile(filepath.Join(h.dir, file))
+	if errors.Is(err, os.ErrNotExist) {
+		rev.Deleted = true
+		return rev, nil
+	}
+	if err != nil {
+		return rev, fmt.Errorf("failed to read pickit file: %w", err)
+	}
+	rev.Content = string(content)
+	rev.Hash = contentHash(rev.Content)
+
+	return rev, nil
+}
+
+// content returns the content of the revision from its snapshot
+func (h *History) content(rev Revision) (string, error) {
+	if rev.Deleted {
+		return "", nil
+	}
+	content, err := os.ReadFile(filepath.Join(h.snapshots, rev.Hash))
+	if err != nil {
+		return "", fmt.Errorf("failed to read revision %d of %s: %w", rev.ID, rev.File, err)
+	}
+
+	return string(content), nil
+}
+
+// saveSnapshot stores the content unless a revision with the same content already did
+func (h *History) saveSnapshot(hash, content string) error {
+	path := filepath.Join(h.snapshots, hash)
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(h.snapshots, 0755); err != nil {
+		return fmt.Errorf("failed to create pickit history: %w", err)
+	}
+
+	// Written apart first, a partial snapshot would be taken for a complete one by the next revisions
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write pickit history: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write pickit history: %w", err)
+	}
+
+	return nil
+}
+
+func (h *History) read() ([]Revision, error) {
+	f, err := os.Open(h.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pickit history: %w", err)
+	}
+	defer f.Close()
+
+	var revisions []Revision
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			var rev Revision
+			if err := json.Unmarshal(line, &rev); err != nil {
+				return nil, fmt.Errorf("corrupted pickit history %s: %w", h.path, err)
+			}
+			revisions = append(revisions, rev)
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	return revisions, nil
+}
+
+// append numbers the revision and stores it, the content goes to its snapshot
+func (h *History) append(revisions *[]Revision, rev Revision) error {
+	rev.ID = len(*revisions) + 1
+	if !rev.Deleted {
+		rev.Hash = contentHash(rev.Content)
+		if err := h.saveSnapshot(rev.Hash, rev.Content); err != nil {
+			return err
+		}
+	}
+	rev.Content = ""
+
+	if err := os.MkdirAll(filepath.Dir(h.path), 0755); err != nil {
+		return fmt.Errorf("failed to create pickit history: %w", err)
+	}
+	f, err := os.OpenFile(h.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open pickit history: %w", err)
+	}
+	defer f.Close()
+
+	line, err := json.Marshal(rev)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write pickit history: %w", err)
+	}
+	*revisions = append(*revisions, rev)
+
+	return nil
+}
+
+func contentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+
+	return hex.EncodeToString(sum[:])
+}
+
+func findRevision(revisions []Revision, id int) (Revision, error) {
+	if id < 1 || id > len(revisions) {
+		return Revision{}, fmt.Errorf("revision %d not found", id)
+	}
+
+	return revisions[id-1], nil
+}
+
+func lastRevision(revisions []Revision, file string) (Revision, bool) {
+	for _, rev := range slices.Backward(revisions) {
+		if rev.File == file {
+			return rev, true
+		}
+	}
+
+	return Revision{}, false
+}
+
+// stateAt returns the last revision of the file up to id, or the version before editing when it was tracked later
+func stateAt(revisions []Revision, file string, id int) Revision {
+	var state Revision
+	for _, rev := range revisions {
+		if rev.File == file && (rev.ID <= id || state.ID == 0) {
+			state = rev
+		}
+	}
+
+	return state
+}
+
+// DiffLines compares two texts line by line using their longest common subsequence
+func DiffLines(a, b string) []DiffLine {
+	oldLines, newLines := splitLines(a), splitLines(b)
+
+	// Common prefix and suffix are kept out of the table
+	prefix := 0
+	for prefix < len(oldLines) && prefix < len(newLines) && oldLines[prefix] == newLines[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(oldLines)-prefix && suffix < len(newLines)-prefix &&
+		oldLines[len(oldLines)-1-suffix] == newLines[len(newLines)-1-suffix] {
+		suffix++
+	}
+
+	diff := make([]DiffLine, 0, len(oldLines)+len(newLines))
+	for i := 0; i < prefix; i++ {
+		diff = append(diff, DiffLine{Op: "=", Text: oldLines[i], OldLine: i + 1, NewLine: i + 1})
+	}
+
+	x, y := oldLines[prefix:len(oldLines)-suffix], newLines[prefix:len(newLines)-suffix]
+	i, j := 0, 0
+	addOld := func() {
+		diff = append(diff, DiffLine{Op: "-", Text: x[i], OldLine: prefix + i + 1})
+		i++
+	}
+	addNew := func() {
+		diff = append(diff, DiffLine{Op: "+", Text: y[j], NewLine: prefix + j + 1})
+		j++
+	}
+
+	if (len(x)+1)*(len(y)+1) <= maxDiffCells {
+		// lcs[i][j] is the longest common subsequence of x[i:] and y[j:]
+		lcs := make([][]int, len(x)+1)
+		for k := range lcs {
+			lcs[k] = make([]int, len(y)+1)
+		}
+		for i := len(x) - 1; i >= 0; i-- {
+			for j := len(y) - 1; j >= 0; j-- {
+				if x[i] == y[j] {
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				} else {
+					lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+				}
+			}
+		}
+
+		for i < len(x) && j < len(y) {
+			switch {
+			case x[i] == y[j]:
+				diff = append(diff, DiffLine{Op: "=", Text: x[i], OldLine: prefix + i + 1, NewLine: prefix + j + 1})
+				i++
+				j++
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				addOld()
+			default:
+				addNew()
+			}
+		}
+	}
+	for i < len(x) {
+		addOld()
+	}
+	for j < len(y) {
+		addNew()
+	}
+
+	for k := len(oldLines) - suffix; k < len(oldLines); k++ {
+		diff = append(diff, DiffLine{Op: "=", Text: oldLines[k], OldLine: k + 1, NewLine: k - len(oldLines) + len(newLines) + 1})
+	}
+
+	return diff
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(strings.ReplaceAll(s, "\r\n", "\n"), "\n"), "\n")
+}
@@ -0,0 +1,108 @@
+package pickit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHistoryRollback(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "pickit")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "general.nip"), []byte("[name] == berrune\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewHistory(dir)
+	if _, err := h.WriteFile("general.nip", "[name] == berrune\n[name] == jahrune\n", "test", "Add jah"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.WriteFile("runes.nip", "[name] == vexrune\n", "test", "Add vex"); err != nil {
+		t.Fatal(err)
+	}
+
+	revisions, err := h.Revisions("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Both files get their version before editing first
+	if len(revisions) != 4 || revisions[0].Message != "Add vex" || revisions[3].Message != "Version before editing" {
+		t.Fatalf("unexpected revisions %+v", revisions)
+	}
+
+	diff, err := h.Diff(1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff) != 2 || diff[0].Op != "=" || diff[1].Op != "+" || diff[1].Text != "[name] == jahrune" || diff[1].NewLine != 2 {
+		t.Errorf("unexpected diff %+v", diff)
+	}
+
+	restored, err := h.Rollback("", 1, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(restored) != 2 {
+		t.Errorf("expected both files restored, got %+v", restored)
+	}
+	if content, _ := os.ReadFile(filepath.Join(dir, "general.nip")); string(content) != "[name] == berrune\n" {
+		t.Errorf("general.nip not restored, got %q", content)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "runes.nip")); !os.IsNotExist(err) {
+		t.Errorf("runes.nip didn't exist at revision 1, got %v", err)
+	}
+
+	// The rollback is a revision as well
+	if _, err := h.Rollback("runes.nip", 4, "test"); err != nil {
+		t.Fatal(err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(dir, "runes.nip")); string(content) != "[name] == vexrune\n" {
+		t.Errorf("runes.nip not restored, got %q", content)
+	}
+
+	rev, err := h.Revision(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rev.Content != "[name] == berrune\n[name] == jahrune\n" {
+		t.Errorf("unexpected content of revision 2 %q", rev.Content)
+	}
+
+	// Every version is stored once, rollbacks reuse the snapshots
+	snapshots, err := os.ReadDir(dir + "_history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != 3 {
+		t.Errorf("expected a snapshot for each of the 3 distinct contents, got %d", len(snapshots))
+	}
+	index, err := os.ReadFile(dir + "_history.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(index), "berrune") {
+		t.Errorf("expected the revision list without content, got %s", index)
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	diff := DiffLines("a\nb\nc\nd\n", "a\nc\nx\nd\n")
+	expected := []DiffLine{
+		{Op: "=", Text: "a", OldLine: 1, NewLine: 1},
+		{Op: "-", Text: "b", OldLine: 2},
+		{Op: "=", Text: "c", OldLine: 3, NewLine: 2},
+		{Op: "+", Text: "x", NewLine: 3},
+		{Op: "=", Text: "d", OldLine: 4, NewLine: 4},
+	}
+	if len(diff) != len(expected) {
+		t.Fatalf("expected %+v, got %+v", expected, diff)
+	}
+	for i := range expected {
+		if diff[i] != expected[i] {
+			t.Errorf("line %d: expected %+v, got %+v", i, expected[i], diff[i])
+		}
+	}
+}
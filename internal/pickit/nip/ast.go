@@ -0,0 +1,146 @@
+package nip
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Node is an expression of a line
+type Node interface {
+	Pos() int
+	End() int
+}
+
+// BinaryExpr is X Op Y, for comparisons, && and || as well as arithmetic
+type BinaryExpr struct {
+	X  Node
+	Op Token
+	Y  Node
+}
+
+// UnaryExpr is a negative value, like [itemreqpercent] == -15
+type UnaryExpr struct {
+	Op Token
+	X  Node
+}
+
+type ParenExpr struct {
+	LParen Token
+	X      Node
+	RParen Token
+}
+
+// PropertyExpr is a property between brackets, [name] or [fcr]
+type PropertyExpr struct {
+	Tok Token
+}
+
+type NumberLit struct {
+	Tok Token
+}
+
+// IdentLit is a value compared with a property, like unique in [quality] == unique
+type IdentLit struct {
+	Tok Token
+}
+
+func (e *BinaryExpr) Pos() int   { return e.X.Pos() }
+func (e *BinaryExpr) End() int   { return e.Y.End() }
+func (e *UnaryExpr) Pos() int    { return e.Op.Pos }
+func (e *UnaryExpr) End() int    { return e.X.End() }
+func (e *ParenExpr) Pos() int    { return e.LParen.Pos }
+func (e *ParenExpr) End() int    { return e.RParen.End() }
+func (e *PropertyExpr) Pos() int { return e.Tok.Pos }
+func (e *PropertyExpr) End() int { return e.Tok.End() }
+func (e *NumberLit) Pos() int    { return e.Tok.Pos }
+func (e *NumberLit) End() int    { return e.Tok.End() }
+func (e *IdentLit) Pos() int     { return e.Tok.Pos }
+func (e *IdentLit) End() int     { return e.Tok.End() }
+
+// Name is the property name in lower case, without brackets
+func (e *PropertyExpr) Name() string {
+	return strings.ToLower(strings.TrimSpace(e.Tok.Text[1 : len(e.Tok.Text)-1]))
+}
+
+func (e *NumberLit) Value() float64 {
+	v, _ := strconv.ParseFloat(e.Tok.Text, 64)
+	return v
+}
+
+// Name is the value in lower case, NIP is not case sensitive
+func (e *IdentLit) Name() string {
+	return strings.ToLower(e.Tok.Text)
+}
+
+// Section is the part of a line before the first # (item properties), between the first and the second one (stats)
+// or after the second one (max quantity). Expr is nil when the section is empty.
+type Section struct {
+	Hash *Token // nil for the first section
+	Expr Node
+}
+
+// Line is a parsed NIP line, rules as well as comments and empty lines
+type Line struct {
+	Sections []Section
+	Comment  *Token
+	EOF      Token
+}
+
+// IsRule is false for empty and comment only lines
+func (l *Line) IsRule() bool {
+	return l.Sections[0].Expr != nil
+}
+
+// Section returns the expression of the section i, nil when the line doesn't have it or it's empty
+func (l *Line) Section(i int) Node {
+	if i >= len(l.Sections) {
+		return nil
+	}
+	return l.Sections[i].Expr
+}
+
+// CommentText is the comment without the leading //
+func (l *Line) CommentText() string {
+	if l.Comment == nil {
+		return ""
+	}
+	return strings.TrimSpace(l.Comment.Text[2:])
+}
+
+// Source returns the source text of a node of the line
+func Source(line string, n Node) string {
+	return line[n.Pos():n.End()]
+}
+
+// Conjuncts splits a node by its top level && operators
+func Conjuncts(n Node) []Node {
+	if b, ok := n.(*BinaryExpr); ok && b.Op.Kind == And {
+		return append(Conjuncts(b.X), Conjuncts(b.Y)...)
+	}
+	return []Node{n}
+}
+
+// Terms splits a node by its top level + operators
+func Terms(n Node) []Node {
+	if b, ok := n.(*BinaryExpr); ok && b.Op.Kind == Plus {
+		return append(Terms(b.X), Terms(b.Y)...)
+	}
+	return []Node{n}
+}
+
+// Walk calls fn for n and all the nodes below it, depth first
+func Walk(n Node, fn func(Node)) {
+	if n == nil {
+		return
+	}
+	fn(n)
+	switch e := n.(type) {
+	case *BinaryExpr:
+		Walk(e.X, fn)
+		Walk(e.Y, fn)
+	case *UnaryExpr:
+		Walk(e.X, fn)
+	case *ParenExpr:
+		Walk(e.X, fn)
+	}
+}
@@ -0,0 +1,172 @@
+// Package nip parses NIP pickit lines into an AST keeping every token with its position and the whitespace before
+// it, so lines can be printed back exactly as they were or formatted in a canonical way
+package nip
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Kind int
+
+const (
+	EOF      Kind = iota
+	Property      // [name]
+	Number        // 5, 4.5
+	Ident         // unique, ethereal, battlebelt...
+	Compare       // == != >= <= > <
+	And           // &&
+	Or            // ||
+	Plus
+	Minus
+	Star
+	Slash
+	LParen
+	RParen
+	Hash
+	Comment // From // to the end of the line
+)
+
+var kindNames = map[Kind]string{
+	EOF: "end of line", Property: "property", Number: "number", Ident: "value", Compare: "comparison", And: "&&",
+	Or: "||", Plus: "+", Minus: "-", Star: "*", Slash: "/", LParen: "(", RParen: ")", Hash: "#", Comment: "comment",
+}
+
+func (k Kind) String() string {
+	return kindNames[k]
+}
+
+// Token is a piece of the line, Pos is the byte offset of Text and Leading the whitespace right before it
+type Token struct {
+	Kind    Kind
+	Text    string
+	Pos     int
+	Leading string
+}
+
+func (t Token) End() int {
+	return t.Pos + len(t.Text)
+}
+
+// Error is a syntax error, Pos and End are the byte offsets of the offending text
+type Error struct {
+	Msg string
+	Pos int
+	End int
+}
+
+// Columns returns the 1-based first and last columns of the error
+func (e *Error) Columns() (int, int) {
+	return e.Pos + 1, max(e.End, e.Pos+1)
+}
+
+func (e *Error) Error() string {
+	start, end := e.Columns()
+	if start == end {
+		return fmt.Sprintf("col %d: %s", start, e.Msg)
+	}
+	return fmt.Sprintf("col %d-%d: %s", start, end, e.Msg)
+}
+
+// Tokenize splits a single line in tokens, the last one is always EOF holding the trailing whitespace
+func Tokenize(line string) ([]Token, error) {
+	var tokens []Token
+	pos := 0
+	for {
+		start := pos
+		for pos < len(line) && isSpace(line[pos]) {
+			pos++
+		}
+		leading := line[start:pos]
+		if pos == len(line) {
+			return append(tokens, Token{Kind: EOF, Pos: pos, Leading: leading}), nil
+		}
+
+		t, err := lexToken(line, pos)
+		if err != nil {
+			return nil, err
+		}
+		t.Leading = leading
+		tokens = append(tokens, t)
+		pos = t.End()
+	}
+}
+
+func lexToken(line string, pos int) (Token, error) {
+	c := line[pos]
+	two := ""
+	if pos+1 < len(line) {
+		two = line[pos : pos+2]
+	}
+	token := func(kind Kind, length int) (Token, error) {
+		return Token{Kind: kind, Text: line[pos : pos+length], Pos: pos}, nil
+	}
+
+	switch {
+	case two == "//":
+		// Trailing whitespace goes to the EOF token
+		return token(Comment, len(strings.TrimRight(line[pos:], " \t\r")))
+	case two == "&&":
+		return token(And, 2)
+	case two == "||":
+		return token(Or, 2)
+	case two == "==" || two == "!=" || two == ">=" || two == "<=":
+		return token(Compare, 2)
+	case c == '>' || c == '<':
+		return token(Compare, 1)
+	case c == '[':
+		end := strings.IndexAny(line[pos:], "]#")
+		if end < 0 || line[pos+end] != ']' {
+			return Token{}, &Error{Msg: "unterminated property, missing ]", Pos: pos, End: pos + 1}
+		}
+		if strings.TrimSpace(line[pos+1:pos+end]) == "" {
+			return Token{}, &Error{Msg: "empty property name", Pos: pos, End: pos + end + 1}
+		}
+		return token(Property, end+1)
+	case c == '+':
+		return token(Plus, 1)
+	case c == '-':
+		return token(Minus, 1)
+	case c == '*':
+		return token(Star, 1)
+	case c == '/':
+		return token(Slash, 1)
+	case c == '(':
+		return token(LParen, 1)
+	case c == ')':
+		return token(RParen, 1)
+	case c == '#':
+		return token(Hash, 1)
+	case isDigit(c):
+		end := pos
+		for end < len(line) && (isDigit(line[end]) || line[end] == '.') {
+			end++
+		}
+		if strings.Count(line[pos:end], ".") > 1 || line[end-1] == '.' {
+			return Token{}, &Error{Msg: fmt.Sprintf("invalid number %q", line[pos:end]), Pos: pos, End: end}
+		}
+		return token(Number, end-pos)
+	case isLetter(c):
+		end := pos
+		for end < len(line) && (isLetter(line[end]) || isDigit(line[end])) {
+			end++
+		}
+		return token(Ident, end-pos)
+	case c == '=' || c == '!' || c == '&' || c == '|':
+		return Token{}, &Error{Msg: fmt.Sprintf("unexpected %q, operators are ==, !=, >=, <=, >, <, && and ||", c), Pos: pos, End: pos + 1}
+	}
+
+	return Token{}, &Error{Msg: fmt.Sprintf("unexpected character %q", c), Pos: pos, End: pos + 1}
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c == '\''
+}
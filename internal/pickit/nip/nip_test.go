@@ -0,0 +1,206 @@
+package nip
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTemplatesRoundTrip(t *testing.T) {
+	files, err := filepath.Glob("../../../config/template/pickit/*.nip")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("Templates not found: %v", err)
+	}
+
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		scanner := bufio.NewScanner(f)
+		for n := 1; scanner.Scan(); n++ {
+			line := scanner.Text()
+			l, err := Parse(line)
+			if err != nil {
+				t.Errorf("%s:%d %q: %v", filepath.Base(file), n, line, err)
+				continue
+			}
+			if got := Print(l); got != line {
+				t.Errorf("%s:%d printed %q, expected %q", filepath.Base(file), n, got, line)
+			}
+
+			formatted := Format(l)
+			again, err := Parse(formatted)
+			if err != nil {
+				t.Errorf("%s:%d formatted %q: %v", filepath.Base(file), n, formatted, err)
+				continue
+			}
+			if got := Format(again); got != formatted {
+				t.Errorf("%s:%d format is not stable, %q then %q", filepath.Base(file), n, formatted, got)
+			}
+		}
+		f.Close()
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected string
+	}{
+		{"[name]==ring&&[quality]==unique#[maxquantity]==1", "[name] == ring && [quality] == unique # [maxquantity] == 1"},
+		{"[type] == amulet # ([fcr] * 18 + [strength]*8)>=500 // caster", "[type] == amulet # ([fcr]*18 + [strength]*8) >= 500 // caster"},
+		{"[name] == CrystalSword ||  [name] == BroadSword", "[name] == CrystalSword || [name] == BroadSword"},
+		{"[type] == ring ## [maxquantity] == 2", "[type] == ring # # [maxquantity] == 2"},
+		{"[ itemreqpercent ] == - 15", "[itemreqpercent] == -15"},
+		{"   // just a comment  ", "// just a comment"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		l, err := Parse(tt.line)
+		if err != nil {
+			t.Errorf("%q: %v", tt.line, err)
+			continue
+		}
+		if got := Format(l); got != tt.expected {
+			t.Errorf("%q formatted to %q, expected %q", tt.line, got, tt.expected)
+		}
+		if got := Print(l); got != tt.line {
+			t.Errorf("%q printed to %q", tt.line, got)
+		}
+	}
+}
+
+func TestPrecedence(t *testing.T) {
+	l, err := Parse("[name] == ring || [name] == amulet && [quality] == unique")
+	if err != nil {
+		t.Fatal(err)
+	}
+	or, ok := l.Section(0).(*BinaryExpr)
+	if !ok || or.Op.Kind != Or {
+		t.Fatalf("Expected || at the top, got %T", l.Section(0))
+	}
+	if c := Conjuncts(or.Y); len(c) != 2 {
+		t.Errorf("Expected && to bind tighter than ||, got %d conjuncts", len(c))
+	}
+
+	line := "[type] == ring # [fcr]*2 + [strength] - 1 >= 10"
+	l, err = Parse(line)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmp := l.Section(1).(*BinaryExpr)
+	if got := Source(line, cmp.X); cmp.Op.Text != ">=" || got != "[fcr]*2 + [strength] - 1" {
+		t.Errorf("Expected the sum on the left of >=, got %q %s", got, cmp.Op.Text)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		line       string
+		start, end int
+	}{
+		{"[name] = ring", 8, 8},
+		{"[name == ring", 1, 1},
+		{"[] == ring", 1, 2},
+		{"[name] == ring &&", 18, 18},
+		{"[name] == ring && && [quality] == unique", 19, 20},
+		{"([name] == ring", 1, 1},
+		{"[name] == ring)", 15, 15},
+		{"[fcr] >= 10 >= 5", 13, 14},
+		{"# [fcr] >= 10", 1, 1},
+		{"[type] == ring # [fcr] >= 10 # [maxquantity] == 1 # [x] == 1", 51, 51},
+		{"[name] == ring $", 16, 16},
+		{"[fcr] >= 1.2.3", 10, 14},
+		{"[type] == ring # [fcr]", 18, 22},
+		{"[type] == ring # [fcr] >= 5 && ([strength] + 5)", 33, 46},
+	}
+
+	for _, tt := range tests {
+		_, err := Parse(tt.line)
+		var nipErr *Error
+		if !errors.As(err, &nipErr) {
+			t.Errorf("%q: expected a syntax error, got %v", tt.line, err)
+			continue
+		}
+		if start, end := nipErr.Columns(); start != tt.start || end != tt.end {
+			t.Errorf("%q: expected columns %d-%d, got %v", tt.line, tt.start, tt.end, err)
+		}
+	}
+}
+
+func TestSubsumes(t *testing.T) {
+	tests := []struct {
+		general, specific string
+		expected          bool
+	}{
+		{"[type] == ring && [quality] == unique", "[name] == ring && [quality] == unique", false},
+		{"[type] == ring", "[type] == ring && [quality] == rare # [fcr] >= 10", true},
+		{"[type] == ring # [fcr] >= 5", "[type] == ring # [fcr] >= 10 && [strength] >= 5", true},
+		{"[type] == ring # [fcr] >= 10", "[type] == ring # [fcr] >= 5", false},
+		{"[type] == ring # [fcr] > 9", "[type] == ring # [fcr] == 10", true},
+		{"[name] == Ring && [quality] >= magic", "[name] == ring && [quality] == rare", true},
+		{"[name] == ring && [quality] <= superior", "[name] == ring && [quality] == magic", false},
+		{"[type] == ring # [fcr] >= 10 || [maxhp] >= 20", "[type] == ring # [maxhp] >= 30", true},
+		{"[type] == ring # [fcr] >= 10", "[type] == ring # ([fcr] >= 10 || [maxhp] >= 20)", false},
+		{"[type] == ring # ([fcr] >= 10 || [maxhp] >= 20)", "[type] == ring # ([fcr] >= 15 || [maxhp] >= 25) && [strength] >= 1", true},
+		{"[type] == ring # [fcr]*2 + [strength] >= 20", "[type] == ring # [fcr]*2 + [strength] >= 20 && [dexterity] >= 1", true},
+		{"[type] == ring # [itemreqpercent] <= -10", "[type] == ring # [itemreqpercent] == -15", true},
+		{"[type] == ring # [maxquantity] == 1", "[type] == ring # [fcr] >= 10 # [maxquantity] == 1", false},
+		{"[type] == ring # # [maxquantity] == 1", "[type] == ring # [fcr] >= 10", true},
+	}
+
+	for _, tt := range tests {
+		general, err := Parse(tt.general)
+		if err != nil {
+			t.Fatal(err)
+		}
+		specific, err := Parse(tt.specific)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := Subsumes(general, specific); got != tt.expected {
+			t.Errorf("Subsumes(%q, %q) = %v, expected %v", tt.general, tt.specific, got, tt.expected)
+		}
+	}
+}
+
+func TestContradiction(t *testing.T) {
+	tests := []struct {
+		line     string
+		property string
+	}{
+		{"[type] == ring # [fcr] >= 10 && [fcr] < 5", "fcr"},
+		{"[type] == ring # [fcr] > 5 && [fcr] < 6", "fcr"},
+		{"[name] == ring && [name] == amulet", "name"},
+		{"[type] == ring && [quality] == unique && [quality] <= rare", "quality"},
+		{"[type] == ring # [fcr] >= 5 && [fcr] <= 5", ""},
+		{"[name] == ring || [name] == amulet", ""},
+		{"[type] == armor && [flag] == ethereal && [flag] == runeword", ""},
+		{"[type] == ring # [fcr] >= 10 && ([fcr] < 5 || [maxhp] >= 20)", ""},
+	}
+
+	for _, tt := range tests {
+		l, err := Parse(tt.line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := Contradiction(l); got != tt.property {
+			t.Errorf("Contradiction(%q) = %q, expected %q", tt.line, got, tt.property)
+		}
+	}
+
+	a, _ := Parse("[type] == ring && [quality] == rare # [fcr] >= 10")
+	b, _ := Parse("[type] == ring && [quality] >= set # [fcr] >= 5")
+	c, _ := Parse("[type] == amulet")
+	if Disjoint(a, b) {
+		t.Errorf("Expected rare rings with 10 fcr to match both rules")
+	}
+	if !Disjoint(a, c) || !Disjoint(c, b) {
+		t.Errorf("Expected rings and amulets to be disjoint")
+	}
+}
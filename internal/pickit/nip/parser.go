@@ -0,0 +1,209 @@
+package nip
+
+import "fmt"
+
+// Sections of a rule: item properties, stats and max quantity
+const maxSections = 3
+
+// Parse parses a single line, empty and comment only lines are valid lines without rule
+func Parse(line string) (*Line, error) {
+	tokens, err := Tokenize(line)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	return p.line()
+}
+
+type parser struct {
+	tokens []Token
+	pos    int
+}
+
+func (p *parser) peek() Token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() Token {
+	t := p.tokens[p.pos]
+	if t.Kind != EOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) line() (*Line, error) {
+	l := &Line{}
+	section := Section{}
+	for {
+		if k := p.peek().Kind; k != Hash && k != Comment && k != EOF {
+			expr, err := p.or()
+			if err != nil {
+				return nil, err
+			}
+			if err := condition(expr); err != nil {
+				return nil, err
+			}
+			section.Expr = expr
+		}
+		l.Sections = append(l.Sections, section)
+
+		t := p.next()
+		if t.Kind == Hash {
+			if len(l.Sections) == maxSections {
+				return nil, &Error{Msg: "too many # sections, rules have item properties # stats # max quantity", Pos: t.Pos, End: t.End()}
+			}
+			section = Section{Hash: &t}
+			continue
+		}
+		if t.Kind == Comment {
+			comment := t
+			l.Comment = &comment
+			t = p.next()
+		}
+		if t.Kind != EOF {
+			return nil, unexpected(t, "&&, || or #")
+		}
+		l.EOF = t
+		break
+	}
+
+	if !l.IsRule() && len(l.Sections) > 1 {
+		h := l.Sections[1].Hash
+		return nil, &Error{Msg: "missing item properties before #", Pos: h.Pos, End: h.End()}
+	}
+
+	return l, nil
+}
+
+// condition checks the section is made of comparisons joined by && and ||
+func condition(n Node) error {
+	switch e := n.(type) {
+	case *BinaryExpr:
+		switch e.Op.Kind {
+		case And, Or:
+			if err := condition(e.X); err != nil {
+				return err
+			}
+			return condition(e.Y)
+		case Compare:
+			return nil
+		}
+	case *ParenExpr:
+		return condition(e.X)
+	}
+
+	return &Error{Msg: "expected a comparison like [property] == value", Pos: n.Pos(), End: n.End()}
+}
+
+func (p *parser) or() (Node, error) {
+	return p.binary(p.and, Or)
+}
+
+func (p *parser) and() (Node, error) {
+	return p.binary(p.comparison, And)
+}
+
+func (p *parser) comparison() (Node, error) {
+	x, err := p.sum()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().Kind != Compare {
+		return x, nil
+	}
+	op := p.next()
+	y, err := p.sum()
+	if err != nil {
+		return nil, err
+	}
+	if next := p.peek(); next.Kind == Compare {
+		return nil, &Error{Msg: "comparisons can't be chained, use && between them", Pos: next.Pos, End: next.End()}
+	}
+
+	return &BinaryExpr{X: x, Op: op, Y: y}, nil
+}
+
+func (p *parser) sum() (Node, error) {
+	return p.binary(p.product, Plus, Minus)
+}
+
+func (p *parser) product() (Node, error) {
+	return p.binary(p.unary, Star, Slash)
+}
+
+// binary parses left associative operators
+func (p *parser) binary(operand func() (Node, error), kinds ...Kind) (Node, error) {
+	x, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for p.is(kinds...) {
+		op := p.next()
+		y, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		x = &BinaryExpr{X: x, Op: op, Y: y}
+	}
+
+	return x, nil
+}
+
+func (p *parser) unary() (Node, error) {
+	if p.peek().Kind != Minus {
+		return p.primary()
+	}
+	op := p.next()
+	x, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+
+	return &UnaryExpr{Op: op, X: x}, nil
+}
+
+func (p *parser) primary() (Node, error) {
+	t := p.next()
+	switch t.Kind {
+	case Property:
+		return &PropertyExpr{Tok: t}, nil
+	case Number:
+		return &NumberLit{Tok: t}, nil
+	case Ident:
+		return &IdentLit{Tok: t}, nil
+	case LParen:
+		x, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		r := p.next()
+		if r.Kind != RParen {
+			if r.Kind == EOF || r.Kind == Hash || r.Kind == Comment {
+				return nil, &Error{Msg: "missing ) for this (", Pos: t.Pos, End: t.End()}
+			}
+			return nil, unexpected(r, ")")
+		}
+		return &ParenExpr{LParen: t, X: x, RParen: r}, nil
+	}
+
+	return nil, unexpected(t, "property, number or value")
+}
+
+func (p *parser) is(kinds ...Kind) bool {
+	k := p.peek().Kind
+	for _, kind := range kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+func unexpected(t Token, expected string) error {
+	if t.Kind == EOF {
+		return &Error{Msg: fmt.Sprintf("unexpected end of line, expected %s", expected), Pos: t.Pos, End: t.Pos}
+	}
+	return &Error{Msg: fmt.Sprintf("unexpected %s %q, expected %s", t.Kind, t.Text, expected), Pos: t.Pos, End: t.End()}
+}
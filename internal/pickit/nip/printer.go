@@ -0,0 +1,89 @@
+package nip
+
+import "strings"
+
+// Print writes the line back exactly as it was parsed, whitespace included
+func Print(l *Line) string {
+	var sb strings.Builder
+	write := func(t Token) {
+		sb.WriteString(t.Leading)
+		sb.WriteString(t.Text)
+	}
+
+	for _, s := range l.Sections {
+		if s.Hash != nil {
+			write(*s.Hash)
+		}
+		printNode(s.Expr, write)
+	}
+	if l.Comment != nil {
+		write(*l.Comment)
+	}
+	write(l.EOF)
+
+	return sb.String()
+}
+
+func printNode(n Node, write func(Token)) {
+	switch e := n.(type) {
+	case *BinaryExpr:
+		printNode(e.X, write)
+		write(e.Op)
+		printNode(e.Y, write)
+	case *UnaryExpr:
+		write(e.Op)
+		printNode(e.X, write)
+	case *ParenExpr:
+		write(e.LParen)
+		printNode(e.X, write)
+		write(e.RParen)
+	case *PropertyExpr:
+		write(e.Tok)
+	case *NumberLit:
+		write(e.Tok)
+	case *IdentLit:
+		write(e.Tok)
+	}
+}
+
+// Format writes the line in the canonical form: single spaces around operators except * and /, no spaces inside
+// brackets and parentheses, " # " between sections and the comment after a space
+func Format(l *Line) string {
+	var parts []string
+	for i, s := range l.Sections {
+		if i > 0 {
+			parts = append(parts, "#")
+		}
+		if s.Expr != nil {
+			parts = append(parts, FormatNode(s.Expr))
+		}
+	}
+	if l.Comment != nil {
+		parts = append(parts, strings.TrimRight(l.Comment.Text, " \t"))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// FormatNode writes an expression in the canonical form
+func FormatNode(n Node) string {
+	switch e := n.(type) {
+	case *BinaryExpr:
+		if e.Op.Kind == Star || e.Op.Kind == Slash {
+			return FormatNode(e.X) + e.Op.Text + FormatNode(e.Y)
+		}
+		return FormatNode(e.X) + " " + e.Op.Text + " " + FormatNode(e.Y)
+	case *UnaryExpr:
+		return e.Op.Text + FormatNode(e.X)
+	case *ParenExpr:
+		return "(" + FormatNode(e.X) + ")"
+	case *PropertyExpr:
+		return "[" + strings.TrimSpace(e.Tok.Text[1:len(e.Tok.Text)-1]) + "]"
+	case *NumberLit:
+		return e.Tok.Text
+	case *IdentLit:
+		return e.Tok.Text
+	}
+
+	return ""
+}
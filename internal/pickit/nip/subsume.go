@@ -0,0 +1,257 @@
+package nip
+
+import (
+	"math"
+	"strings"
+)
+
+// Quality values in the order NIP compares them, [quality] <= superior matches normal items too
+var qualities = map[string]float64{
+	"lowquality": 1, "normal": 2, "superior": 3, "magic": 4, "set": 5, "rare": 6, "unique": 7, "crafted": 8,
+}
+
+// Subsumes is true when every item matching specific also matches general, only looking at the item properties and
+// stats sections. It's conservative, false means it couldn't be proven.
+func Subsumes(general, specific *Line) bool {
+	if !general.IsRule() || !specific.IsRule() {
+		return false
+	}
+
+	known := facts(specific)
+	for _, c := range facts(general) {
+		if !impliedBy(known, c) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Contradiction returns a property compared in ways that can't be true at the same time, like [fcr] >= 10 &&
+// [fcr] < 5, so the rule never matches. Only conditions joined by && are checked.
+func Contradiction(l *Line) (string, bool) {
+	if !l.IsRule() {
+		return "", false
+	}
+
+	return contradiction(facts(l))
+}
+
+// Disjoint is true when no item can match both rules. It's conservative, false means it couldn't be proven.
+func Disjoint(a, b *Line) bool {
+	if !a.IsRule() || !b.IsRule() {
+		return false
+	}
+	_, found := contradiction(append(facts(a), facts(b)...))
+
+	return found
+}
+
+// HasProperty is true when the rule uses any of the properties, names in lower case
+func HasProperty(l *Line, names ...string) bool {
+	found := false
+	for _, s := range l.Sections {
+		Walk(s.Expr, func(n Node) {
+			if p, ok := n.(*PropertyExpr); ok {
+				for _, name := range names {
+					found = found || p.Name() == name
+				}
+			}
+		})
+	}
+
+	return found
+}
+
+// facts are the conditions of the item properties and stats sections joined by &&
+func facts(l *Line) []Node {
+	return append(conjuncts(l.Section(0)), conjuncts(l.Section(1))...)
+}
+
+func conjuncts(n Node) []Node {
+	if n == nil {
+		return nil
+	}
+	var out []Node
+	for _, c := range Conjuncts(unparen(n)) {
+		out = append(out, unparen(c))
+	}
+
+	return out
+}
+
+// impliedBy is true when the facts, all of them true, make c true
+func impliedBy(facts []Node, c Node) bool {
+	c = unparen(c)
+	if b, ok := c.(*BinaryExpr); ok {
+		switch b.Op.Kind {
+		case And:
+			return impliedBy(facts, b.X) && impliedBy(facts, b.Y)
+		case Or:
+			if impliedBy(facts, b.X) || impliedBy(facts, b.Y) {
+				return true
+			}
+		}
+	}
+
+	for _, f := range facts {
+		if implies(f, c) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// implies is true when f being true makes c true
+func implies(f, c Node) bool {
+	f, c = unparen(f), unparen(c)
+
+	if b, ok := c.(*BinaryExpr); ok && b.Op.Kind == And {
+		return implies(f, b.X) && implies(f, b.Y)
+	}
+	if b, ok := f.(*BinaryExpr); ok {
+		switch b.Op.Kind {
+		case Or:
+			return implies(b.X, c) && implies(b.Y, c)
+		case And:
+			return impliedBy(conjuncts(b), c)
+		}
+	}
+	if b, ok := c.(*BinaryExpr); ok && b.Op.Kind == Or {
+		return implies(f, b.X) || implies(f, b.Y)
+	}
+
+	if strings.EqualFold(FormatNode(f), FormatNode(c)) {
+		return true
+	}
+
+	fp, fr, ok := comparisonRange(f)
+	if !ok {
+		return false
+	}
+	cp, cr, ok := comparisonRange(c)
+
+	return ok && fp == cp && cr.contains(fr)
+}
+
+func contradiction(facts []Node) (string, bool) {
+	ranges := make(map[string]valueRange)
+	idents := make(map[string]string)
+	for _, f := range facts {
+		if p, r, ok := comparisonRange(f); ok {
+			if current, found := ranges[p]; found {
+				r = current.intersect(r)
+			}
+			if r.empty() {
+				return p, true
+			}
+			ranges[p] = r
+			continue
+		}
+
+		// [name], [type] and [quality] can only be equal to one value, an item has many flags
+		if p, value, ok := identEquality(f); ok {
+			if current, found := idents[p]; found && current != value {
+				return p, true
+			}
+			idents[p] = value
+		}
+	}
+
+	return "", false
+}
+
+func identEquality(n Node) (string, string, bool) {
+	b, ok := n.(*BinaryExpr)
+	if !ok || b.Op.Text != "==" {
+		return "", "", false
+	}
+	p, ok := b.X.(*PropertyExpr)
+	if !ok || (p.Name() != "name" && p.Name() != "type" && p.Name() != "quality") {
+		return "", "", false
+	}
+	v, ok := b.Y.(*IdentLit)
+	if !ok {
+		return "", "", false
+	}
+
+	return p.Name(), v.Name(), true
+}
+
+func unparen(n Node) Node {
+	for {
+		p, ok := n.(*ParenExpr)
+		if !ok {
+			return n
+		}
+		n = p.X
+	}
+}
+
+// valueRange is the closed range of values a comparison accepts
+type valueRange struct {
+	min, max float64
+}
+
+func (r valueRange) contains(o valueRange) bool {
+	return r.min <= o.min && o.max <= r.max
+}
+
+func (r valueRange) intersect(o valueRange) valueRange {
+	return valueRange{min: math.Max(r.min, o.min), max: math.Min(r.max, o.max)}
+}
+
+func (r valueRange) empty() bool {
+	return r.min > r.max
+}
+
+// comparisonRange returns the property and the range of values of [property] op value, != is not a range. Values
+// are integers in game so > 5 is >= 6.
+func comparisonRange(n Node) (string, valueRange, bool) {
+	b, ok := n.(*BinaryExpr)
+	if !ok || b.Op.Kind != Compare {
+		return "", valueRange{}, false
+	}
+	p, ok := b.X.(*PropertyExpr)
+	if !ok {
+		return "", valueRange{}, false
+	}
+	v, ok := comparedValue(p.Name(), b.Y)
+	if !ok {
+		return "", valueRange{}, false
+	}
+
+	switch b.Op.Text {
+	case "==":
+		return p.Name(), valueRange{min: v, max: v}, true
+	case ">=":
+		return p.Name(), valueRange{min: v, max: math.Inf(1)}, true
+	case ">":
+		return p.Name(), valueRange{min: math.Floor(v) + 1, max: math.Inf(1)}, true
+	case "<=":
+		return p.Name(), valueRange{min: math.Inf(-1), max: v}, true
+	case "<":
+		return p.Name(), valueRange{min: math.Inf(-1), max: math.Ceil(v) - 1}, true
+	}
+
+	return "", valueRange{}, false
+}
+
+func comparedValue(property string, n Node) (float64, bool) {
+	switch v := n.(type) {
+	case *NumberLit:
+		return v.Value(), true
+	case *UnaryExpr:
+		if num, ok := v.X.(*NumberLit); ok && v.Op.Kind == Minus {
+			return -num.Value(), true
+		}
+	case *IdentLit:
+		if property == "quality" {
+			q, found := qualities[v.Name()]
+			return q, found
+		}
+	}
+
+	return 0, false
+}
@@ -2,8 +2,12 @@ package pickit
 
 import (
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/hectorgimenez/koolo/internal/pickit/nip"
 )
 
 // NIPBuilder handles conversion between visual rules and NIP syntax
@@ -30,23 +34,22 @@ func (b *NIPBuilder) GenerateNIP(rule *PickitRule) (string, error) {
 	parts = append(parts, leftSide)
 
 	// Build right side (after #) - stats/scored conditions
-	if len(rule.RightConditions) > 0 || rule.IsScored {
+	rightSide := b.buildRightConditions(rule.RightConditions)
+	if rule.IsScored {
+		if scoredSyntax := b.buildScoredConditions(rule); scoredSyntax != "" && rightSide != "" {
+			rightSide += " && " + scoredSyntax
+		} else if scoredSyntax != "" {
+			rightSide = scoredSyntax
+		}
+	}
+	if rightSide != "" || rule.MaxQuantity > 0 {
 		parts = append(parts, "#")
-
-		if rule.IsScored {
-			// Build scored rule
-			scoredSyntax := b.buildScoredConditions(rule)
-			parts = append(parts, scoredSyntax)
-		} else {
-			// Build regular stat conditions
-			rightSide := b.buildRightConditions(rule.RightConditions)
-			if rightSide != "" {
-				parts = append(parts, rightSide)
-			}
+		if rightSide != "" {
+			parts = append(parts, rightSide)
 		}
 	}
 
-	// Add max quantity if specified
+	// Add max quantity if specified, the stats section stays empty when there are no stats
 	if rule.MaxQuantity > 0 {
 		parts = append(parts, fmt.Sprintf("# [maxquantity] == %d", rule.MaxQuantity))
 	}
@@ -101,12 +104,20 @@ func (b *NIPBuilder) buildScoredConditions(rule *PickitRule) string {
 		return ""
 	}
 
+	// Sorted so the same rule always generates the same line
+	stats := make([]string, 0, len(rule.ScoreWeights))
+	for stat := range rule.ScoreWeights {
+		stats = append(stats, stat)
+	}
+	slices.Sort(stats)
+
 	var scoreParts []string
-	for stat, weight := range rule.ScoreWeights {
-		statType := GetStatTypeByID(stat)
-		if statType != nil {
-			scoreParts = append(scoreParts, fmt.Sprintf("%s*%.1f", statType.NipProperty, weight))
+	for _, stat := range stats {
+		property := "[" + stat + "]"
+		if statType := GetStatTypeByID(stat); statType != nil {
+			property = statType.NipProperty
 		}
+		scoreParts = append(scoreParts, property+"*"+strconv.FormatFloat(rule.ScoreWeights[stat], 'f', -1, 64))
 	}
 
 	if len(scoreParts) == 0 {
@@ -114,12 +125,17 @@ func (b *NIPBuilder) buildScoredConditions(rule *PickitRule) string {
 	}
 
 	// Build: ([stat1]*weight1 + [stat2]*weight2 + ...) >= threshold
-	scoreFormula := fmt.Sprintf("(%s) >= %.0f", strings.Join(scoreParts, " + "), rule.ScoreThreshold)
+	scoreFormula := fmt.Sprintf("(%s) >= %s", strings.Join(scoreParts, " + "), strconv.FormatFloat(rule.ScoreThreshold, 'f', -1, 64))
 	return scoreFormula
 }
 
 // conditionToNIP converts a single condition to NIP syntax
 func (b *NIPBuilder) conditionToNIP(cond Condition) string {
+	// Expressions that aren't a single comparison, like || groups, only have their NIP syntax
+	if cond.Property == "" {
+		return cond.NipSyntax
+	}
+
 	property := cond.Property
 	operator := cond.Operator
 	value := cond.Value
@@ -135,153 +151,189 @@ func (b *NIPBuilder) conditionToNIP(cond Condition) string {
 	}
 }
 
-// ParseNIP parses a NIP line into a PickitRule
+// ParseNIP parses a NIP line into a PickitRule. Conditions joined by && are split, anything more complex like ||
+// groups or arithmetic is kept as a single condition with its NIP syntax so GenerateNIP can write it back.
 func (b *NIPBuilder) ParseNIP(nipLine string) (*PickitRule, error) {
-	if nipLine == "" {
-		return nil, fmt.Errorf("empty NIP line")
+	line, err := nip.Parse(nipLine)
+	if err != nil {
+		return nil, err
 	}
-
-	// Remove comments
-	parts := strings.Split(nipLine, "//")
-	nipLine = strings.TrimSpace(parts[0])
-	comments := ""
-	if len(parts) > 1 {
-		comments = strings.TrimSpace(parts[1])
+	if !line.IsRule() {
+		return nil, fmt.Errorf("empty NIP line")
 	}
 
-	// Split by #
-	sections := strings.Split(nipLine, "#")
-
 	rule := &PickitRule{
 		ID:        generateRuleID(),
 		Enabled:   true,
 		Priority:  50,
-		Comments:  comments,
+		Comments:  line.CommentText(),
 		CreatedAt: time.Now().Format(time.RFC3339),
 		UpdatedAt: time.Now().Format(time.RFC3339),
 	}
 
 	// Parse left side (before first #)
-	if len(sections) > 0 {
-		leftConditions, err := b.parseConditions(sections[0])
-		if err != nil {
-			return nil, fmt.Errorf("error parsing left conditions: %w", err)
-		}
-		rule.LeftConditions = leftConditions
+	rule.LeftConditions = b.parseConditions(line.Section(0))
 
-		// Extract item name from conditions
-		for _, cond := range leftConditions {
-			if cond.Property == "name" {
-				rule.ItemName = fmt.Sprintf("%v", cond.Value)
-				break
-			}
+	// Extract item name from conditions
+	for _, cond := range rule.LeftConditions {
+		if cond.Property == "name" {
+			rule.ItemName = fmt.Sprintf("%v", cond.Value)
+			break
 		}
 	}
 
-	// Parse right side (stats - between first and second #)
-	if len(sections) > 1 {
-		rightSection := strings.TrimSpace(sections[1])
-
-		// Check if it's a scored rule
-		if strings.Contains(rightSection, "(") && strings.Contains(rightSection, "*") {
-			// Scored rule
-			rule.IsScored = true
-			// TODO: Parse scored conditions
-		} else {
-			// Regular stat conditions
-			rightConditions, err := b.parseConditions(rightSection)
-			if err != nil {
-				return nil, fmt.Errorf("error parsing right conditions: %w", err)
+	// Parse right side (stats - between first and second #), one of the conditions can be a scored formula
+	rule.RightConditions = []Condition{}
+	if expr := line.Section(1); expr != nil {
+		for _, n := range nip.Conjuncts(expr) {
+			if !rule.IsScored && b.parseScoredCondition(n, rule) {
+				continue
 			}
-			rule.RightConditions = rightConditions
+			rule.RightConditions = append(rule.RightConditions, b.parseCondition(n))
 		}
 	}
 
 	// Parse max quantity (after second #)
-	if len(sections) > 2 {
-		maxQtySection := strings.TrimSpace(sections[2])
-		if strings.Contains(maxQtySection, "maxquantity") {
-			// Parse: [maxquantity] == 5
-			var qty int
-			fmt.Sscanf(maxQtySection, "[maxquantity] == %d", &qty)
-			rule.MaxQuantity = qty
+	if expr := line.Section(2); expr != nil {
+		qty, ok := parseMaxQuantity(expr)
+		if !ok {
+			return nil, &nip.Error{Msg: "expected [maxquantity] == number", Pos: expr.Pos(), End: expr.End()}
 		}
+		rule.MaxQuantity = qty
 	}
 
-	rule.GeneratedNIP = nipLine
+	end := line.EOF.Pos
+	if line.Comment != nil {
+		end = line.Comment.Pos
+	}
+	rule.GeneratedNIP = strings.TrimSpace(nipLine[:end])
+
 	return rule, nil
 }
 
-// parseConditions parses conditions from a section
-func (b *NIPBuilder) parseConditions(section string) ([]Condition, error) {
-	section = strings.TrimSpace(section)
-	if section == "" {
-		return []Condition{}, nil
+// parseConditions splits a section by its && operators
+func (b *NIPBuilder) parseConditions(section nip.Node) []Condition {
+	if section == nil {
+		return []Condition{}
 	}
 
-	// Split by && for AND conditions
-	conditionParts := strings.Split(section, "&&")
-
 	var conditions []Condition
-	for _, part := range conditionParts {
-		part = strings.TrimSpace(part)
-		if part == "" {
-			continue
-		}
-
-		cond, err := b.parseCondition(part)
-		if err != nil {
-			return nil, err
-		}
-		conditions = append(conditions, cond)
+	for _, n := range nip.Conjuncts(section) {
+		conditions = append(conditions, b.parseCondition(n))
 	}
 
-	return conditions, nil
+	return conditions
 }
 
-// parseCondition parses a single condition
-func (b *NIPBuilder) parseCondition(condStr string) (Condition, error) {
-	condStr = strings.TrimSpace(condStr)
+// parseCondition converts [property] operator value to a condition, other expressions only keep their NIP syntax
+func (b *NIPBuilder) parseCondition(n nip.Node) Condition {
+	cond := Condition{NipSyntax: nip.FormatNode(n)}
 
-	// Extract property, operator, value
-	// Format: [property] operator value
+	cmp, ok := n.(*nip.BinaryExpr)
+	if !ok || cmp.Op.Kind != nip.Compare {
+		return cond
+	}
+	property, ok := cmp.X.(*nip.PropertyExpr)
+	if !ok {
+		return cond
+	}
+	switch v := cmp.Y.(type) {
+	case *nip.IdentLit, *nip.NumberLit:
+		cond.Value = nip.FormatNode(v)
+	case *nip.UnaryExpr:
+		if _, ok := v.X.(*nip.NumberLit); !ok {
+			return cond
+		}
+		cond.Value = nip.FormatNode(v)
+	default:
+		return cond
+	}
+	cond.Property = property.Name()
+	cond.Operator = cmp.Op.Text
 
-	// Find property (between [ and ])
-	startBracket := strings.Index(condStr, "[")
-	endBracket := strings.Index(condStr, "]")
+	return cond
+}
 
-	if startBracket == -1 || endBracket == -1 {
-		return Condition{}, fmt.Errorf("invalid condition format: %s", condStr)
+// parseScoredCondition fills the score of the rule from ([stat1]*weight1 + [stat2]*weight2 + ...) >= threshold
+func (b *NIPBuilder) parseScoredCondition(n nip.Node, rule *PickitRule) bool {
+	cmp, ok := n.(*nip.BinaryExpr)
+	if !ok || cmp.Op.Text != ">=" {
+		return false
+	}
+	threshold, ok := cmp.Y.(*nip.NumberLit)
+	if !ok {
+		return false
+	}
+	sum := cmp.X
+	if p, ok := sum.(*nip.ParenExpr); ok {
+		sum = p.X
 	}
 
-	property := condStr[startBracket+1 : endBracket]
-	rest := strings.TrimSpace(condStr[endBracket+1:])
+	// Plain sums like [coldresist]+[fireresist] >= 40 stay as conditions, scores have weights
+	weights := make(map[string]float64)
+	weighted := false
+	for _, term := range nip.Terms(sum) {
+		stat, weight, ok := scoreTerm(term)
+		if !ok {
+			return false
+		}
+		if _, found := weights[stat]; found {
+			return false
+		}
+		weights[stat] = weight
+		_, plain := term.(*nip.PropertyExpr)
+		weighted = weighted || !plain
+	}
+	if len(weights) < 2 || !weighted {
+		return false
+	}
 
-	// Parse operator and value
-	var operator string
-	var value interface{}
+	rule.IsScored = true
+	rule.ScoreWeights = weights
+	rule.ScoreThreshold = threshold.Value()
 
-	// Try different operators
-	operators := []string{">=", "<=", "==", "!=", ">", "<"}
-	for _, op := range operators {
-		if strings.Contains(rest, op) {
-			parts := strings.SplitN(rest, op, 2)
-			operator = op
-			value = strings.TrimSpace(parts[1])
-			break
+	return true
+}
+
+// scoreTerm parses [stat]*weight, weight*[stat] or [stat]
+func scoreTerm(n nip.Node) (string, float64, bool) {
+	if p, ok := n.(*nip.PropertyExpr); ok {
+		return p.Name(), 1, true
+	}
+
+	mul, ok := n.(*nip.BinaryExpr)
+	if !ok || mul.Op.Kind != nip.Star {
+		return "", 0, false
+	}
+	if p, ok := mul.X.(*nip.PropertyExpr); ok {
+		if w, ok := mul.Y.(*nip.NumberLit); ok {
+			return p.Name(), w.Value(), true
 		}
 	}
+	if p, ok := mul.Y.(*nip.PropertyExpr); ok {
+		if w, ok := mul.X.(*nip.NumberLit); ok {
+			return p.Name(), w.Value(), true
+		}
+	}
+
+	return "", 0, false
+}
 
-	if operator == "" {
-		return Condition{}, fmt.Errorf("no operator found in condition: %s", condStr)
+func parseMaxQuantity(n nip.Node) (int, bool) {
+	cmp, ok := n.(*nip.BinaryExpr)
+	if !ok || cmp.Op.Text != "==" {
+		return 0, false
+	}
+	property, ok := cmp.X.(*nip.PropertyExpr)
+	if !ok || property.Name() != "maxquantity" {
+		return 0, false
+	}
+	qty, ok := cmp.Y.(*nip.NumberLit)
+	if !ok {
+		return 0, false
 	}
 
-	return Condition{
-		Property:  property,
-		Operator:  operator,
-		Value:     value,
-		NipSyntax: condStr,
-	}, nil
+	return int(qty.Value()), true
 }
 
 // ValidateRule validates a pickit rule
@@ -333,7 +385,7 @@ func (b *NIPBuilder) ValidateRule(rule *PickitRule) ValidationResult {
 
 	// Validate stat properties exist
 	for _, cond := range append(rule.LeftConditions, rule.RightConditions...) {
-		if !b.isValidProperty(cond.Property) {
+		if cond.Property != "" && !b.isValidProperty(cond.Property) {
 			result.Warnings = append(result.Warnings, fmt.Sprintf("Unknown property: [%s]", cond.Property))
 		}
 	}
@@ -0,0 +1,227 @@
+package pickit
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"strconv"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
+	"github.com/hectorgimenez/d2go/pkg/nip"
+)
+
+// Outcome is what the bot does with an item according to the pickit rules
+type Outcome string
+
+const (
+	OutcomeStash   Outcome = "stash"   // Full match, picked up and stashed
+	OutcomePick    Outcome = "pick"    // Picked up but only stashed under some conditions, like tier rules
+	OutcomePartial Outcome = "partial" // Picked up to be identified, the stats decide after that
+	OutcomeIgnore  Outcome = "ignore"  // Left on the ground
+)
+
+// Simulator runs items through the pickit rules the same way action.shouldBePickedUp and action.shouldStashIt do,
+// leaving out everything that depends on the game state (gold, quantity already stashed, equipped items...)
+type Simulator struct {
+	rules     nip.Rules
+	tierRules []int
+	rnd       *rand.Rand
+}
+
+// NewSimulator creates a simulator, the seed makes the rolled stats reproducible
+func NewSimulator(rules nip.Rules, tierRules []int, seed int64) *Simulator {
+	return &Simulator{
+		rules:     rules,
+		tierRules: tierRules,
+		rnd:       rand.New(rand.NewSource(seed)),
+	}
+}
+
+// NewSimulatorFromLines creates a simulator for a few NIP lines instead of a whole pickit folder, tier rules are
+// detected the same way the config does it
+func NewSimulatorFromLines(lines []string, seed int64) (*Simulator, error) {
+	var rules nip.Rules
+	for i, line := range lines {
+		rule, err := nip.NewRule(line, "simulation", i+1)
+		if errors.Is(err, nip.ErrEmptyRule) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		rules = append(rules, rule)
+	}
+
+	return NewSimulator(rules, tierRuleIndexes(rules), seed), nil
+}
+
+// NewSimulatorFromDir creates a simulator for the .nip files of a pickit directory as they are on disk, the rules
+// loaded by the bot are outdated as soon as a file is edited
+func NewSimulatorFromDir(dir string, seed int64) (*Simulator, error) {
+	// nip.ReadDir joins the directory and the file names as they are
+	rules, err := nip.ReadDir(filepath.Clean(dir) + string(filepath.Separator))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pickit rules from %s: %w", dir, err)
+	}
+
+	return NewSimulator(rules, tierRuleIndexes(rules), seed), nil
+}
+
+// tierRuleIndexes returns the rules with a tier or a merc tier, the same way the config does it
+func tierRuleIndexes(rules nip.Rules) []int {
+	var tierRules []int
+	for i, rule := range rules {
+		if rule.Tier() > 0 || rule.MercTier() > 0 {
+			tierRules = append(tierRules, i)
+		}
+	}
+
+	return tierRules
+}
+
+// SampleItems builds count items of the definition, every stat in AvailableStats is rolled between its min and max
+// value with a 50% chance of being on the item
+func (s *Simulator) SampleItems(def ItemDefinition, count int) ([]data.Item, error) {
+	name := def.NIPName
+	if def.BaseItem != "" {
+		name = def.BaseItem
+	}
+	id := item.GetIDByName(name)
+	if id < 0 {
+		return nil, fmt.Errorf("unknown base item %s for %s", name, def.Name)
+	}
+
+	qualities := def.Quality
+	if len(qualities) == 0 {
+		qualities = []item.Quality{item.QualityNormal}
+	}
+
+	items := make([]data.Item, 0, count)
+	for range count {
+		it := data.Item{
+			ID:       id,
+			Name:     item.GetNameByEnum(uint(id)),
+			Quality:  qualities[s.rnd.Intn(len(qualities))],
+			Ethereal: def.Ethereal && s.rnd.Intn(4) == 0,
+		}
+		for _, st := range def.AvailableStats {
+			if s.rnd.Intn(2) == 0 {
+				continue
+			}
+			value := int(st.MinValue) + s.rnd.Intn(int(st.MaxValue-st.MinValue)+1)
+			if sd, found := statData(st.ID, value); found {
+				it.Stats = append(it.Stats, sd)
+			}
+		}
+		if def.MaxSockets > 0 {
+			if sd, found := statData("sockets", s.rnd.Intn(def.MaxSockets+1)); found && sd.Value > 0 {
+				it.Stats = append(it.Stats, sd)
+			}
+		}
+		// Normal and superior items don't need to be identified
+		it.Identified = it.Quality <= item.QualitySuperior
+		items = append(items, it)
+	}
+
+	return items, nil
+}
+
+// Evaluate returns what the bot does with the item when it sees it on the ground and, when it's picked up to be
+// identified, what happens after that
+func (s *Simulator) Evaluate(it data.Item, source string) SimulatedItem {
+	result := SimulatedItem{
+		ItemName: string(it.Name),
+		Quality:  it.Quality.ToString(),
+		Ethereal: it.Ethereal,
+		Stats:    statsByName(it),
+		Source:   source,
+	}
+
+	s.evaluate(it, &result)
+	if result.Outcome == OutcomePartial && !it.Identified {
+		identified := it
+		identified.Identified = true
+		after := SimulatedItem{}
+		s.evaluate(identified, &after)
+		result.AfterIdentify = after.Outcome
+		result.Reason = "After identifying: " + after.Reason
+		if after.Rule != "" {
+			result.Rule, result.RuleFile = after.Rule, after.RuleFile
+		}
+	}
+
+	return result
+}
+
+func (s *Simulator) evaluate(it data.Item, result *SimulatedItem) {
+	if it.IsRuneword {
+		result.Outcome, result.Reason = OutcomeStash, "Runewords are always kept"
+		return
+	}
+
+	playerRule, mercRule := s.rules.EvaluateTiers(it, s.tierRules)
+	if playerRule.Tier() > 0.0 || mercRule.MercTier() > 0.0 {
+		tierRule, reason := playerRule, "Tier rule, stashed when better than the equipped item"
+		if playerRule.Tier() <= 0.0 {
+			tierRule, reason = mercRule, "Merc tier rule, stashed when better than the merc equipped item"
+		}
+		result.Outcome, result.Reason = OutcomePick, reason
+		if it.Quality > item.QualitySuperior && !it.Identified {
+			result.Outcome, result.Reason = OutcomePartial, "Tier rule, needs to be identified first"
+		}
+		result.Rule, result.RuleFile = tierRule.RawLine, ruleLocation(tierRule)
+		return
+	}
+
+	rule, res := s.rules.EvaluateAllIgnoreTiers(it)
+	switch res {
+	case nip.RuleResultNoMatch:
+		result.Outcome, result.Reason = OutcomeIgnore, "No rule matches"
+		return
+	case nip.RuleResultPartial:
+		result.Outcome, result.Reason = OutcomePartial, "Item properties match, stats are checked after identifying it"
+	default:
+		result.Outcome, result.Reason = OutcomeStash, "Full match"
+		if it.IsPotion() {
+			result.Outcome, result.Reason = OutcomePick, "Potions are picked up but never stashed"
+		} else if qty := rule.MaxQuantity(); qty > 0 {
+			result.Reason = fmt.Sprintf("Full match, stashed until there are %d, dropped after that", qty)
+		}
+	}
+	result.Rule, result.RuleFile = rule.RawLine, ruleLocation(rule)
+}
+
+func ruleLocation(rule nip.Rule) string {
+	return rule.Filename + ":" + strconv.Itoa(rule.LineNumber)
+}
+
+// statData maps a NIP property to the stat the rules read
+func statData(property string, value int) (stat.Data, bool) {
+	alias, found := nip.StatAliases[property]
+	if !found {
+		return stat.Data{}, false
+	}
+	layer := 0
+	if len(alias) > 1 {
+		layer = alias[1]
+	}
+
+	return stat.Data{ID: stat.ID(alias[0]), Value: value, Layer: layer}, true
+}
+
+// statsByName returns the item stats known by the editor by their NIP property
+func statsByName(it data.Item) map[string]int {
+	stats := make(map[string]int)
+	for _, st := range GetAllStatTypes() {
+		if ref, found := statData(st.ID, 0); found {
+			if itemStat, found := it.FindStat(ref.ID, ref.Layer); found {
+				stats[st.ID] = itemStat.Value
+			}
+		}
+	}
+
+	return stats
+}
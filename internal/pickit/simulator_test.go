@@ -0,0 +1,130 @@
+package pickit
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
+)
+
+func TestSimulatorEvaluate(t *testing.T) {
+	lines := []string{
+		"[name] == jahrune",
+		"[name] == superhealingpotion",
+		"[name] == ring && [quality] == rare # [fcr] >= 10",
+		"[name] == amulet && [quality] == unique # # [tier] == 10",
+	}
+
+	tests := []struct {
+		name          string
+		item          data.Item
+		outcome       Outcome
+		afterIdentify Outcome
+		ruleFile      string
+	}{
+		{
+			name:     "full match is stashed",
+			item:     testItem(t, "JahRune", item.QualityNormal, true),
+			outcome:  OutcomeStash,
+			ruleFile: "simulation:1",
+		},
+		{
+			name:     "potions are picked up but not stashed",
+			item:     testItem(t, "SuperHealingPotion", item.QualityNormal, true),
+			outcome:  OutcomePick,
+			ruleFile: "simulation:2",
+		},
+		{
+			name:          "stats checked after identifying",
+			item:          testItem(t, "Ring", item.QualityRare, false, stat.Data{ID: stat.FasterCastRate, Value: 10}),
+			outcome:       OutcomePartial,
+			afterIdentify: OutcomeStash,
+			ruleFile:      "simulation:3",
+		},
+		{
+			name:          "dropped after identifying",
+			item:          testItem(t, "Ring", item.QualityRare, false),
+			outcome:       OutcomePartial,
+			afterIdentify: OutcomeIgnore,
+			ruleFile:      "simulation:3",
+		},
+		{
+			name:    "no rule matches",
+			item:    testItem(t, "Ring", item.QualityMagic, true),
+			outcome: OutcomeIgnore,
+		},
+		{
+			name:     "tier rule",
+			item:     testItem(t, "Amulet", item.QualityUnique, true),
+			outcome:  OutcomePick,
+			ruleFile: "simulation:4",
+		},
+		{
+			name:          "tier rule needs the item identified",
+			item:          testItem(t, "Amulet", item.QualityUnique, false),
+			outcome:       OutcomePartial,
+			afterIdentify: OutcomePick,
+			ruleFile:      "simulation:4",
+		},
+	}
+
+	s, err := NewSimulatorFromLines(lines, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := s.Evaluate(tt.item, "test")
+			if result.Outcome != tt.outcome || result.AfterIdentify != tt.afterIdentify || result.RuleFile != tt.ruleFile {
+				t.Errorf("expected %s/%q by %q, got %s/%q by %q (%s)", tt.outcome, tt.afterIdentify, tt.ruleFile,
+					result.Outcome, result.AfterIdentify, result.RuleFile, result.Reason)
+			}
+		})
+	}
+}
+
+func TestSimulatorSampleItems(t *testing.T) {
+	def := ItemDefinition{
+		Name:           "Stone of Jordan",
+		NIPName:        "ring",
+		Quality:        []item.Quality{item.QualityUnique},
+		AvailableStats: []StatType{{ID: "fcr", MinValue: 0, MaxValue: 20}, {ID: "maxhp", MinValue: 10, MaxValue: 40}},
+	}
+
+	sample := func(seed int64) []data.Item {
+		s, err := NewSimulatorFromLines([]string{"[name] == ring"}, seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		items, err := s.SampleItems(def, 20)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return items
+	}
+
+	items := sample(7)
+	if len(items) != 20 {
+		t.Fatalf("expected 20 items, got %d", len(items))
+	}
+	for _, it := range items {
+		if it.Name != "Ring" || it.Quality != item.QualityUnique || it.Identified {
+			t.Fatalf("unexpected item %+v", it)
+		}
+		if fcr, found := it.FindStat(stat.FasterCastRate, 0); found && (fcr.Value < 0 || fcr.Value > 20) {
+			t.Errorf("fcr %d out of range", fcr.Value)
+		}
+	}
+	if !reflect.DeepEqual(items, sample(7)) {
+		t.Error("the same seed should roll the same items")
+	}
+	if reflect.DeepEqual(items, sample(8)) {
+		t.Error("a different seed should roll different items")
+	}
+
+	if _, err := NewSimulator(nil, nil, 1).SampleItems(ItemDefinition{Name: "Unknown", NIPName: "unknownitem"}, 1); err == nil {
+		t.Error("expected an error for an unknown base item")
+	}
+}
@@ -1,7 +1,5 @@
 package pickit
 
-import "fmt"
-
 // GetRuleTemplates returns pre-built rule templates
 func GetRuleTemplates() []RuleTemplate {
 	return []RuleTemplate{
@@ -357,29 +355,3 @@ func GetAutoSuggestions(rule *PickitRule) []AutoSuggestion {
 
 	return suggestions
 }
-
-// DetectConflicts detects conflicts between rules
-func DetectConflicts(rules []PickitRule) []ConflictDetection {
-	conflicts := []ConflictDetection{}
-
-	// Check for duplicate rules
-	seen := make(map[string][]string)
-	for _, rule := range rules {
-		key := rule.ItemName
-		seen[key] = append(seen[key], rule.ID)
-	}
-
-	for itemName, ruleIDs := range seen {
-		if len(ruleIDs) > 1 {
-			conflicts = append(conflicts, ConflictDetection{
-				Type:        "duplicate",
-				Rules:       ruleIDs,
-				Severity:    "warning",
-				Description: fmt.Sprintf("Multiple rules for '%s'", itemName),
-				Suggestion:  "Consider merging these rules or adjusting priorities",
-			})
-		}
-	}
-
-	return conflicts
-}
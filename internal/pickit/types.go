@@ -104,24 +104,25 @@ type ValidationResult struct {
 	Suggestions []string `json:"suggestions"` // Improvement suggestions
 }
 
-// SimulationResult represents the result of testing a rule
+// SimulationResult represents the result of running item samples through the pickit rules
 type SimulationResult struct {
-	RuleID      string      `json:"ruleId"`      // Rule being tested
-	MatchCount  int         `json:"matchCount"`  // Number of items matched
-	Matches     []ItemMatch `json:"matches"`     // Matched items
-	Misses      []ItemMatch `json:"misses"`      // Items that didn't match
-	Performance string      `json:"performance"` // Performance assessment
-	Suggestions []string    `json:"suggestions"` // Optimization suggestions
+	Items       []SimulatedItem `json:"items"`       // Outcome of every item
+	Summary     map[Outcome]int `json:"summary"`     // Number of items per outcome
+	Suggestions []string        `json:"suggestions"` // Optimization suggestions
 }
 
-// ItemMatch represents an item that matched or didn't match a rule
-type ItemMatch struct {
-	ItemName  string                 `json:"itemName"`  // Item name
-	ImageIcon string                 `json:"imageIcon"` // Icon path
-	Matched   bool                   `json:"matched"`   // Whether it matched
-	Score     float64                `json:"score"`     // Score if scored rule
-	Stats     map[string]interface{} `json:"stats"`     // Item stats
-	Reason    string                 `json:"reason"`    // Why it matched/didn't match
+// SimulatedItem represents an item and what the bot would do with it
+type SimulatedItem struct {
+	ItemName      string         `json:"itemName"`      // Item name
+	Quality       string         `json:"quality"`       // Item quality
+	Ethereal      bool           `json:"ethereal"`      // Whether the item is ethereal
+	Stats         map[string]int `json:"stats"`         // Item stats by NIP property
+	Source        string         `json:"source"`        // synthetic or droplog
+	Outcome       Outcome        `json:"outcome"`       // What the bot does when it sees the item on the ground
+	AfterIdentify Outcome        `json:"afterIdentify"` // What happens after identifying it, only for partial matches
+	Rule          string         `json:"rule"`          // NIP line that decided the outcome
+	RuleFile      string         `json:"ruleFile"`      // file:line of the rule
+	Reason        string         `json:"reason"`        // Why the rule fired or not
 }
 
 // StatPreset represents common stat combinations
@@ -137,8 +138,9 @@ type StatPreset struct {
 
 // ConflictDetection represents detected conflicts between rules
 type ConflictDetection struct {
-	Type        string   `json:"type"`        // Type of conflict (duplicate, overlapping, etc.)
+	Type        string   `json:"type"`        // Type of conflict (duplicate, subsumed, contradiction, etc.)
 	Rules       []string `json:"rules"`       // Conflicting rule IDs
+	Lines       []string `json:"lines"`       // NIP lines of the rules, same order as Rules
 	Severity    string   `json:"severity"`    // Severity (warning, error)
 	Description string   `json:"description"` // Conflict description
 	Suggestion  string   `json:"suggestion"`  // How to resolve
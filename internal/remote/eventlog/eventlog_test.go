@@ -0,0 +1,162 @@
+package eventlog
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+	"github.com/hectorgimenez/koolo/internal/event"
+)
+
+var discardLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func TestRecorderRotation(t *testing.T) {
+	dir := t.TempDir()
+	r := NewRecorder(dir, discardLogger)
+	// Every event goes to a new file
+	r.maxFileSize = 1
+	r.maxFiles = 3
+
+	for _, run := range []string{"andariel", "countess", "mephisto", "pindleskin", "baal"} {
+		if err := r.Record(event.RunStarted(event.Text("sorc", "Starting "+run), run)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := Recordings(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 3 {
+		t.Fatalf("expected the 3 newest recordings to be kept, got %v", files)
+	}
+	// The oldest ones are pruned
+	for i, run := range []string{"mephisto", "pindleskin", "baal"} {
+		lines := readLines(t, files[i])
+		if len(lines) != 1 || !strings.Contains(lines[0], run) {
+			t.Errorf("expected %s to only have the %s event, got %v", files[i], run, lines)
+		}
+	}
+
+	if err := r.Record(event.RunStarted(event.Text("sorc", "Starting cows"), "cows")); !errors.Is(err, errClosed) {
+		t.Errorf("expected events after closing to be discarded, got %v", err)
+	}
+	if files, _ = Recordings(dir); len(files) != 3 {
+		t.Errorf("expected no new recording after closing, got %v", files)
+	}
+}
+
+func TestRecorderKeepsFileUntilFull(t *testing.T) {
+	dir := t.TempDir()
+	r := NewRecorder(dir, discardLogger)
+	for i := 0; i < 10; i++ {
+		if err := r.Record(event.Text("sorc", "Hello")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r.Close()
+
+	files, _ := Recordings(dir)
+	if len(files) != 1 || len(readLines(t, files[0])) != 10 {
+		t.Errorf("expected a single recording with 10 events, got %v", files)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	buf := new(bytes.Buffer)
+	for _, e := range []event.Event{
+		event.RunStarted(event.Text("sorc", "Starting run"), "mephisto"),
+		event.RunFinished(event.Text("pala", "Finished run"), "pindleskin", event.FinishedOK, area.NihlathaksTemple),
+		event.RunFinished(event.Text("sorc", "Finished run"), "mephisto", event.FinishedChicken, area.DuranceOfHateLevel3),
+	} {
+		line, err := event.Marshal(e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf.Write(append(line, '\n'))
+	}
+	// Last line of a recording cut by a crash
+	buf.WriteString(`{"type":"run_fini`)
+
+	var got []event.Event
+	listener := event.NewSyncListener(discardLogger)
+	listener.Register(func(_ context.Context, e event.Event) error {
+		got = append(got, e)
+		return nil
+	})
+
+	count, err := NewReplayer(listener, discardLogger).Replay(context.Background(), bytes.NewReader(buf.Bytes()), ReplayOptions{Supervisor: "sorc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 || len(got) != 2 {
+		t.Fatalf("expected the 2 events of sorc, got %d: %+v", count, got)
+	}
+	finished, ok := got[1].(event.RunFinishedEvent)
+	if !ok || finished.RunName != "mephisto" || finished.Reason != event.FinishedChicken {
+		t.Errorf("unexpected replayed event %+v", got[1])
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err = NewReplayer(listener, discardLogger).Replay(ctx, bytes.NewReader(buf.Bytes()), ReplayOptions{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the replay to stop when the context is done, got %v", err)
+	}
+}
+
+func TestReplayFiles(t *testing.T) {
+	dir := t.TempDir()
+	r := NewRecorder(dir, discardLogger)
+	r.maxFileSize = 1
+	for _, run := range []string{"andariel", "countess"} {
+		if err := r.Record(event.RunStarted(event.Text("sorc", "Starting run"), run)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r.Close()
+
+	var runs []string
+	listener := event.NewSyncListener(discardLogger)
+	event.Subscribe(listener, func(_ context.Context, e event.RunStartedEvent) error {
+		runs = append(runs, e.RunName)
+		return nil
+	})
+
+	files, _ := Recordings(dir)
+	count, err := NewReplayer(listener, discardLogger).ReplayFiles(context.Background(), ReplayOptions{}, files...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 || strings.Join(runs, ",") != "andariel,countess" {
+		t.Errorf("expected both runs in order, got %d events %v", count, runs)
+	}
+}
+
+func readLines(t *testing.T, file string) []string {
+	t.Helper()
+
+	f, err := os.Open(filepath.Clean(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var lines []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		lines = append(lines, sc.Text())
+	}
+
+	return lines
+}
@@ -0,0 +1,177 @@
+package eventlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hectorgimenez/koolo/internal/event"
+)
+
+const (
+	DefaultMaxFileSize = 32 << 20 // Bytes written to a recording before rotating to a new one
+	DefaultMaxFiles    = 20       // Recordings kept on disk, the oldest ones are deleted
+
+	fileTimeFormat = "2006-01-02_15-04-05"
+)
+
+var errClosed = errors.New("event recorder is closed")
+
+// Recorder persists every event sent on the bus to rotating JSONL files, each line is encoded with event.Marshal so a
+// session can be replayed later with a Replayer
+type Recorder struct {
+	dir         string
+	maxFileSize int64
+	maxFiles    int
+	logger      *slog.Logger
+
+	mu     sync.Mutex
+	file   *os.File
+	size   int64
+	closed bool
+	// Name prefix and sequence number of the current recording
+	prefix string
+	seq    int
+}
+
+func NewRecorder(dir string, logger *slog.Logger) *Recorder {
+	return &Recorder{
+		dir:         dir,
+		maxFileSize: DefaultMaxFileSize,
+		maxFiles:    DefaultMaxFiles,
+		logger:      logger,
+	}
+}
+
+// Handle subscribes to the event bus and appends the event to the current recording
+func (r *Recorder) Handle(_ context.Context, e event.Event) error {
+	// Don't break the bot because of recording errors, events still queued when closing are dropped
+	if err := r.Record(e); err != nil && !errors.Is(err, errClosed) {
+		r.logger.Error("Failed to record event", slog.Any("error", err), slog.String("dir", r.dir))
+	}
+
+	return nil
+}
+
+// Record writes a single event, rotating the recording file when it's full
+func (r *Recorder) Record(e event.Event) error {
+	line, err := event.Marshal(e)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return errClosed
+	}
+	if r.file == nil || r.size+int64(len(line)) > r.maxFileSize {
+		if err = r.rotate(); err != nil {
+			return err
+		}
+	}
+
+	// Lines are written straight to the file, a crash never loses more than the event being written
+	n, err := r.file.Write(line)
+	r.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("error writing recording %s: %w", r.file.Name(), err)
+	}
+
+	return nil
+}
+
+// Close closes the current recording, events recorded after that are discarded
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.closed = true
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+
+	return err
+}
+
+func (r *Recorder) rotate() error {
+	if r.file != nil {
+		if err := r.file.Close(); err != nil {
+			r.logger.Warn("Failed to close event recording", slog.Any("error", err), slog.String("file", r.file.Name()))
+		}
+		r.file = nil
+	}
+
+	if err := os.MkdirAll(r.dir, 0o755); err != nil {
+		return fmt.Errorf("error creating recordings directory: %w", err)
+	}
+
+	// Always start a new file, files rotated within the same second get a sequence number that keeps them sorted. It
+	// never goes back, names of pruned recordings would be taken again and sorted before the newer ones.
+	prefix := filepath.Join(r.dir, "events-"+time.Now().Format(fileTimeFormat))
+	seq := 0
+	if prefix == r.prefix {
+		seq = r.seq + 1
+	}
+	name := recordingName(prefix, seq)
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	for os.IsExist(err) && seq < 999 {
+		seq++
+		name = recordingName(prefix, seq)
+		f, err = os.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	}
+	if err != nil {
+		return fmt.Errorf("error creating recording %s: %w", name, err)
+	}
+	r.file = f
+	r.size = 0
+	r.prefix, r.seq = prefix, seq
+
+	r.prune()
+
+	return nil
+}
+
+func recordingName(prefix string, seq int) string {
+	if seq == 0 {
+		return prefix + ".jsonl"
+	}
+
+	return fmt.Sprintf("%s_%03d.jsonl", prefix, seq)
+}
+
+// prune deletes the oldest recordings above maxFiles, the current one included in the count
+func (r *Recorder) prune() {
+	files, err := Recordings(r.dir)
+	if err != nil || len(files) <= r.maxFiles {
+		return
+	}
+
+	for _, file := range files[:len(files)-r.maxFiles] {
+		if err = os.Remove(file); err != nil {
+			r.logger.Warn("Failed to delete old event recording", slog.Any("error", err), slog.String("file", file))
+		}
+	}
+}
+
+// Recordings returns the recording files found in dir, oldest first
+func Recordings(dir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "events-*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	// File names start with the creation time, so lexical order is chronological
+	sort.Strings(files)
+
+	return files, nil
+}
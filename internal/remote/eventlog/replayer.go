@@ -0,0 +1,94 @@
+package eventlog
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hectorgimenez/koolo/internal/event"
+)
+
+// ReplayOptions controls how a recording is fed back to a listener
+type ReplayOptions struct {
+	Speed      float64 // 1 keeps the recorded pace, 2 is twice as fast, 0 or less replays without waiting
+	Supervisor string  // Only replay the events of this supervisor, empty replays all of them
+}
+
+// Replayer feeds recorded events to a Listener, so stats, remote notifications and the dashboard can be reproduced
+// offline from a recorded session. Events keep their original OccurredAt.
+type Replayer struct {
+	listener *event.Listener
+	logger   *slog.Logger
+}
+
+func NewReplayer(listener *event.Listener, logger *slog.Logger) *Replayer {
+	return &Replayer{listener: listener, logger: logger}
+}
+
+// ReplayFiles replays the given recordings in order and returns the number of events dispatched
+func (r *Replayer) ReplayFiles(ctx context.Context, opts ReplayOptions, files ...string) (int, error) {
+	total := 0
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return total, fmt.Errorf("error opening recording %s: %w", file, err)
+		}
+		n, err := r.Replay(ctx, f, opts)
+		f.Close()
+		total += n
+		if err != nil {
+			return total, fmt.Errorf("error replaying %s: %w", file, err)
+		}
+	}
+
+	return total, nil
+}
+
+// Replay dispatches every event read from rd and returns the number of events dispatched. Lines that can't be decoded,
+// like the last one of a recording cut by a crash, are skipped.
+func (r *Replayer) Replay(ctx context.Context, rd io.Reader, opts ReplayOptions) (int, error) {
+	sc := bufio.NewScanner(rd)
+	sc.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+
+	count := 0
+	var previous time.Time
+	for lineNumber := 1; sc.Scan(); lineNumber++ {
+		if err := ctx.Err(); err != nil {
+			return count, err
+		}
+
+		line := sc.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		e, err := event.Unmarshal(line)
+		if err != nil {
+			r.logger.Warn("Skipping unreadable recorded event", slog.Int("line", lineNumber), slog.Any("error", err))
+			continue
+		}
+		if opts.Supervisor != "" && !strings.EqualFold(opts.Supervisor, e.Supervisor()) {
+			continue
+		}
+
+		if opts.Speed > 0 && !previous.IsZero() {
+			if wait := time.Duration(float64(e.OccurredAt().Sub(previous)) / opts.Speed); wait > 0 {
+				select {
+				case <-time.After(wait):
+				case <-ctx.Done():
+					return count, ctx.Err()
+				}
+			}
+		}
+		previous = e.OccurredAt()
+
+		r.listener.Dispatch(e)
+		count++
+	}
+
+	return count, sc.Err()
+}
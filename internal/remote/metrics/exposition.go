@@ -0,0 +1,147 @@
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// labels is a rendered, escaped label set (e.g. `supervisor="foo",run="bar"`), kept as a string so it can be used as
+// a map key
+type labels string
+
+func newLabels(kv ...string) labels {
+	pairs := make([]string, 0, len(kv)/2)
+	for i := 0; i+1 < len(kv); i += 2 {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, kv[i], escapeLabelValue(kv[i+1])))
+	}
+
+	return labels(strings.Join(pairs, ","))
+}
+
+func (l labels) with(kv ...string) labels {
+	extra := newLabels(kv...)
+	if l == "" {
+		return extra
+	}
+
+	return l + "," + extra
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(v string) string {
+	return labelValueReplacer.Replace(v)
+}
+
+type histogram struct {
+	buckets []float64
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+func newHistogram(buckets []float64) *histogram {
+	return &histogram{buckets: buckets, counts: make([]uint64, len(buckets))}
+}
+
+func (h *histogram) observe(v float64) {
+	for i, upper := range h.buckets {
+		if v <= upper {
+			h.counts[i]++
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+// writer renders metric families in the Prometheus text format (0.0.4), or in OpenMetrics 1.0 when openMetrics is
+// set. Both formats only differ in how counters are declared and in the trailing EOF marker.
+type writer struct {
+	buf         bytes.Buffer
+	openMetrics bool
+}
+
+func (w *writer) contentType() string {
+	if w.openMetrics {
+		return "application/openmetrics-text; version=1.0.0; charset=utf-8"
+	}
+
+	return "text/plain; version=0.0.4; charset=utf-8"
+}
+
+// counter writes a counter family, name must not include the _total suffix
+func (w *writer) counter(name, help string, samples map[labels]float64) {
+	family := name + "_total"
+	if w.openMetrics {
+		family = name
+	}
+	w.header(family, help, "counter")
+	for _, l := range sortedLabels(samples) {
+		w.sample(name+"_total", l, samples[l])
+	}
+}
+
+func (w *writer) gauge(name, help string, samples map[labels]float64) {
+	w.header(name, help, "gauge")
+	for _, l := range sortedLabels(samples) {
+		w.sample(name, l, samples[l])
+	}
+}
+
+func (w *writer) histogram(name, help string, samples map[labels]*histogram) {
+	w.header(name, help, "histogram")
+	for _, l := range sortedLabels(samples) {
+		h := samples[l]
+		for i, upper := range h.buckets {
+			w.sample(name+"_bucket", l.with("le", formatFloat(upper)), float64(h.counts[i]))
+		}
+		w.sample(name+"_bucket", l.with("le", "+Inf"), float64(h.count))
+		w.sample(name+"_sum", l, h.sum)
+		w.sample(name+"_count", l, float64(h.count))
+	}
+}
+
+func (w *writer) eof() {
+	if w.openMetrics {
+		w.buf.WriteString("# EOF\n")
+	}
+}
+
+func (w *writer) header(name, help, metricType string) {
+	fmt.Fprintf(&w.buf, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+}
+
+func (w *writer) sample(name string, l labels, value float64) {
+	w.buf.WriteString(name)
+	if l != "" {
+		w.buf.WriteString("{" + string(l) + "}")
+	}
+	w.buf.WriteString(" " + formatFloat(value) + "\n")
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func sortedLabels[V any](samples map[labels]V) []labels {
+	keys := make([]labels, 0, len(samples))
+	for l := range samples {
+		keys = append(keys, l)
+	}
+	slices.Sort(keys)
+
+	return keys
+}
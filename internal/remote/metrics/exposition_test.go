@@ -0,0 +1,125 @@
+package metrics
+
+import "testing"
+
+func TestNewLabelsEscapesValues(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  labels
+	}{
+		{name: "plain", value: "sorc", want: `supervisor="sorc"`},
+		{name: "quote", value: `my "sorc"`, want: `supervisor="my \"sorc\""`},
+		{name: "backslash", value: `C:\Games`, want: `supervisor="C:\\Games"`},
+		{name: "newline", value: "a\nb", want: `supervisor="a\nb"`},
+		{name: "backslash before n", value: `a\nb`, want: `supervisor="a\\nb"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newLabels("supervisor", tt.value); got != tt.want {
+				t.Errorf("newLabels = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLabelsWith(t *testing.T) {
+	if got := labels("").with("le", "+Inf"); got != `le="+Inf"` {
+		t.Errorf("empty with = %s", got)
+	}
+	if got := newLabels("supervisor", "sorc").with("le", "15"); got != `supervisor="sorc",le="15"` {
+		t.Errorf("with = %s", got)
+	}
+}
+
+func TestWriterCounter(t *testing.T) {
+	samples := map[labels]float64{
+		newLabels("supervisor", "sorc"):      3,
+		newLabels("supervisor", "hammerdin"): 1.5,
+	}
+
+	tests := []struct {
+		name        string
+		openMetrics bool
+		want        string
+	}{
+		{
+			name: "prometheus",
+			want: "# HELP koolo_games_created_total Games created.\n" +
+				"# TYPE koolo_games_created_total counter\n" +
+				"koolo_games_created_total{supervisor=\"hammerdin\"} 1.5\n" +
+				"koolo_games_created_total{supervisor=\"sorc\"} 3\n",
+		},
+		{
+			// The family is declared without the suffix, samples keep it
+			name:        "openmetrics",
+			openMetrics: true,
+			want: "# HELP koolo_games_created Games created.\n" +
+				"# TYPE koolo_games_created counter\n" +
+				"koolo_games_created_total{supervisor=\"hammerdin\"} 1.5\n" +
+				"koolo_games_created_total{supervisor=\"sorc\"} 3\n" +
+				"# EOF\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &writer{openMetrics: tt.openMetrics}
+			w.counter("koolo_games_created", "Games created.", samples)
+			w.eof()
+			if got := w.buf.String(); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriterGauge(t *testing.T) {
+	w := &writer{}
+	w.gauge("koolo_ping_milliseconds", "Last ping seen by the ping monitor.", map[labels]float64{
+		newLabels("supervisor", `a"b`): 120,
+	})
+	w.gauge("koolo_event_queue_depth", "Events waiting in the handler queue.", nil)
+
+	want := "# HELP koolo_ping_milliseconds Last ping seen by the ping monitor.\n" +
+		"# TYPE koolo_ping_milliseconds gauge\n" +
+		"koolo_ping_milliseconds{supervisor=\"a\\\"b\"} 120\n" +
+		"# HELP koolo_event_queue_depth Events waiting in the handler queue.\n" +
+		"# TYPE koolo_event_queue_depth gauge\n"
+	if got := w.buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestWriterHistogram(t *testing.T) {
+	h := newHistogram([]float64{30, 60})
+	for _, v := range []float64{10, 45, 90} {
+		h.observe(v)
+	}
+
+	w := &writer{}
+	w.histogram("koolo_run_duration_seconds", "Run duration by run name.", map[labels]*histogram{
+		newLabels("supervisor", "sorc", "run", "andariel"): h,
+	})
+
+	want := "# HELP koolo_run_duration_seconds Run duration by run name.\n" +
+		"# TYPE koolo_run_duration_seconds histogram\n" +
+		"koolo_run_duration_seconds_bucket{supervisor=\"sorc\",run=\"andariel\",le=\"30\"} 1\n" +
+		"koolo_run_duration_seconds_bucket{supervisor=\"sorc\",run=\"andariel\",le=\"60\"} 2\n" +
+		"koolo_run_duration_seconds_bucket{supervisor=\"sorc\",run=\"andariel\",le=\"+Inf\"} 3\n" +
+		"koolo_run_duration_seconds_sum{supervisor=\"sorc\",run=\"andariel\"} 145\n" +
+		"koolo_run_duration_seconds_count{supervisor=\"sorc\",run=\"andariel\"} 3\n"
+	if got := w.buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestWriterContentType(t *testing.T) {
+	if got := (&writer{}).contentType(); got != "text/plain; version=0.0.4; charset=utf-8" {
+		t.Errorf("prometheus content type = %s", got)
+	}
+	if got := (&writer{openMetrics: true}).contentType(); got != "application/openmetrics-text; version=1.0.0; charset=utf-8" {
+		t.Errorf("openmetrics content type = %s", got)
+	}
+}
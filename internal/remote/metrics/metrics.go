@@ -0,0 +1,143 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hectorgimenez/koolo/internal/bot"
+	"github.com/hectorgimenez/koolo/internal/event"
+)
+
+// Run duration buckets in seconds, from short boss runs to full leveling sequences
+var runDurationBuckets = []float64{15, 30, 60, 120, 180, 300, 600, 1200, 1800, 3600}
+
+var supervisorStatuses = []bot.SupervisorStatus{bot.NotStarted, bot.Starting, bot.InGame, bot.Paused, bot.Crashed}
+
+// Collector aggregates event bus events into Prometheus metrics and serves them in the text exposition format, or
+// OpenMetrics when the scraper asks for it. Gauges (status, ping, event queues) are read from the supervisor manager
+// and the event listener on scrape.
+type Collector struct {
+	manager  *bot.SupervisorManager
+	listener *event.Listener
+
+	mu            sync.Mutex
+	gamesCreated  map[labels]float64
+	runsFinished  map[labels]float64
+	runDurations  map[labels]*histogram
+	potionsUsed   map[labels]float64
+	itemsStashed  map[labels]float64
+	clientCrashes map[labels]float64
+	runStartedAt  map[string]time.Time
+}
+
+func NewCollector(manager *bot.SupervisorManager, listener *event.Listener) *Collector {
+	return &Collector{
+		manager:       manager,
+		listener:      listener,
+		gamesCreated:  make(map[labels]float64),
+		runsFinished:  make(map[labels]float64),
+		runDurations:  make(map[labels]*histogram),
+		potionsUsed:   make(map[labels]float64),
+		itemsStashed:  make(map[labels]float64),
+		clientCrashes: make(map[labels]float64),
+		runStartedAt:  make(map[string]time.Time),
+	}
+}
+
+func (c *Collector) Handle(_ context.Context, e event.Event) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	sup := e.Supervisor()
+	switch evt := e.(type) {
+	case event.GameCreatedEvent:
+		c.gamesCreated[newLabels("supervisor", sup)]++
+	case event.RunStartedEvent:
+		c.runStartedAt[sup] = evt.OccurredAt()
+	case event.RunFinishedEvent:
+		c.runsFinished[newLabels("supervisor", sup, "run", evt.RunName, "reason", string(evt.Reason))]++
+		if startedAt, found := c.runStartedAt[sup]; found {
+			delete(c.runStartedAt, sup)
+			l := newLabels("supervisor", sup, "run", evt.RunName)
+			h, found := c.runDurations[l]
+			if !found {
+				h = newHistogram(runDurationBuckets)
+				c.runDurations[l] = h
+			}
+			h.observe(evt.OccurredAt().Sub(startedAt).Seconds())
+		}
+	case event.UsedPotionEvent:
+		c.potionsUsed[newLabels("supervisor", sup, "type", string(evt.PotionType), "merc", strconv.FormatBool(evt.OnMerc))]++
+	case event.ItemStashedEvent:
+		c.itemsStashed[newLabels("supervisor", sup, "quality", evt.Item.Item.Quality.ToString())]++
+	case event.ClientCrashedEvent:
+		c.clientCrashes[newLabels("supervisor", sup)]++
+	}
+
+	return nil
+}
+
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	mw := &writer{openMetrics: strings.Contains(r.Header.Get("Accept"), "application/openmetrics-text")}
+	c.write(mw)
+
+	w.Header().Set("Content-Type", mw.contentType())
+	w.Write(mw.buf.Bytes())
+}
+
+func (c *Collector) write(w *writer) {
+	c.mu.Lock()
+	w.counter("koolo_games_created", "Games created.", c.gamesCreated)
+	w.counter("koolo_runs_finished", "Runs finished by reason.", c.runsFinished)
+	w.histogram("koolo_run_duration_seconds", "Run duration by run name.", c.runDurations)
+	w.counter("koolo_potions_used", "Potions used by type.", c.potionsUsed)
+	w.counter("koolo_items_stashed", "Items stashed by quality.", c.itemsStashed)
+	w.counter("koolo_client_crash_restarts", "Supervisor restarts triggered by the crash detector.", c.clientCrashes)
+	c.mu.Unlock()
+
+	status := make(map[labels]float64)
+	ping := make(map[labels]float64)
+	for _, sup := range c.manager.AvailableSupervisors() {
+		current := c.manager.Status(sup).SupervisorStatus
+		if current == "" {
+			current = bot.NotStarted
+		}
+		for _, st := range supervisorStatuses {
+			value := 0.0
+			if st == current {
+				value = 1
+			}
+			status[newLabels("supervisor", sup, "status", string(st))] = value
+		}
+
+		if ctx := c.manager.GetContext(sup); ctx != nil {
+			if pm := ctx.PingMonitor(); pm != nil {
+				ping[newLabels("supervisor", sup)] = float64(pm.LastPing())
+			}
+		}
+	}
+	w.gauge("koolo_supervisor_status", "Current supervisor status, 1 for the active status.", status)
+	w.gauge("koolo_ping_milliseconds", "Last ping seen by the ping monitor.", ping)
+
+	// Handlers registered more than once under the same name are added up
+	depth := make(map[labels]float64)
+	capacity := make(map[labels]float64)
+	dropped := make(map[labels]float64)
+	timeouts := make(map[labels]float64)
+	for _, qs := range c.listener.QueueStats() {
+		l := newLabels("handler", qs.Handler)
+		depth[l] += float64(qs.Depth)
+		capacity[l] += float64(qs.Capacity)
+		dropped[l] += float64(qs.Dropped)
+		timeouts[l] += float64(qs.Timeouts)
+	}
+	w.gauge("koolo_event_queue_depth", "Events waiting in the handler queue.", depth)
+	w.gauge("koolo_event_queue_capacity", "Size of the handler queue.", capacity)
+	w.counter("koolo_event_dropped", "Events dropped because the handler queue was full.", dropped)
+	w.counter("koolo_event_handler_timeouts", "Handler calls that took longer than their timeout.", timeouts)
+	w.eof()
+}
@@ -0,0 +1,293 @@
+package runlog
+
+import (
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+	"github.com/hectorgimenez/d2go/pkg/data/difficulty"
+	"github.com/hectorgimenez/koolo/internal/event"
+)
+
+// Query filters stored records, zero values mean no filter
+type Query struct {
+	From        time.Time
+	To          time.Time
+	Supervisors []string
+}
+
+func (q Query) matches(rec Record) bool {
+	if !q.From.IsZero() && rec.Time.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && rec.Time.After(q.To) {
+		return false
+	}
+	if len(q.Supervisors) == 0 {
+		return true
+	}
+
+	return slices.ContainsFunc(q.Supervisors, func(sup string) bool {
+		return strings.EqualFold(sup, rec.Supervisor)
+	})
+}
+
+// overlapsSegment checks the date encoded in the segment file name against the query range, files with unexpected
+// names are always read
+func (q Query) overlapsSegment(file string) bool {
+	name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(file), "runlog-"), ".jsonl")
+	day, err := time.ParseInLocation(segmentDateFormat, name, time.Local)
+	if err != nil {
+		return true
+	}
+	if !q.From.IsZero() && !day.AddDate(0, 0, 1).After(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && day.After(q.To) {
+		return false
+	}
+
+	return true
+}
+
+// Run is a finished run rebuilt from its stored events
+type Run struct {
+	Supervisor  string                `json:"supervisor"`
+	Character   string                `json:"character"`
+	Difficulty  difficulty.Difficulty `json:"difficulty"`
+	Name        string                `json:"name"`
+	Area        area.ID               `json:"area"` // Area the run finished in
+	Reason      event.FinishReason    `json:"reason"`
+	StartedAt   time.Time             `json:"startedAt"`
+	FinishedAt  time.Time             `json:"finishedAt"`
+	UsedPotions int                   `json:"usedPotions"`
+}
+
+func (r Run) Duration() time.Duration {
+	return r.FinishedAt.Sub(r.StartedAt)
+}
+
+// Runs returns the runs started within the query range. Runs without a finish event are closed by the next game
+// finished event of the same supervisor, runs never closed (e.g. Koolo was killed) are discarded.
+func (s *Store) Runs(q Query) ([]Run, error) {
+	// Runs started before q.To may finish after it
+	records, err := s.Records(Query{From: q.From, Supervisors: q.Supervisors})
+	if err != nil {
+		return nil, err
+	}
+
+	return buildRuns(records, q.To), nil
+}
+
+func buildRuns(records []Record, to time.Time) []Run {
+	var runs []Run
+	open := make(map[string]*Run)
+	closeRun := func(sup string, rec Record) {
+		r, found := open[sup]
+		if !found {
+			return
+		}
+		delete(open, sup)
+		r.FinishedAt = rec.Time
+		r.Reason = rec.Reason
+		r.Area = rec.Area
+		runs = append(runs, *r)
+	}
+
+	for _, rec := range records {
+		sup := strings.ToLower(rec.Supervisor)
+		switch rec.Kind {
+		case KindRunStarted:
+			if !to.IsZero() && rec.Time.After(to) {
+				delete(open, sup)
+				continue
+			}
+			open[sup] = &Run{
+				Supervisor: rec.Supervisor,
+				Character:  rec.Character,
+				Difficulty: rec.Difficulty,
+				Name:       rec.Run,
+				StartedAt:  rec.Time,
+			}
+		case KindUsedPotion:
+			if r, found := open[sup]; found {
+				r.UsedPotions++
+			}
+		case KindRunFinished, KindGameFinished:
+			closeRun(sup, rec)
+		}
+	}
+
+	return runs
+}
+
+// Rate counts how runs ended for a group of runs
+type Rate struct {
+	Runs        int     `json:"runs"`
+	Deaths      int     `json:"deaths"`
+	Chickens    int     `json:"chickens"`
+	Errors      int     `json:"errors"`
+	DeathRate   float64 `json:"deathRate"`
+	ChickenRate float64 `json:"chickenRate"`
+	ErrorRate   float64 `json:"errorRate"`
+}
+
+func (r *Rate) add(run Run) {
+	r.Runs++
+	switch run.Reason {
+	case event.FinishedDied:
+		r.Deaths++
+	case event.FinishedChicken, event.FinishedMercChicken:
+		r.Chickens++
+	case event.FinishedError:
+		r.Errors++
+	}
+	r.DeathRate = float64(r.Deaths) / float64(r.Runs)
+	r.ChickenRate = float64(r.Chickens) / float64(r.Runs)
+	r.ErrorRate = float64(r.Errors) / float64(r.Runs)
+}
+
+// RatesByRun groups runs by run name
+func RatesByRun(runs []Run) map[string]Rate {
+	return rates(runs, func(r Run) string { return r.Name })
+}
+
+// RatesByArea groups runs by the area they finished in
+func RatesByArea(runs []Run) map[area.ID]Rate {
+	return rates(runs, func(r Run) area.ID { return r.Area })
+}
+
+func RatesByDifficulty(runs []Run) map[difficulty.Difficulty]Rate {
+	return rates(runs, func(r Run) difficulty.Difficulty { return r.Difficulty })
+}
+
+func rates[K comparable](runs []Run, key func(Run) K) map[K]Rate {
+	out := make(map[K]Rate)
+	for _, r := range runs {
+		rate := out[key(r)]
+		rate.add(r)
+		out[key(r)] = rate
+	}
+
+	return out
+}
+
+// MedianRunTime returns the median duration of the successful runs, grouped by run name. Failed runs are left out,
+// a death in the first seconds of a run would make it look faster than it is.
+func MedianRunTime(runs []Run) map[string]time.Duration {
+	durations := make(map[string][]time.Duration)
+	for _, r := range runs {
+		if r.Reason == event.FinishedOK {
+			durations[r.Name] = append(durations[r.Name], r.Duration())
+		}
+	}
+
+	out := make(map[string]time.Duration, len(durations))
+	for name, d := range durations {
+		slices.Sort(d)
+		if len(d)%2 == 1 {
+			out[name] = d[len(d)/2]
+		} else {
+			out[name] = (d[len(d)/2-1] + d[len(d)/2]) / 2
+		}
+	}
+
+	return out
+}
+
+// RunsPerHour is the number of finished runs divided by the time span between the first run start and the last run
+// finish
+func RunsPerHour(runs []Run) float64 {
+	if len(runs) == 0 {
+		return 0
+	}
+
+	first, last := runs[0].StartedAt, runs[0].FinishedAt
+	for _, r := range runs[1:] {
+		if r.StartedAt.Before(first) {
+			first = r.StartedAt
+		}
+		if r.FinishedAt.After(last) {
+			last = r.FinishedAt
+		}
+	}
+
+	hours := last.Sub(first).Hours()
+	if hours <= 0 {
+		return 0
+	}
+
+	return float64(len(runs)) / hours
+}
+
+// HourlyRuns is the number of runs finished within the hour starting at Hour
+type HourlyRuns struct {
+	Hour time.Time `json:"hour"`
+	Runs int       `json:"runs"`
+}
+
+// RunsByHour buckets runs by the hour they finished in, hours without runs are omitted
+func RunsByHour(runs []Run) []HourlyRuns {
+	var out []HourlyRuns
+	idx := make(map[time.Time]int)
+	for _, r := range runs {
+		hour := r.FinishedAt.Truncate(time.Hour)
+		i, found := idx[hour]
+		if !found {
+			i = len(out)
+			idx[hour] = i
+			out = append(out, HourlyRuns{Hour: hour})
+		}
+		out[i].Runs++
+	}
+	slices.SortFunc(out, func(a, b HourlyRuns) int { return a.Hour.Compare(b.Hour) })
+
+	return out
+}
+
+// Summary aggregates the runs matching a query, keyed by names so it can be served as JSON directly
+type Summary struct {
+	From             time.Time                      `json:"from"`
+	To               time.Time                      `json:"to"`
+	Supervisors      []string                       `json:"supervisors,omitempty"`
+	Runs             int                            `json:"runs"`
+	RunsPerHour      float64                        `json:"runsPerHour"`
+	Hourly           []HourlyRuns                   `json:"hourly"`
+	MedianRunSeconds map[string]float64             `json:"medianRunSeconds"`
+	ByArea           map[string]Rate                `json:"byArea"`
+	ByDifficulty     map[difficulty.Difficulty]Rate `json:"byDifficulty"`
+}
+
+func (s *Store) Summary(q Query) (Summary, error) {
+	runs, err := s.Runs(q)
+	if err != nil {
+		return Summary{}, err
+	}
+
+	sum := Summary{
+		From:             q.From,
+		To:               q.To,
+		Supervisors:      q.Supervisors,
+		Runs:             len(runs),
+		RunsPerHour:      RunsPerHour(runs),
+		Hourly:           RunsByHour(runs),
+		MedianRunSeconds: make(map[string]float64),
+		ByArea:           make(map[string]Rate),
+		ByDifficulty:     RatesByDifficulty(runs),
+	}
+	for name, d := range MedianRunTime(runs) {
+		sum.MedianRunSeconds[name] = d.Seconds()
+	}
+	for id, rate := range RatesByArea(runs) {
+		name := id.Area().Name
+		if name == "" {
+			name = strconv.Itoa(int(id))
+		}
+		sum.ByArea[name] = rate
+	}
+
+	return sum, nil
+}
@@ -0,0 +1,209 @@
+package runlog
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hectorgimenez/koolo/internal/event"
+)
+
+var day = time.Date(2025, 3, 14, 0, 0, 0, 0, time.Local)
+
+func at(days int, hour, minute int) time.Time {
+	return day.AddDate(0, 0, days).Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+}
+
+// newTestStore writes the records to daily segments in a temporary directory
+func newTestStore(t *testing.T, records ...Record) *Store {
+	t.Helper()
+
+	s := NewStore(t.TempDir(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	for _, rec := range records {
+		if err := s.Append(rec); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+
+	return s
+}
+
+func testRecords() []Record {
+	return []Record{
+		{Time: at(0, 10, 0), Kind: KindRunStarted, Supervisor: "Sorc", Run: "andariel"},
+		{Time: at(0, 10, 1), Kind: KindUsedPotion, Supervisor: "sorc"},
+		{Time: at(0, 10, 2), Kind: KindRunFinished, Supervisor: "sorc", Run: "andariel", Reason: event.FinishedOK},
+		{Time: at(0, 10, 3), Kind: KindRunStarted, Supervisor: "sorc", Run: "andariel"},
+		{Time: at(0, 10, 7), Kind: KindRunFinished, Supervisor: "sorc", Run: "andariel", Reason: event.FinishedOK},
+		{Time: at(0, 10, 8), Kind: KindRunStarted, Supervisor: "sorc", Run: "mephisto"},
+		// Closed by the game finished event
+		{Time: at(0, 10, 9), Kind: KindGameFinished, Supervisor: "sorc", Reason: event.FinishedDied},
+		{Time: at(1, 9, 0), Kind: KindRunStarted, Supervisor: "hammerdin", Run: "pindleskin"},
+		{Time: at(1, 9, 0), Kind: KindRunStarted, Supervisor: "sorc", Run: "mephisto"},
+		{Time: at(1, 9, 4), Kind: KindRunFinished, Supervisor: "sorc", Run: "mephisto", Reason: event.FinishedOK},
+		// Never closed, Koolo was killed
+		{Time: at(1, 9, 5), Kind: KindRunStarted, Supervisor: "sorc", Run: "andariel"},
+		{Time: at(1, 9, 6), Kind: KindRunFinished, Supervisor: "hammerdin", Run: "pindleskin", Reason: event.FinishedError},
+	}
+}
+
+func TestStoreWritesDailySegments(t *testing.T) {
+	s := newTestStore(t, testRecords()...)
+
+	for _, d := range []int{0, 1} {
+		if _, err := os.Stat(filepath.Join(s.dir, segmentName(at(d, 12, 0)))); err != nil {
+			t.Errorf("segment for day %d: %v", d, err)
+		}
+	}
+}
+
+func TestRuns(t *testing.T) {
+	s := newTestStore(t, testRecords()...)
+
+	tests := []struct {
+		name  string
+		query Query
+		want  []Run
+	}{
+		{
+			name:  "all",
+			query: Query{},
+			want: []Run{
+				{Supervisor: "Sorc", Name: "andariel", Reason: event.FinishedOK, StartedAt: at(0, 10, 0), FinishedAt: at(0, 10, 2), UsedPotions: 1},
+				{Supervisor: "sorc", Name: "andariel", Reason: event.FinishedOK, StartedAt: at(0, 10, 3), FinishedAt: at(0, 10, 7)},
+				{Supervisor: "sorc", Name: "mephisto", Reason: event.FinishedDied, StartedAt: at(0, 10, 8), FinishedAt: at(0, 10, 9)},
+				{Supervisor: "sorc", Name: "mephisto", Reason: event.FinishedOK, StartedAt: at(1, 9, 0), FinishedAt: at(1, 9, 4)},
+				{Supervisor: "hammerdin", Name: "pindleskin", Reason: event.FinishedError, StartedAt: at(1, 9, 0), FinishedAt: at(1, 9, 6)},
+			},
+		},
+		{
+			name:  "from skips the previous day",
+			query: Query{From: at(1, 0, 0)},
+			want: []Run{
+				{Supervisor: "sorc", Name: "mephisto", Reason: event.FinishedOK, StartedAt: at(1, 9, 0), FinishedAt: at(1, 9, 4)},
+				{Supervisor: "hammerdin", Name: "pindleskin", Reason: event.FinishedError, StartedAt: at(1, 9, 0), FinishedAt: at(1, 9, 6)},
+			},
+		},
+		{
+			name:  "run started before to finishes after it",
+			query: Query{From: at(1, 0, 0), To: at(1, 9, 2), Supervisors: []string{"SORC"}},
+			want: []Run{
+				{Supervisor: "sorc", Name: "mephisto", Reason: event.FinishedOK, StartedAt: at(1, 9, 0), FinishedAt: at(1, 9, 4)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runs, err := s.Runs(tt.query)
+			if err != nil {
+				t.Fatalf("Runs: %v", err)
+			}
+			if len(runs) != len(tt.want) {
+				t.Fatalf("got %d runs, want %d: %+v", len(runs), len(tt.want), runs)
+			}
+			for i, want := range tt.want {
+				got := runs[i]
+				if got.Supervisor != want.Supervisor || got.Name != want.Name || got.Reason != want.Reason ||
+					!got.StartedAt.Equal(want.StartedAt) || !got.FinishedAt.Equal(want.FinishedAt) || got.UsedPotions != want.UsedPotions {
+					t.Errorf("run %d = %+v, want %+v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildRunsDiscardsRunsStartedAfterTo(t *testing.T) {
+	runs := buildRuns([]Record{
+		{Time: at(0, 10, 0), Kind: KindRunStarted, Supervisor: "sorc", Run: "andariel"},
+		{Time: at(0, 10, 2), Kind: KindRunFinished, Supervisor: "sorc", Run: "andariel", Reason: event.FinishedOK},
+		{Time: at(0, 11, 0), Kind: KindRunStarted, Supervisor: "sorc", Run: "mephisto"},
+		{Time: at(0, 11, 2), Kind: KindRunFinished, Supervisor: "sorc", Run: "mephisto", Reason: event.FinishedOK},
+	}, at(0, 10, 30))
+
+	if len(runs) != 1 || runs[0].Name != "andariel" {
+		t.Errorf("got %+v, want only andariel", runs)
+	}
+}
+
+func TestMedianRunTime(t *testing.T) {
+	s := newTestStore(t, testRecords()...)
+	runs, err := s.Runs(Query{})
+	if err != nil {
+		t.Fatalf("Runs: %v", err)
+	}
+
+	got := MedianRunTime(runs)
+	want := map[string]time.Duration{
+		// Even count, average of both
+		"andariel": 3 * time.Minute,
+		// The death isn't counted
+		"mephisto": 4 * time.Minute,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for name, d := range want {
+		if got[name] != d {
+			t.Errorf("%s = %s, want %s", name, got[name], d)
+		}
+	}
+}
+
+func TestRunsPerHour(t *testing.T) {
+	tests := []struct {
+		name string
+		runs []Run
+		want float64
+	}{
+		{name: "no runs", want: 0},
+		{
+			name: "single instant run",
+			runs: []Run{{StartedAt: at(0, 10, 0), FinishedAt: at(0, 10, 0)}},
+			want: 0,
+		},
+		{
+			name: "three runs in half an hour",
+			runs: []Run{
+				{StartedAt: at(0, 10, 10), FinishedAt: at(0, 10, 20)},
+				{StartedAt: at(0, 10, 0), FinishedAt: at(0, 10, 10)},
+				{StartedAt: at(0, 10, 20), FinishedAt: at(0, 10, 30)},
+			},
+			want: 6,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RunsPerHour(tt.runs); got != tt.want {
+				t.Errorf("RunsPerHour = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRatesByRun(t *testing.T) {
+	s := newTestStore(t, testRecords()...)
+	runs, err := s.Runs(Query{})
+	if err != nil {
+		t.Fatalf("Runs: %v", err)
+	}
+
+	got := RatesByRun(runs)
+	want := map[string]Rate{
+		"andariel":   {Runs: 2},
+		"mephisto":   {Runs: 2, Deaths: 1, DeathRate: 0.5},
+		"pindleskin": {Runs: 1, Errors: 1, ErrorRate: 1},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for name, rate := range want {
+		if got[name] != rate {
+			t.Errorf("%s = %+v, want %+v", name, got[name], rate)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package runlog
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+	"github.com/hectorgimenez/d2go/pkg/data/difficulty"
+	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/event"
+)
+
+const segmentDateFormat = "2006-01-02"
+
+type Kind string
+
+const (
+	KindRunStarted   Kind = "run_started"
+	KindRunFinished  Kind = "run_finished"
+	KindGameFinished Kind = "game_finished"
+	KindUsedPotion   Kind = "used_potion"
+)
+
+// Record is the persisted representation of a single run lifecycle event, one JSON line per event.
+type Record struct {
+	Time       time.Time             `json:"time"`
+	Kind       Kind                  `json:"kind"`
+	Supervisor string                `json:"supervisor"`
+	Character  string                `json:"character,omitempty"` // in-game character name
+	Difficulty difficulty.Difficulty `json:"difficulty,omitempty"`
+	Run        string                `json:"run,omitempty"`
+	Area       area.ID               `json:"area,omitempty"`
+	Reason     event.FinishReason    `json:"reason,omitempty"`
+	Potion     data.PotionType       `json:"potion,omitempty"`
+	OnMerc     bool                  `json:"onMerc,omitempty"`
+}
+
+// Store persists run events to append-only daily segment files and answers historical queries over them.
+type Store struct {
+	dir    string
+	logger *slog.Logger
+	mu     sync.Mutex
+}
+
+func NewStore(dir string, logger *slog.Logger) *Store {
+	return &Store{dir: dir, logger: logger}
+}
+
+// Handle subscribes to the event bus and appends run related events to the segment of the day they occurred.
+func (s *Store) Handle(_ context.Context, e event.Event) error {
+	rec := Record{
+		Time:       e.OccurredAt(),
+		Supervisor: e.Supervisor(),
+	}
+
+	switch evt := e.(type) {
+	case event.RunStartedEvent:
+		rec.Kind = KindRunStarted
+		rec.Run = evt.RunName
+	case event.RunFinishedEvent:
+		rec.Kind = KindRunFinished
+		rec.Run = evt.RunName
+		rec.Reason = evt.Reason
+		rec.Area = evt.Area
+	case event.GameFinishedEvent:
+		rec.Kind = KindGameFinished
+		rec.Reason = evt.Reason
+		rec.Area = evt.Area
+	case event.UsedPotionEvent:
+		rec.Kind = KindUsedPotion
+		rec.Potion = evt.PotionType
+		rec.OnMerc = evt.OnMerc
+	default:
+		return nil
+	}
+
+	if cfg, found := config.GetCharacter(rec.Supervisor); found && cfg != nil {
+		rec.Character = cfg.CharacterName
+		rec.Difficulty = cfg.Game.Difficulty
+	}
+
+	// Don't break the bot because of stats persistence errors
+	if err := s.Append(rec); err != nil {
+		s.logger.Error("Failed to persist run stats", slog.Any("error", err), slog.String("dir", s.dir))
+	}
+
+	return nil
+}
+
+// Append writes a record to the segment file matching its date
+func (s *Store) Append(rec Record) error {
+	enc, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("error encoding record: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err = os.MkdirAll(s.dir, 0o755); err != nil {
+		return fmt.Errorf("error creating runlog directory: %w", err)
+	}
+
+	file := filepath.Join(s.dir, segmentName(rec.Time))
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("error opening segment %s: %w", file, err)
+	}
+	defer f.Close()
+
+	if _, err = f.Write(append(enc, '\n')); err != nil {
+		return fmt.Errorf("error writing segment %s: %w", file, err)
+	}
+
+	return nil
+}
+
+// Records returns every stored record matching the query, sorted by time. Only the segments overlapping the query
+// date range are read.
+func (s *Store) Records(q Query) ([]Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	files, err := filepath.Glob(filepath.Join(s.dir, "runlog-*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	var out []Record
+	for _, file := range files {
+		if !q.overlapsSegment(file) {
+			continue
+		}
+		records, err := readSegment(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, rec := range records {
+			if q.matches(rec) {
+				out = append(out, rec)
+			}
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Time.Before(out[j].Time) })
+
+	return out, nil
+}
+
+func readSegment(file string) ([]Record, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("error opening segment %s: %w", file, err)
+	}
+	defer f.Close()
+
+	var out []Record
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		var rec Record
+		// A partially written line (e.g. the bot was killed mid write) is skipped instead of failing the whole query
+		if err := json.Unmarshal([]byte(line), &rec); err == nil {
+			out = append(out, rec)
+		}
+	}
+
+	return out, sc.Err()
+}
+
+func segmentName(t time.Time) string {
+	return fmt.Sprintf("runlog-%s.jsonl", t.Local().Format(segmentDateFormat))
+}
@@ -0,0 +1,242 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/jpeg"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/event"
+)
+
+const (
+	defaultMaxRetries = 3
+	initialBackoff    = time.Second
+	maxBackoff        = 30 * time.Second
+)
+
+// Notifier POSTs the events matching its filter to a single webhook URL
+type Notifier struct {
+	cfg    config.WebhookCfg
+	events map[string]bool
+	tmpl   *template.Template
+	client *http.Client
+	logger *slog.Logger
+	// First wait between retries, doubled after every attempt
+	backoff time.Duration
+}
+
+// templateData is what the body template is executed with, event specific fields are reachable through .Event with
+// the secrets cleared like in the default body
+type templateData struct {
+	Type       string
+	Supervisor string
+	Message    string
+	OccurredAt time.Time
+	HasImage   bool
+	Event      event.Event
+}
+
+func NewNotifier(cfg config.WebhookCfg, logger *slog.Logger) (*Notifier, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("webhook %s has no url", cfg.Name)
+	}
+	if cfg.MaxRetries <= 0 {
+		cfg.MaxRetries = defaultMaxRetries
+	}
+
+	n := &Notifier{
+		cfg:     cfg,
+		events:  make(map[string]bool, len(cfg.Events)),
+		client:  &http.Client{Timeout: 10 * time.Second},
+		logger:  logger,
+		backoff: initialBackoff,
+	}
+	for _, tag := range cfg.Events {
+		n.events[strings.ToLower(strings.TrimSpace(tag))] = true
+	}
+
+	if cfg.Template != "" {
+		tmpl, err := template.New(cfg.Name).Funcs(template.FuncMap{"json": toJSON}).Parse(cfg.Template)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing template of webhook %s: %w", cfg.Name, err)
+		}
+		n.tmpl = tmpl
+	}
+
+	return n, nil
+}
+
+func (n *Notifier) Name() string {
+	return n.cfg.Name
+}
+
+func (n *Notifier) Handle(ctx context.Context, e event.Event) error {
+	tag := event.TypeTag(e)
+	if tag == "" || (len(n.events) > 0 && !n.events[tag]) {
+		return nil
+	}
+
+	payload, err := n.payload(e, tag)
+	if err != nil {
+		return err
+	}
+	body, contentType, err := n.body(e, payload)
+	if err != nil {
+		return err
+	}
+
+	return n.post(ctx, tag, body, contentType)
+}
+
+func (n *Notifier) payload(e event.Event, tag string) ([]byte, error) {
+	if n.tmpl == nil {
+		return event.Marshal(e)
+	}
+
+	buf := new(bytes.Buffer)
+	err := n.tmpl.Execute(buf, templateData{
+		Type:       tag,
+		Supervisor: e.Supervisor(),
+		Message:    e.Message(),
+		OccurredAt: e.OccurredAt(),
+		HasImage:   e.Image() != nil,
+		Event:      event.Redact(e),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error rendering template of webhook %s: %w", n.cfg.Name, err)
+	}
+	if !json.Valid(buf.Bytes()) {
+		return nil, fmt.Errorf("template of webhook %s didn't render valid JSON", n.cfg.Name)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// body wraps the payload in a multipart form when the screenshot has to be attached, using the same payload_json field
+// name Discord expects
+func (n *Notifier) body(e event.Event, payload []byte) ([]byte, string, error) {
+	if !n.cfg.Screenshot || e.Image() == nil {
+		return payload, "application/json", nil
+	}
+
+	buf := new(bytes.Buffer)
+	mw := multipart.NewWriter(buf)
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", `form-data; name="payload_json"`)
+	header.Set("Content-Type", "application/json")
+	part, err := mw.CreatePart(header)
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err = part.Write(payload); err != nil {
+		return nil, "", err
+	}
+
+	header = make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", `form-data; name="screenshot"; filename="screenshot.jpeg"`)
+	header.Set("Content-Type", "image/jpeg")
+	part, err = mw.CreatePart(header)
+	if err != nil {
+		return nil, "", err
+	}
+	if err = jpeg.Encode(part, e.Image(), &jpeg.Options{Quality: 80}); err != nil {
+		return nil, "", fmt.Errorf("error encoding screenshot: %w", err)
+	}
+
+	if err = mw.Close(); err != nil {
+		return nil, "", err
+	}
+
+	return buf.Bytes(), mw.FormDataContentType(), nil
+}
+
+// post sends the request, retrying with exponential backoff on network errors, 429 and 5xx responses
+func (n *Notifier) post(ctx context.Context, tag string, body []byte, contentType string) error {
+	backoff := n.backoff
+	for attempt := 0; ; attempt++ {
+		retryAfter, err := n.send(ctx, tag, body, contentType)
+		if err == nil {
+			return nil
+		}
+		if retryAfter < 0 || attempt >= n.cfg.MaxRetries {
+			return fmt.Errorf("error sending webhook %s: %w", n.cfg.Name, err)
+		}
+
+		wait := max(backoff, retryAfter)
+		n.logger.Debug("Webhook failed, retrying", slog.String("webhook", n.cfg.Name), slog.Duration("in", wait), slog.Any("error", err))
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return fmt.Errorf("error sending webhook %s: %w", n.cfg.Name, errors.Join(err, ctx.Err()))
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// send makes a single attempt, a negative retryAfter means the request must not be retried
+func (n *Notifier) send(ctx context.Context, tag string, body []byte, contentType string) (retryAfter time.Duration, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return -1, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("User-Agent", "koolo/"+config.Version)
+	req.Header.Set("X-Koolo-Event", tag)
+	for k, v := range n.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	if n.cfg.Secret != "" {
+		req.Header.Set("X-Koolo-Signature", "sha256="+Sign(n.cfg.Secret, body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return -1, err
+		}
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	switch {
+	case resp.StatusCode < 300:
+		return 0, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		seconds, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+		return min(time.Duration(seconds)*time.Second, maxBackoff), fmt.Errorf("unexpected status %s", resp.Status)
+	default:
+		return -1, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of body, receivers compare it with the X-Koolo-Signature header
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func toJSON(v any) (string, error) {
+	b, err := json.Marshal(v)
+
+	return string(b), err
+}
@@ -0,0 +1,150 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/event"
+)
+
+func testNotifier(t *testing.T, cfg config.WebhookCfg) *Notifier {
+	t.Helper()
+
+	n, err := NewNotifier(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	n.backoff = time.Millisecond
+
+	return n
+}
+
+func TestSignature(t *testing.T) {
+	var signature, eventTag string
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature, eventTag = r.Header.Get("X-Koolo-Signature"), r.Header.Get("X-Koolo-Event")
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	n := testNotifier(t, config.WebhookCfg{Name: "test", URL: srv.URL, Secret: "s3cr3t"})
+	if err := n.Handle(context.Background(), event.RunStarted(event.Text("char", "Starting run"), "countess")); err != nil {
+		t.Fatal(err)
+	}
+
+	if eventTag != "run_started" {
+		t.Errorf("expected the run_started tag, got %q", eventTag)
+	}
+	if len(body) == 0 {
+		t.Fatal("expected a body")
+	}
+	if expected := "sha256=" + Sign("s3cr3t", body); signature != expected {
+		t.Errorf("expected signature %s, got %s", expected, signature)
+	}
+	// Well known HMAC-SHA256 of this key and message
+	if got := Sign("key", []byte("The quick brown fox jumps over the lazy dog")); got != "f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8" {
+		t.Errorf("unexpected HMAC %s", got)
+	}
+}
+
+func TestRetries(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		maxRetries int
+		requests   int32
+		fails      bool
+	}{
+		{name: "server errors are retried", status: http.StatusBadGateway, maxRetries: 2, requests: 3, fails: true},
+		{name: "rate limits are retried", status: http.StatusTooManyRequests, maxRetries: 1, requests: 2, fails: true},
+		{name: "client errors are not retried", status: http.StatusBadRequest, maxRetries: 3, requests: 1, fails: true},
+		{name: "success", status: http.StatusNoContent, maxRetries: 3, requests: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			n := testNotifier(t, config.WebhookCfg{Name: "test", URL: srv.URL, MaxRetries: tt.maxRetries})
+			err := n.Handle(context.Background(), event.RunStarted(event.Text("char", "Starting run"), "countess"))
+			if (err != nil) != tt.fails {
+				t.Errorf("expected failure %v, got %v", tt.fails, err)
+			}
+			if got := requests.Load(); got != tt.requests {
+				t.Errorf("expected %d requests, got %d", tt.requests, got)
+			}
+		})
+	}
+}
+
+func TestRetrySucceeds(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	n := testNotifier(t, config.WebhookCfg{Name: "test", URL: srv.URL})
+	if err := n.Handle(context.Background(), event.RunStarted(event.Text("char", "Starting run"), "countess")); err != nil {
+		t.Fatal(err)
+	}
+	if got := requests.Load(); got != 3 {
+		t.Errorf("expected 2 failed attempts and a successful one, got %d requests", got)
+	}
+}
+
+func TestTemplateRedactsPasswords(t *testing.T) {
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	n := testNotifier(t, config.WebhookCfg{
+		Name:     "test",
+		URL:      srv.URL,
+		Template: `{"name": {{json .Event.Name}}, "password": {{json .Event.Password}}, "event": {{json .Event}}}`,
+	})
+	if err := n.Handle(context.Background(), event.GameCreated(event.Text("char", "New game"), "game-1", "s3cr3t")); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(string(body), "s3cr3t") {
+		t.Errorf("password sent to the webhook: %s", body)
+	}
+	if !strings.Contains(string(body), `"name": "game-1"`) {
+		t.Errorf("expected the game name, got %s", body)
+	}
+}
+
+func TestEventFilter(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer srv.Close()
+
+	n := testNotifier(t, config.WebhookCfg{Name: "test", URL: srv.URL, Events: []string{"run_finished"}})
+	if err := n.Handle(context.Background(), event.RunStarted(event.Text("char", "Starting run"), "countess")); err != nil {
+		t.Fatal(err)
+	}
+	if got := requests.Load(); got != 0 {
+		t.Errorf("expected events out of the filter to be skipped, got %d requests", got)
+	}
+}
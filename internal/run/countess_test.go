@@ -0,0 +1,106 @@
+package run
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+	"github.com/hectorgimenez/d2go/pkg/data/npc"
+	"github.com/hectorgimenez/d2go/pkg/data/object"
+	"github.com/hectorgimenez/d2go/pkg/data/skill"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
+	"github.com/hectorgimenez/koolo/internal/character"
+	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/game"
+	"github.com/hectorgimenez/koolo/internal/game/sim"
+)
+
+// countessScenario lays out the levels of the run one after the other, every level links to the previous and the next
+// one through an entrance
+func countessScenario() sim.Scenario {
+	levels := []area.ID{
+		area.BlackMarsh,
+		area.ForgottenTower,
+		area.TowerCellarLevel1,
+		area.TowerCellarLevel2,
+		area.TowerCellarLevel3,
+		area.TowerCellarLevel4,
+		area.TowerCellarLevel5,
+	}
+
+	areas := map[area.ID]game.AreaData{area.RogueEncampment: sim.OpenArea(area.RogueEncampment, 1000, 1000, 40, 40)}
+	for i, id := range levels {
+		offset := 2000 + i*1000
+		ad := sim.OpenArea(id, offset, offset, 50, 50)
+		if i > 0 {
+			ad.AdjacentLevels = append(ad.AdjacentLevels, data.Level{Area: levels[i-1], Position: data.Position{X: offset + 5, Y: offset + 5}, IsEntrance: true})
+		}
+		if i < len(levels)-1 {
+			ad.AdjacentLevels = append(ad.AdjacentLevels, data.Level{Area: levels[i+1], Position: data.Position{X: offset + 40, Y: offset + 40}, IsEntrance: true})
+		}
+		areas[id] = ad
+	}
+
+	countess := data.Position{X: 8030, Y: 8020}
+	cellar5 := areas[area.TowerCellarLevel5]
+	cellar5.NPCs = data.NPCs{{ID: 740, Name: "The Countess", Positions: []data.Position{countess}}}
+	areas[area.TowerCellarLevel5] = cellar5
+
+	return sim.Scenario{
+		Seed:  1,
+		Areas: areas,
+		Player: data.PlayerUnit{
+			Area:               area.RogueEncampment,
+			Position:           data.Position{X: 1015, Y: 1015},
+			AvailableWaypoints: []area.ID{area.RogueEncampment, area.BlackMarsh},
+			Stats: stat.Stats{
+				{ID: stat.Life, Value: 500},
+				{ID: stat.MaxLife, Value: 500},
+				{ID: stat.Mana, Value: 200},
+				{ID: stat.MaxMana, Value: 200},
+			},
+		},
+		Objects: map[area.ID][]data.Object{
+			area.RogueEncampment: {{ID: 50, Name: object.WaypointPortal, Position: data.Position{X: 1022, Y: 1020}, Selectable: true}},
+		},
+		Monsters: map[area.ID]data.Monsters{
+			area.TowerCellarLevel5: {{
+				UnitID:   60,
+				Name:     npc.DarkStalker,
+				Type:     data.MonsterTypeSuperUnique,
+				Position: countess,
+				Stats:    map[stat.ID]int{stat.Life: 1000},
+			}},
+		},
+		KeyBindings:  sim.DefaultKeyBindings(skill.Concentration, skill.BlessedHammer),
+		CharacterCfg: config.CharacterCfg{},
+	}
+}
+
+func TestCountess(t *testing.T) {
+	s := countessScenario()
+	s.CharacterCfg.Character.Class = "hammerdin"
+
+	b := sim.NewBackend(s)
+	ctx := sim.NewContext(t.Name(), b, &s.CharacterCfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	t.Cleanup(ctx.Detach)
+	char, err := character.BuildCharacter(ctx.Context)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx.Char = char
+
+	if err = NewCountess().Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	d := b.GetData()
+	if d.PlayerUnit.Area != area.TowerCellarLevel5 {
+		t.Fatalf("expected to end in %s, got %s", area.TowerCellarLevel5.Area().Name, d.PlayerUnit.Area.Area().Name)
+	}
+	if countess, found := d.Monsters.FindOne(npc.DarkStalker, data.MonsterTypeSuperUnique); found && countess.Stats[stat.Life] > 0 {
+		t.Fatalf("expected the Countess to be dead, life: %d", countess.Stats[stat.Life])
+	}
+}
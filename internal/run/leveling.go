@@ -133,10 +133,10 @@ func (a Leveling) AdjustGameDifficulty() error {
 	}
 
 	if difficultyChanged {
-		a.ctx.Logger.Info("Difficulty changed to %s. Saving character configuration...", a.ctx.CharacterCfg.Game.Difficulty)
+		a.ctx.Logger.Info(fmt.Sprintf("Difficulty changed to %s. Saving character configuration...", a.ctx.CharacterCfg.Game.Difficulty))
 		// Use the new ConfigFolderName field here!
 		if err := config.SaveSupervisorConfig(a.ctx.CharacterCfg.ConfigFolderName, a.ctx.CharacterCfg); err != nil {
-			a.ctx.Logger.Error("Failed to save character configuration: %s", err.Error())
+			a.ctx.Logger.Error("Failed to save character configuration", "error", err)
 			return fmt.Errorf("failed to save character configuration: %w", err)
 		}
 		return errors.New("res too low for hell")
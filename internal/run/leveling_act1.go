@@ -19,7 +19,6 @@ import (
 	"github.com/hectorgimenez/koolo/internal/town"
 	"github.com/hectorgimenez/koolo/internal/ui"
 	"github.com/hectorgimenez/koolo/internal/utils"
-	"github.com/lxn/win"
 )
 
 // act1 is the main function for Act 1 leveling
@@ -209,9 +208,9 @@ func (a Leveling) goToAct2() error {
 	}
 
 	action.InteractNPC(npc.Warriv)
-	a.ctx.HID.KeySequence(win.VK_HOME, win.VK_DOWN, win.VK_RETURN)
+	a.ctx.HID.KeySequence(game.VKHome, game.VKDown, game.VKReturn)
 	utils.Sleep(1000)
-	a.HoldKey(win.VK_SPACE, 2000)
+	a.HoldKey(game.VKSpace, 2000)
 	utils.Sleep(1000)
 	return nil
 }
@@ -314,7 +313,7 @@ func gambleAct1Belt(ctx *context.Status) error {
 	}
 	defer step.CloseAllMenus()
 
-	ctx.HID.KeySequence(win.VK_HOME, win.VK_DOWN, win.VK_DOWN, win.VK_RETURN)
+	ctx.HID.KeySequence(game.VKHome, game.VKDown, game.VKDown, game.VKReturn)
 	utils.Sleep(1000)
 
 	// Check if the shop menu is open
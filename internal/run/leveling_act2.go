@@ -12,7 +12,6 @@ import (
 	"github.com/hectorgimenez/d2go/pkg/data/quest"
 	"github.com/hectorgimenez/d2go/pkg/data/skill"
 	"github.com/hectorgimenez/d2go/pkg/data/stat"
-	"github.com/hectorgimenez/d2go/pkg/memory"
 	"github.com/hectorgimenez/koolo/internal/action"
 	"github.com/hectorgimenez/koolo/internal/action/step"
 	"github.com/hectorgimenez/koolo/internal/config"
@@ -21,7 +20,6 @@ import (
 	"github.com/hectorgimenez/koolo/internal/town"
 	"github.com/hectorgimenez/koolo/internal/ui"
 	"github.com/hectorgimenez/koolo/internal/utils"
-	"github.com/lxn/win"
 )
 
 func (a Leveling) act2() error {
@@ -55,9 +53,9 @@ func (a Leveling) act2() error {
 			Y: 5060,
 		})
 		action.InteractNPC(npc.Meshif)
-		a.ctx.HID.KeySequence(win.VK_HOME, win.VK_DOWN, win.VK_RETURN)
+		a.ctx.HID.KeySequence(game.VKHome, game.VKDown, game.VKReturn)
 		utils.Sleep(1000)
-		a.HoldKey(win.VK_SPACE, 2000) // Hold the Escape key (VK_ESCAPE or 0x1B) for 2000 milliseconds (2 seconds)
+		a.HoldKey(game.VKSpace, 2000) // Hold the Escape key (VK_ESCAPE or 0x1B) for 2000 milliseconds (2 seconds)
 		utils.Sleep(1000)
 
 		return nil
@@ -84,9 +82,9 @@ func (a Leveling) act2() error {
 			Y: 5060,
 		})
 		action.InteractNPC(npc.Meshif)
-		a.ctx.HID.KeySequence(win.VK_HOME, win.VK_DOWN, win.VK_RETURN)
+		a.ctx.HID.KeySequence(game.VKHome, game.VKDown, game.VKReturn)
 		utils.Sleep(1000)
-		a.HoldKey(win.VK_SPACE, 2000) // Hold the Escape key (VK_ESCAPE or 0x1B) for 2000 milliseconds (2 seconds)
+		a.HoldKey(game.VKSpace, 2000) // Hold the Escape key (VK_ESCAPE or 0x1B) for 2000 milliseconds (2 seconds)
 		utils.Sleep(1000)
 		return nil
 	}
@@ -113,14 +111,14 @@ func (a Leveling) act2() error {
 		if err := action.InteractNPC(town.GetTownByArea(a.ctx.Data.PlayerUnit.Area).MercContractorNPC()); err != nil {
 			return err
 		}
-		a.ctx.HID.KeySequence(win.VK_HOME, win.VK_DOWN, win.VK_RETURN)
+		a.ctx.HID.KeySequence(game.VKHome, game.VKDown, game.VKReturn)
 		utils.Sleep(2000)
 
 		a.ctx.Logger.Info("Getting merc list")
 		mercList := a.ctx.GameReader.GetMercList()
 
 		// get the first with fronzen aura
-		var mercToHire *memory.MercOption
+		var mercToHire *game.MercOption
 		for i := range mercList {
 			if mercList[i].Skill.ID == skill.HolyFreeze {
 				mercToHire = &mercList[i]
@@ -134,11 +132,11 @@ func (a Leveling) act2() error {
 		}
 
 		a.ctx.Logger.Info(fmt.Sprintf("Hiring merc: %s with skill %s", mercToHire.Name, mercToHire.Skill.Name))
-		keySequence := []byte{win.VK_HOME}
+		keySequence := []byte{game.VKHome}
 		for i := 0; i < mercToHire.Index; i++ {
-			keySequence = append(keySequence, win.VK_DOWN)
+			keySequence = append(keySequence, game.VKDown)
 		}
-		keySequence = append(keySequence, win.VK_RETURN, win.VK_UP, win.VK_RETURN) // Select merc and confirm hire
+		keySequence = append(keySequence, game.VKReturn, game.VKUp, game.VKReturn) // Select merc and confirm hire
 		a.ctx.HID.KeySequence(keySequence...)
 
 		a.ctx.CharacterCfg.Character.ShouldHireAct2MercFrozenAura = false
@@ -171,9 +169,9 @@ func (a Leveling) act2() error {
 			Y: 5060,
 		})
 		action.InteractNPC(npc.Meshif)
-		a.ctx.HID.KeySequence(win.VK_HOME, win.VK_DOWN, win.VK_RETURN)
+		a.ctx.HID.KeySequence(game.VKHome, game.VKDown, game.VKReturn)
 		utils.Sleep(1000)
-		a.HoldKey(win.VK_SPACE, 2000) // Hold the Escape key (VK_ESCAPE or 0x1B) for 2000 milliseconds (2 seconds)
+		a.HoldKey(game.VKSpace, 2000) // Hold the Escape key (VK_ESCAPE or 0x1B) for 2000 milliseconds (2 seconds)
 		utils.Sleep(1000)
 		return nil
 
@@ -621,7 +619,7 @@ func buyAct2Belt(ctx *context.Status) error {
 	}
 	defer step.CloseAllMenus()
 
-	ctx.HID.KeySequence(win.VK_HOME, win.VK_DOWN, win.VK_RETURN) // Interact with Fara
+	ctx.HID.KeySequence(game.VKHome, game.VKDown, game.VKReturn) // Interact with Fara
 	utils.Sleep(1000)
 
 	// Switch to armor tab and refresh game data to see the new items
@@ -659,7 +657,7 @@ func (a Leveling) RockyWaste() error {
 	// Use action.MoveToArea to navigate to Rocky Waste, similar to the Izual quest.
 	err := action.MoveToArea(area.RockyWaste)
 	if err != nil {
-		a.ctx.Logger.Error("Failed to move to Rocky Waste area: %v", err)
+		a.ctx.Logger.Error("Failed to move to Rocky Waste area", "error", err)
 		return err // Return the error if navigation fails
 	}
 	a.ctx.Logger.Info("Successfully reached Rocky Waste.")
@@ -667,7 +665,7 @@ func (a Leveling) RockyWaste() error {
 	// Attempt to clear the current level (Rocky Waste).
 	err = action.ClearCurrentLevel(false, data.MonsterAnyFilter())
 	if err != nil {
-		a.ctx.Logger.Error("Failed to clear Rocky Waste area: %v", err)
+		a.ctx.Logger.Error("Failed to clear Rocky Waste area", "error", err)
 		return err // Return the error if clearing fails
 	}
 	a.ctx.Logger.Info("Successfully cleared Rocky Waste area.")
@@ -682,7 +680,7 @@ func (a Leveling) FarOasis() error {
 	// Attempt to clear the current level (Far Oasis).
 	err := action.ClearCurrentLevel(false, data.MonsterEliteFilter())
 	if err != nil {
-		a.ctx.Logger.Error("Failed to clear Far Oasis area: %v", err)
+		a.ctx.Logger.Error("Failed to clear Far Oasis area", "error", err)
 		return err // Return the error if clearing fails
 	}
 
@@ -16,7 +16,6 @@ import (
 	"github.com/hectorgimenez/koolo/internal/game"
 	"github.com/hectorgimenez/koolo/internal/ui"
 	"github.com/hectorgimenez/koolo/internal/utils"
-	"github.com/lxn/win"
 )
 
 func (a Leveling) act3() error {
@@ -48,7 +47,7 @@ func (a Leveling) act3() error {
 
 		a.ctx.Logger.Info("Low on gold. Initiating Lower Kurast Chests gold farm.")
 		if err := NewLowerKurastChest().Run(); err != nil {
-			a.ctx.Logger.Error("Error during Lower Kurast Chests gold farm: %v", err)
+			a.ctx.Logger.Error("Error during Lower Kurast Chests gold farm", "error", err)
 			return err
 		}
 		a.ctx.Logger.Info("Lower Kurast Chests gold farming completed. Quitting current run to re-evaluate in next game.")
@@ -100,7 +99,7 @@ func (a Leveling) act3() error {
 		})
 
 		utils.Sleep(500)
-		a.HoldKey(win.VK_SPACE, 3000)
+		a.HoldKey(game.VKSpace, 3000)
 		utils.Sleep(500)
 
 		return nil
@@ -116,7 +115,7 @@ func (a Leveling) act3() error {
 		a.ctx.CharacterCfg.Game.Mephisto.ExitToA4 = true
 		err := NewMephisto(nil).Run()
 		if err != nil {
-			a.ctx.Logger.Error("Mephisto run failed, ending Act 3 script.", err)
+			a.ctx.Logger.Error("Mephisto run failed, ending Act 3 script.", "error", err)
 			return err
 		}
 		// If Mephisto run completes (successfully or not, but without an explicit error from NewMephisto),
@@ -132,12 +131,12 @@ func (a Leveling) act3() error {
 				return a.ctx.Data.PlayerUnit.Area == area.ThePandemoniumFortress
 			})
 			if err != nil {
-				a.ctx.Logger.Error("Failed to interact with Hell Gate, ending Act 3 script.", err)
+				a.ctx.Logger.Error("Failed to interact with Hell Gate, ending Act 3 script.", "error", err)
 				return err //
 			}
 			a.ctx.Logger.Info("Successfully interacted with Hell Gate. Attempting to skip cinematic.")
 			utils.Sleep(500)
-			a.HoldKey(win.VK_SPACE, 3000)
+			a.HoldKey(game.VKSpace, 3000)
 			utils.Sleep(500)
 			// If we successfully interacted with the Hell Gate, we assume the attempt to go to A4 is complete.
 			a.ctx.Logger.Info("Successfully attempted to enter Act 4. Ending Act 3 script.")
@@ -168,18 +167,18 @@ func (a Leveling) act3() error {
 			err = action.InteractObject(hellgate, func() bool {
 				utils.Sleep(500)
 				utils.Sleep(1000)
-				a.HoldKey(win.VK_SPACE, 3000) // Hold the Escape key (VK_ESCAPE or 0x1B) for 2000 milliseconds (2 seconds)
+				a.HoldKey(game.VKSpace, 3000) // Hold the Escape key (VK_ESCAPE or 0x1B) for 2000 milliseconds (2 seconds)
 				utils.Sleep(1000)
 				return a.ctx.Data.PlayerUnit.Area == area.ThePandemoniumFortress
 			})
 			if err != nil {
 				utils.Sleep(1000)
-				a.HoldKey(win.VK_SPACE, 3000) // Hold the Escape key (VK_ESCAPE or 0x1B) for 2000 milliseconds (2 seconds)
+				a.HoldKey(game.VKSpace, 3000) // Hold the Escape key (VK_ESCAPE or 0x1B) for 2000 milliseconds (2 seconds)
 				utils.Sleep(1000)
 				return err // Exit on error interacting with portal
 			}
 			utils.Sleep(1000)
-			a.HoldKey(win.VK_SPACE, 3000) // Hold the Escape key (VK_ESCAPE or 0x1B) for 2000 milliseconds (2 seconds)
+			a.HoldKey(game.VKSpace, 3000) // Hold the Escape key (VK_ESCAPE or 0x1B) for 2000 milliseconds (2 seconds)
 			utils.Sleep(1000)
 			return nil // Exit if successfully interacted with portal
 		}
@@ -235,18 +234,18 @@ func (a Leveling) act3() error {
 			err := action.InteractObject(hellgate, func() bool {
 				utils.Sleep(500)
 				utils.Sleep(1000)
-				a.HoldKey(win.VK_SPACE, 2000) // Hold the Escape key (VK_ESCAPE or 0x1B) for 2000 milliseconds (2 seconds)
+				a.HoldKey(game.VKSpace, 2000) // Hold the Escape key (VK_ESCAPE or 0x1B) for 2000 milliseconds (2 seconds)
 				utils.Sleep(1000)
 				return a.ctx.Data.PlayerUnit.Area == area.ThePandemoniumFortress
 			})
 			if err != nil {
 				utils.Sleep(1000)
-				a.HoldKey(win.VK_SPACE, 2000) // Hold the Escape key (VK_ESCAPE or 0x1B) for 2000 milliseconds (2 seconds)
+				a.HoldKey(game.VKSpace, 2000) // Hold the Escape key (VK_ESCAPE or 0x1B) for 2000 milliseconds (2 seconds)
 				utils.Sleep(1000)
 				return err // Exit on error interacting with portal
 			}
 			utils.Sleep(1000)
-			a.HoldKey(win.VK_SPACE, 2000) // Hold the Escape key (VK_ESCAPE or 0x1B) for 2000 milliseconds (2 seconds)
+			a.HoldKey(game.VKSpace, 2000) // Hold the Escape key (VK_ESCAPE or 0x1B) for 2000 milliseconds (2 seconds)
 			utils.Sleep(1000)
 			return nil // Exit if successfully interacted with portal
 		}
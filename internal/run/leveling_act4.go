@@ -16,8 +16,8 @@ import (
 	"github.com/hectorgimenez/koolo/internal/action"
 	"github.com/hectorgimenez/koolo/internal/config"
 	"github.com/hectorgimenez/koolo/internal/context"
+	"github.com/hectorgimenez/koolo/internal/game"
 	"github.com/hectorgimenez/koolo/internal/utils"
-	"github.com/lxn/win"
 )
 
 func ToKeyBinding(keyCode byte) data.KeyBinding {
@@ -65,7 +65,7 @@ func (a Leveling) act4() error {
 
 		harrogathPortal, found := a.ctx.Data.Objects.FindOne(object.LastLastPortal)
 		if !found { // portal was already opened before so we must talk to Tyrael to get to A5
-			a.ctx.HID.KeySequence(win.VK_HOME, win.VK_DOWN, win.VK_RETURN)
+			a.ctx.HID.KeySequence(game.VKHome, game.VKDown, game.VKReturn)
 			// After attempting to open it with key sequence, you should re-check if it's found
 			// If still not found, then it's an error.
 
@@ -79,9 +79,9 @@ func (a Leveling) act4() error {
 
 		// Skip Cinematic
 		utils.Sleep(2000)
-		a.HoldKey(win.VK_SPACE, 2000)
+		a.HoldKey(game.VKSpace, 2000)
 		utils.Sleep(2000)
-		a.HoldKey(win.VK_SPACE, 2000)
+		a.HoldKey(game.VKSpace, 2000)
 
 		return nil
 	}
@@ -130,7 +130,7 @@ func (a Leveling) act4() error {
 
 	if !a.ctx.Data.Quests[quest.Act4TheFallenAngel].Completed() {
 		err := NewQuests().killIzualQuest() // No immediate 'return' here
-		a.ctx.Logger.Debug("After Izual attempt, Izual quest completed status: %v", a.ctx.Data.Quests[quest.Act4TheFallenAngel].Completed())
+		a.ctx.Logger.Debug("After Izual attempt", "izualCompleted", a.ctx.Data.Quests[quest.Act4TheFallenAngel].Completed())
 		if err != nil {
 			return err
 		}
@@ -150,7 +150,7 @@ func (a Leveling) act4() error {
 
 		harrogathPortal, found := a.ctx.Data.Objects.FindOne(object.LastLastPortal)
 		if !found { // portal was already opened before so we must talk to Tyrael to get to A5
-			a.ctx.HID.KeySequence(win.VK_HOME, win.VK_DOWN, win.VK_RETURN)
+			a.ctx.HID.KeySequence(game.VKHome, game.VKDown, game.VKReturn)
 			// After attempting to open it with key sequence, you should re-check if it's found
 			// If still not found, then it's an error.
 
@@ -164,18 +164,18 @@ func (a Leveling) act4() error {
 
 		// Skip Cinematic
 		utils.Sleep(2000)
-		a.HoldKey(win.VK_SPACE, 2000)
+		a.HoldKey(game.VKSpace, 2000)
 		utils.Sleep(2000)
-		a.HoldKey(win.VK_SPACE, 2000)
+		a.HoldKey(game.VKSpace, 2000)
 
 		return nil
 	}
 
-	a.ctx.Logger.Debug("Current Izual quest completed status: %v", a.ctx.Data.Quests[quest.Act4TheFallenAngel].Completed())
+	a.ctx.Logger.Debug("Current Izual quest status", "izualCompleted", a.ctx.Data.Quests[quest.Act4TheFallenAngel].Completed())
 
 	if !a.ctx.Data.Quests[quest.Act4TheFallenAngel].Completed() {
 		err := NewQuests().killIzualQuest() // No immediate 'return' here
-		a.ctx.Logger.Debug("After Izual attempt, Izual quest completed status: %v", a.ctx.Data.Quests[quest.Act4TheFallenAngel].Completed())
+		a.ctx.Logger.Debug("After Izual attempt", "izualCompleted", a.ctx.Data.Quests[quest.Act4TheFallenAngel].Completed())
 		if err != nil {
 			return err
 		}
@@ -205,9 +205,9 @@ func (a Leveling) act4() error {
 
 		// Skip Cinematic
 		utils.Sleep(2000)
-		a.HoldKey(win.VK_SPACE, 2000)
+		a.HoldKey(game.VKSpace, 2000)
 		utils.Sleep(2000)
-		a.HoldKey(win.VK_SPACE, 2000)
+		a.HoldKey(game.VKSpace, 2000)
 
 		return nil
 	}
@@ -219,14 +219,14 @@ func (a Leveling) OuterSteppes() error {
 
 	err := action.MoveToArea(area.OuterSteppes)
 	if err != nil {
-		a.ctx.Logger.Error("Failed to move to Outer Steppes area: %v", err)
+		a.ctx.Logger.Error("Failed to move to Outer Steppes area", "error", err)
 		return err
 	}
 	a.ctx.Logger.Debug("Successfully reached Outer Steppes.")
 
 	err = action.ClearCurrentLevel(false, data.MonsterAnyFilter())
 	if err != nil {
-		a.ctx.Logger.Error("Failed to clear Outer Steppes area: %v", err)
+		a.ctx.Logger.Error("Failed to clear Outer Steppes area", "error", err)
 		return err
 	}
 	a.ctx.Logger.Debug("Successfully cleared Outer Steppes area.")
@@ -19,7 +19,6 @@ import (
 	"github.com/hectorgimenez/d2go/pkg/data/stat"
 	"github.com/hectorgimenez/koolo/internal/action"
 	"github.com/hectorgimenez/koolo/internal/config" // Make sure this import is present
-	"github.com/lxn/win"
 )
 
 func (a Leveling) act5() error {
@@ -54,7 +53,7 @@ func (a Leveling) act5() error {
 
 			a.ctx.Logger.Info("Low on gold. Initiating gold farm.")
 			if err := NewEldritch().Run(); err != nil {
-				a.ctx.Logger.Error("Error during gold farm: %v", err)
+				a.ctx.Logger.Error("Error during gold farm", "error", err)
 				return err // Propagate error if farming fails
 			}
 
@@ -192,7 +191,7 @@ func (a Leveling) act5() error {
 
 			action.InteractNPC(npc.Malah)
 			utils.Sleep(1000)
-			a.ctx.HID.KeySequence(win.VK_HOME, win.VK_DOWN, win.VK_DOWN, win.VK_RETURN)
+			a.ctx.HID.KeySequence(game.VKHome, game.VKDown, game.VKDown, game.VKReturn)
 			// Adding a longer delay to ensure the game state has time to update
 			utils.Sleep(2500)
 
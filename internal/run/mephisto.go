@@ -11,8 +11,8 @@ import (
 	"github.com/hectorgimenez/koolo/internal/action"
 	"github.com/hectorgimenez/koolo/internal/config"
 	"github.com/hectorgimenez/koolo/internal/context"
+	"github.com/hectorgimenez/koolo/internal/game"
 	"github.com/hectorgimenez/koolo/internal/utils"
-	"github.com/lxn/win"
 )
 
 type Mephisto struct {
@@ -142,7 +142,7 @@ func (m Mephisto) Run() error {
 
 		if isLevelingChar {
 			utils.Sleep(1000)
-			m.HoldKey(win.VK_SPACE, 2000)
+			m.HoldKey(game.VKSpace, 2000)
 
 			utils.Sleep(1000)
 
@@ -18,7 +18,6 @@ import (
 	"github.com/hectorgimenez/koolo/internal/game"
 	"github.com/hectorgimenez/koolo/internal/ui"
 	"github.com/hectorgimenez/koolo/internal/utils"
-	"github.com/lxn/win"
 )
 
 type Quests struct {
@@ -96,7 +95,7 @@ func (a Quests) clearDenQuest() error {
 
 	a.ctx.CharacterCfg.Character.ClearPathDist = 20
 	if err := config.SaveSupervisorConfig(a.ctx.CharacterCfg.ConfigFolderName, a.ctx.CharacterCfg); err != nil {
-		a.ctx.Logger.Error("Failed to save character configuration: %s", err.Error())
+		a.ctx.Logger.Error("Failed to save character configuration", "error", err)
 	}
 
 	if err := action.ClearCurrentLevel(false, data.MonsterAnyFilter()); err != nil {
@@ -134,7 +133,7 @@ func (a Quests) rescueCainQuest() error {
 
 	a.ctx.CharacterCfg.Character.ClearPathDist = 20
 	if err := config.SaveSupervisorConfig(a.ctx.CharacterCfg.ConfigFolderName, a.ctx.CharacterCfg); err != nil {
-		a.ctx.Logger.Error("Failed to save character configuration: %s", err.Error())
+		a.ctx.Logger.Error("Failed to save character configuration", "error", err)
 	}
 
 	err = action.WayPoint(area.DarkWood)
@@ -144,7 +143,7 @@ func (a Quests) rescueCainQuest() error {
 
 	a.ctx.CharacterCfg.Character.ClearPathDist = 30
 	if err := config.SaveSupervisorConfig(a.ctx.CharacterCfg.ConfigFolderName, a.ctx.CharacterCfg); err != nil {
-		a.ctx.Logger.Error("Failed to save character configuration: %s", err.Error())
+		a.ctx.Logger.Error("Failed to save character configuration", "error", err)
 	}
 
 	// Find the Inifuss Tree position.
@@ -865,7 +864,7 @@ func (a Quests) killAncientsQuest() error {
 	utils.Sleep(1000)
 	a.ctx.HID.Click(game.LeftButton, 720, 260)
 	utils.Sleep(1000)
-	a.ctx.HID.PressKey(win.VK_RETURN)
+	a.ctx.HID.PressKey(game.VKReturn)
 	utils.Sleep(2000)
 
 	// Modify the configuration for the Ancients fight
@@ -0,0 +1,181 @@
+package rotation
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Strategies ordering the runs of a game
+const (
+	Static     = "static"     // Runs in the configured order
+	Random     = "random"     // Shuffled every game
+	Weighted   = "weighted"   // Shuffled every game, runs with a higher weight tend to go first
+	RoundRobin = "roundRobin" // The starting run moves one position every game, RunsPerGame limits the runs per game
+)
+
+// Policy decides which runs are executed in a game and in which order. Runs are referenced by their config name.
+type Policy struct {
+	Strategy    string
+	Weights     map[string]int // Weighted strategy, runs without weight get 1
+	RunsPerGame int            // RoundRobin strategy, 0 runs all of them
+
+	EveryNthGame map[string]int  // Runs only done every N games, e.g. cows every 5 games
+	TimeOfDay    []TimeOfDayRuns // Replace the run list during some hours of the day
+
+	// Adaptive ordering moves the runs failing more often than MaxFailureRate to the end of the game, worst last
+	Adaptive       bool
+	MaxFailureRate float64
+	MinRuns        int // Runs needed in the history before a run failure rate is trusted
+}
+
+// TimeOfDayRuns replaces the configured runs between From and To, To before From means the range crosses midnight
+type TimeOfDayRuns struct {
+	From time.Duration // Offset from midnight
+	To   time.Duration
+	Runs []string
+}
+
+// Game is what the rotation knows about the game being created
+type Game struct {
+	Number int       // Games created since the supervisor started, starting at 1
+	Time   time.Time // Game creation time, picks the time of day run list
+}
+
+// FailureRate is the recent history of a run
+type FailureRate struct {
+	Runs int
+	Rate float64 // Deaths and errors over runs
+}
+
+// Rotator applies a Policy, it's not safe for concurrent use
+type Rotator struct {
+	policy Policy
+	rnd    *rand.Rand
+}
+
+func NewRotator(policy Policy, rnd *rand.Rand) *Rotator {
+	return &Rotator{policy: policy, rnd: rnd}
+}
+
+// Order returns the runs for the game. failures is only used by adaptive policies and can be nil.
+func (r *Rotator) Order(runs []string, game Game, failures map[string]FailureRate) []string {
+	p := r.policy
+
+	runs = slices.Clone(r.runsAt(runs, game.Time))
+	runs = slices.DeleteFunc(runs, func(run string) bool {
+		n := lookup(p.EveryNthGame, run)
+		return n > 1 && game.Number%n != 0
+	})
+	if len(runs) == 0 {
+		return runs
+	}
+
+	switch p.Strategy {
+	case Random:
+		r.rnd.Shuffle(len(runs), func(i, j int) { runs[i], runs[j] = runs[j], runs[i] })
+	case Weighted:
+		runs = r.weighted(runs)
+	case RoundRobin:
+		runs = roundRobin(runs, game.Number, p.RunsPerGame)
+	}
+
+	if p.Adaptive {
+		runs = adaptive(runs, failures, p.MaxFailureRate, p.MinRuns)
+	}
+
+	return runs
+}
+
+func (r *Rotator) runsAt(runs []string, t time.Time) []string {
+	clock := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	for _, tod := range r.policy.TimeOfDay {
+		inRange := clock >= tod.From && clock < tod.To
+		if tod.To <= tod.From {
+			inRange = clock >= tod.From || clock < tod.To
+		}
+		if inRange {
+			return tod.Runs
+		}
+	}
+
+	return runs
+}
+
+// weighted is a weighted shuffle (Efraimidis-Spirakis), every run gets the key u^(1/weight) and runs are sorted by key
+func (r *Rotator) weighted(runs []string) []string {
+	keys := make(map[string]float64, len(runs))
+	for _, run := range runs {
+		w := lookup(r.policy.Weights, run)
+		if w <= 0 {
+			w = 1
+		}
+		keys[run] = math.Pow(r.rnd.Float64(), 1/float64(w))
+	}
+	sort.SliceStable(runs, func(i, j int) bool { return keys[runs[i]] > keys[runs[j]] })
+
+	return runs
+}
+
+func roundRobin(runs []string, gameNumber, perGame int) []string {
+	if perGame <= 0 || perGame > len(runs) {
+		perGame = len(runs)
+	}
+	// Running all of them the first run moves one position per game, otherwise every game takes the next perGame runs
+	step := perGame
+	if perGame == len(runs) {
+		step = 1
+	}
+	start := ((gameNumber-1)*step%len(runs) + len(runs)) % len(runs)
+
+	out := make([]string, 0, perGame)
+	for i := 0; i < perGame; i++ {
+		out = append(out, runs[(start+i)%len(runs)])
+	}
+
+	return out
+}
+
+// adaptive keeps the order of the healthy runs and moves the failing ones to the end, the worst one last
+func adaptive(runs []string, failures map[string]FailureRate, maxRate float64, minRuns int) []string {
+	penalty := func(run string) float64 {
+		f, found := lookupFailure(failures, run)
+		if !found || f.Runs < minRuns || f.Rate <= maxRate {
+			return 0
+		}
+		return f.Rate
+	}
+	sort.SliceStable(runs, func(i, j int) bool { return penalty(runs[i]) < penalty(runs[j]) })
+
+	return runs
+}
+
+// lookup is case-insensitive, run names in the config are hand written
+func lookup(m map[string]int, run string) int {
+	if v, found := m[run]; found {
+		return v
+	}
+	for k, v := range m {
+		if strings.EqualFold(k, run) {
+			return v
+		}
+	}
+
+	return 0
+}
+
+func lookupFailure(m map[string]FailureRate, run string) (FailureRate, bool) {
+	if v, found := m[run]; found {
+		return v, true
+	}
+	for k, v := range m {
+		if strings.EqualFold(k, run) {
+			return v, true
+		}
+	}
+
+	return FailureRate{}, false
+}
@@ -0,0 +1,93 @@
+package rotation
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+)
+
+var runs = []string{"countess", "andariel", "pit", "cows"}
+
+func TestRoundRobin(t *testing.T) {
+	tests := []struct {
+		perGame int
+		game    int
+		want    []string
+	}{
+		{0, 1, []string{"countess", "andariel", "pit", "cows"}},
+		{0, 2, []string{"andariel", "pit", "cows", "countess"}},
+		{0, 5, []string{"countess", "andariel", "pit", "cows"}},
+		{3, 1, []string{"countess", "andariel", "pit"}},
+		{3, 2, []string{"cows", "countess", "andariel"}},
+		{3, 3, []string{"pit", "cows", "countess"}},
+	}
+
+	for _, tt := range tests {
+		r := NewRotator(Policy{Strategy: RoundRobin, RunsPerGame: tt.perGame}, nil)
+		if got := r.Order(runs, Game{Number: tt.game}, nil); !slices.Equal(got, tt.want) {
+			t.Errorf("perGame %d game %d: expected %v, got %v", tt.perGame, tt.game, tt.want, got)
+		}
+	}
+}
+
+func TestEveryNthGame(t *testing.T) {
+	r := NewRotator(Policy{EveryNthGame: map[string]int{"Cows": 5}}, nil)
+
+	for game := 1; game <= 10; game++ {
+		got := r.Order(runs, Game{Number: game}, nil)
+		if hasCows := slices.Contains(got, "cows"); hasCows != (game%5 == 0) {
+			t.Fatalf("game %d: unexpected runs %v", game, got)
+		}
+	}
+}
+
+func TestTimeOfDay(t *testing.T) {
+	r := NewRotator(Policy{TimeOfDay: []TimeOfDayRuns{
+		{From: 22 * time.Hour, To: 6 * time.Hour, Runs: []string{"pit"}},
+	}}, nil)
+
+	night := time.Date(2025, 3, 14, 2, 30, 0, 0, time.UTC)
+	if got := r.Order(runs, Game{Number: 1, Time: night}, nil); !slices.Equal(got, []string{"pit"}) {
+		t.Fatalf("expected the night list, got %v", got)
+	}
+	day := time.Date(2025, 3, 14, 14, 0, 0, 0, time.UTC)
+	if got := r.Order(runs, Game{Number: 1, Time: day}, nil); !slices.Equal(got, runs) {
+		t.Fatalf("expected the default list, got %v", got)
+	}
+}
+
+func TestWeightedFavoursHeavierRuns(t *testing.T) {
+	r := NewRotator(Policy{Strategy: Weighted, Weights: map[string]int{"pit": 10}}, rand.New(rand.NewSource(1)))
+
+	first := 0
+	for game := 1; game <= 1000; game++ {
+		got := r.Order(runs, Game{Number: game}, nil)
+		if len(got) != len(runs) {
+			t.Fatalf("runs lost in the shuffle: %v", got)
+		}
+		if got[0] == "pit" {
+			first++
+		}
+	}
+	// Weight 10 against three runs of weight 1 leads 10/13 of the games
+	if first < 700 || first > 840 {
+		t.Fatalf("expected pit first in ~770 games, got %d", first)
+	}
+}
+
+func TestAdaptiveMovesFailingRunsLast(t *testing.T) {
+	r := NewRotator(Policy{Adaptive: true, MaxFailureRate: 0.2, MinRuns: 5}, nil)
+	failures := map[string]FailureRate{
+		"countess": {Runs: 20, Rate: 0.5},
+		"andariel": {Runs: 20, Rate: 0.3},
+		"pit":      {Runs: 2, Rate: 1}, // Not enough runs to tell
+		"cows":     {Runs: 20, Rate: 0.1},
+	}
+
+	got := r.Order(runs, Game{Number: 1}, failures)
+	want := []string{"pit", "cows", "andariel", "countess"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,49 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	// 2025-03-14 is a friday
+	from := time.Date(2025, 3, 14, 18, 50, 0, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2025, 3, 14, 18, 51, 0, 0, time.UTC)},
+		{"0 19 * * *", time.Date(2025, 3, 14, 19, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2025, 3, 14, 19, 0, 0, 0, time.UTC)},
+		{"5/20 * * * *", time.Date(2025, 3, 14, 19, 5, 0, 0, time.UTC)},
+		{"30 8 * * mon-fri", time.Date(2025, 3, 17, 8, 30, 0, 0, time.UTC)},
+		{"0 10 * * 7", time.Date(2025, 3, 16, 10, 0, 0, 0, time.UTC)},
+		{"0 0 1 jan *", time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 12 29 feb *", time.Date(2028, 2, 29, 12, 0, 0, 0, time.UTC)},
+		// Both day fields restricted, either one matches
+		{"0 9 20 * sun", time.Date(2025, 3, 16, 9, 0, 0, 0, time.UTC)},
+		{"0 20-22/2 * * *", time.Date(2025, 3, 14, 20, 0, 0, 0, time.UTC)},
+		{"0 0 30 feb *", time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			c, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := c.Next(from); !got.Equal(tt.want) {
+				t.Fatalf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseCronErrors(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8", "*/0 * * * *", "5-1 * * * *", "* * * foo *"} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("expected an error parsing %q", expr)
+		}
+	}
+}
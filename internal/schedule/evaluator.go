@@ -0,0 +1,239 @@
+package schedule
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+type Action int
+
+const (
+	None Action = iota
+	Start
+	Stop
+)
+
+func (a Action) String() string {
+	switch a {
+	case Start:
+		return "start"
+	case Stop:
+		return "stop"
+	}
+
+	return "none"
+}
+
+type Decision struct {
+	Action Action
+	Reason string
+}
+
+// historyLength is how long play sessions are kept, enough to compute the weekly budget
+const historyLength = 8 * 24 * time.Hour
+
+// Evaluator applies a Policy to a single supervisor. It keeps track of the play sessions from the running state it's
+// given on every Decide call, the current time is always passed in so it can be tested without waiting.
+type Evaluator struct {
+	policy     Policy
+	rnd        *rand.Rand
+	sessions   []session
+	restUntil  time.Time
+	restReason string
+	jitter     map[time.Time]windowJitter
+}
+
+type session struct {
+	start, end time.Time // end is zero while playing
+}
+
+type windowJitter struct {
+	start, stop time.Duration
+}
+
+func NewEvaluator(policy Policy, rnd *rand.Rand) *Evaluator {
+	return &Evaluator{
+		policy: policy,
+		rnd:    rnd,
+		jitter: make(map[time.Time]windowJitter),
+	}
+}
+
+// SetPolicy replaces the policy keeping the play history, used when the configuration is reloaded
+func (e *Evaluator) SetPolicy(policy Policy) {
+	e.policy = policy
+}
+
+// Decide returns what should be done with the supervisor at now. running is the current supervisor state and games
+// the number of games played since it was started.
+func (e *Evaluator) Decide(now time.Time, running bool, games int) Decision {
+	e.track(now, running)
+
+	if running {
+		if reason := e.stopReason(now, games); reason != "" {
+			e.track(now, false)
+			return Decision{Action: Stop, Reason: reason}
+		}
+		return Decision{Action: None, Reason: "playing"}
+	}
+
+	if now.Before(e.restUntil) {
+		return Decision{Action: None, Reason: fmt.Sprintf("%s until %s", e.restReason, e.restUntil.Format("15:04"))}
+	}
+	if reason := e.budgetReached(now); reason != "" {
+		return Decision{Action: None, Reason: reason}
+	}
+	if !e.inWindow(now) {
+		return Decision{Action: None, Reason: "outside of the play windows"}
+	}
+
+	// Play time counts from the decision, if the start fails the session is closed on the next call
+	e.track(now, true)
+
+	return Decision{Action: Start, Reason: "inside a play window"}
+}
+
+// PlayTime returns the time played between from and to
+func (e *Evaluator) PlayTime(from, to time.Time) time.Duration {
+	var total time.Duration
+	for _, s := range e.sessions {
+		end := s.end
+		if end.IsZero() {
+			end = to
+		}
+		start := maxTime(s.start, from)
+		end = minTime(end, to)
+		if end.After(start) {
+			total += end.Sub(start)
+		}
+	}
+
+	return total
+}
+
+func (e *Evaluator) stopReason(now time.Time, games int) string {
+	p := e.policy
+
+	if now.Before(e.restUntil) {
+		return e.restReason
+	}
+	if !e.inWindow(now) {
+		return "outside of the play windows"
+	}
+	if reason := e.budgetReached(now); reason != "" {
+		return reason
+	}
+	if p.BreakAfter > 0 && p.BreakDuration > 0 {
+		if current := e.sessions[len(e.sessions)-1]; now.Sub(current.start) >= p.BreakAfter {
+			e.rest(now, p.BreakDuration, "taking a break")
+			return e.restReason
+		}
+	}
+	if p.RestAfterGames > 0 && games >= p.RestAfterGames {
+		e.rest(now, p.RestDuration, fmt.Sprintf("resting after %d games", games))
+		return e.restReason
+	}
+
+	return ""
+}
+
+func (e *Evaluator) budgetReached(now time.Time) string {
+	if e.policy.MaxDaily > 0 && e.PlayTime(startOfDay(now), now) >= e.policy.MaxDaily {
+		return "daily play time budget reached"
+	}
+	if e.policy.MaxWeekly > 0 && e.PlayTime(startOfWeek(now), now) >= e.policy.MaxWeekly {
+		return "weekly play time budget reached"
+	}
+
+	return ""
+}
+
+func (e *Evaluator) inWindow(now time.Time) bool {
+	if len(e.policy.Windows) == 0 {
+		return true
+	}
+
+	for _, w := range e.policy.Windows {
+		start, end, found := w.Occurrence(now)
+		if !found {
+			continue
+		}
+		j := e.windowJitter(start)
+		if !now.Before(start.Add(j.start)) && now.Before(end.Add(-j.stop)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// windowJitter returns the random offsets of the window occurrence starting at start, they are picked once so the
+// decision is stable across calls
+func (e *Evaluator) windowJitter(start time.Time) windowJitter {
+	if j, found := e.jitter[start]; found {
+		return j
+	}
+
+	for s := range e.jitter {
+		if s.Before(start.Add(-historyLength)) {
+			delete(e.jitter, s)
+		}
+	}
+	j := windowJitter{start: e.random(e.policy.StartJitter), stop: e.random(e.policy.StopJitter)}
+	e.jitter[start] = j
+
+	return j
+}
+
+func (e *Evaluator) rest(now time.Time, d time.Duration, reason string) {
+	e.restUntil = now.Add(d + e.random(e.policy.StartJitter))
+	e.restReason = reason
+}
+
+func (e *Evaluator) random(upTo time.Duration) time.Duration {
+	if upTo <= 0 || e.rnd == nil {
+		return 0
+	}
+
+	return time.Duration(e.rnd.Int63n(int64(upTo)))
+}
+
+func (e *Evaluator) track(now time.Time, running bool) {
+	playing := len(e.sessions) > 0 && e.sessions[len(e.sessions)-1].end.IsZero()
+	switch {
+	case running && !playing:
+		e.sessions = append(e.sessions, session{start: now})
+	case !running && playing:
+		e.sessions[len(e.sessions)-1].end = now
+	}
+
+	for len(e.sessions) > 0 && !e.sessions[0].end.IsZero() && e.sessions[0].end.Before(now.Add(-historyLength)) {
+		e.sessions = e.sessions[1:]
+	}
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// startOfWeek returns the monday starting the week of t
+func startOfWeek(t time.Time) time.Time {
+	daysSinceMonday := (int(t.Weekday()) + 6) % 7
+
+	return startOfDay(t).AddDate(0, 0, -daysSinceMonday)
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
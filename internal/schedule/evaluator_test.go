@@ -0,0 +1,141 @@
+package schedule
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// monday is 2025-03-10 00:00
+var monday = time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+
+func at(day int, clock string) time.Time {
+	d, _ := time.ParseDuration(clock)
+	return monday.AddDate(0, 0, day).Add(d)
+}
+
+// simulate calls Decide every minute between from and to, starting and stopping as told, and returns the decisions
+// that changed the running state
+func simulate(e *Evaluator, from, to time.Time, gamesPerHour int) []time.Time {
+	var changes []time.Time
+	running := false
+	var startedAt time.Time
+	for now := from; now.Before(to); now = now.Add(time.Minute) {
+		games := 0
+		if running {
+			games = int(now.Sub(startedAt).Hours() * float64(gamesPerHour))
+		}
+		switch e.Decide(now, running, games).Action {
+		case Start:
+			running, startedAt = true, now
+			changes = append(changes, now)
+		case Stop:
+			running = false
+			changes = append(changes, now)
+		}
+	}
+
+	return changes
+}
+
+func TestWeeklyRangeWindow(t *testing.T) {
+	e := NewEvaluator(Policy{Windows: []Window{
+		WeeklyRange{Weekday: time.Monday, Start: 18 * time.Hour, End: 20 * time.Hour},
+		// Crosses midnight
+		WeeklyRange{Weekday: time.Tuesday, Start: 23 * time.Hour, End: time.Hour},
+	}}, nil)
+
+	got := simulate(e, monday, monday.AddDate(0, 0, 3), 0)
+	want := []time.Time{at(0, "18h"), at(0, "20h"), at(1, "23h"), at(2, "1h")}
+	assertTimes(t, want, got)
+}
+
+func TestCronWindow(t *testing.T) {
+	c, err := ParseCron("30 9 * * mon,wed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := NewEvaluator(Policy{Windows: []Window{CronWindow{Cron: c, Duration: 90 * time.Minute}}}, nil)
+
+	got := simulate(e, monday, monday.AddDate(0, 0, 3), 0)
+	want := []time.Time{at(0, "9h30m"), at(0, "11h"), at(2, "9h30m"), at(2, "11h")}
+	assertTimes(t, want, got)
+}
+
+func TestJitterStaysInsideTheWindow(t *testing.T) {
+	window := WeeklyRange{Weekday: time.Monday, Start: 18 * time.Hour, End: 20 * time.Hour}
+	for seed := int64(0); seed < 20; seed++ {
+		e := NewEvaluator(Policy{
+			Windows:     []Window{window},
+			StartJitter: 15 * time.Minute,
+			StopJitter:  10 * time.Minute,
+		}, rand.New(rand.NewSource(seed)))
+
+		got := simulate(e, monday, monday.AddDate(0, 0, 1), 0)
+		if len(got) != 2 {
+			t.Fatalf("seed %d: expected a start and a stop, got %v", seed, got)
+		}
+		if got[0].Before(at(0, "18h")) || got[0].After(at(0, "18h15m")) {
+			t.Fatalf("seed %d: start %s out of the jitter range", seed, got[0])
+		}
+		if got[1].Before(at(0, "19h50m")) || got[1].After(at(0, "20h")) {
+			t.Fatalf("seed %d: stop %s out of the jitter range", seed, got[1])
+		}
+	}
+}
+
+func TestBreaks(t *testing.T) {
+	e := NewEvaluator(Policy{
+		Windows:       []Window{WeeklyRange{Weekday: time.Monday, Start: 10 * time.Hour, End: 14 * time.Hour}},
+		BreakAfter:    90 * time.Minute,
+		BreakDuration: 30 * time.Minute,
+	}, nil)
+
+	got := simulate(e, monday, monday.AddDate(0, 0, 1), 0)
+	// The second break ends with the window, so there is no third session
+	want := []time.Time{at(0, "10h"), at(0, "11h30m"), at(0, "12h"), at(0, "13h30m")}
+	assertTimes(t, want, got)
+}
+
+func TestRestAfterGames(t *testing.T) {
+	e := NewEvaluator(Policy{
+		RestAfterGames: 10,
+		RestDuration:   time.Hour,
+	}, nil)
+
+	// 20 games per hour, rest after 30 minutes of play
+	got := simulate(e, monday, monday.Add(3*time.Hour), 20)
+	want := []time.Time{at(0, "0h"), at(0, "30m"), at(0, "1h30m"), at(0, "2h")}
+	assertTimes(t, want, got)
+}
+
+func TestDailyAndWeeklyBudgets(t *testing.T) {
+	e := NewEvaluator(Policy{
+		MaxDaily:  3 * time.Hour,
+		MaxWeekly: 7 * time.Hour,
+	}, nil)
+
+	got := simulate(e, monday, monday.AddDate(0, 0, 7), 0)
+	// 3 hours on monday and tuesday, the last hour on wednesday, nothing else until next monday
+	want := []time.Time{at(0, "0h"), at(0, "3h"), at(1, "0h"), at(1, "3h"), at(2, "0h"), at(2, "1h")}
+	assertTimes(t, want, got)
+
+	if played := e.PlayTime(monday, monday.AddDate(0, 0, 7)); played != 7*time.Hour {
+		t.Fatalf("expected 7h played, got %s", played)
+	}
+	if d := e.Decide(monday.AddDate(0, 0, 7), false, 0); d.Action != Start {
+		t.Fatalf("expected a start on the next week, got %v (%s)", d.Action, d.Reason)
+	}
+}
+
+func assertTimes(t *testing.T, want, got []time.Time) {
+	t.Helper()
+	if len(want) != len(got) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if !want[i].Equal(got[i]) {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package schedule
+
+import (
+	"time"
+)
+
+// Policy describes when a supervisor is allowed to play. Zero values disable the matching rule.
+type Policy struct {
+	Windows []Window // Play windows, the supervisor can only play inside one of them. Empty means always.
+
+	StartJitter time.Duration // Starts are delayed by a random amount up to this
+	StopJitter  time.Duration // Window stops are brought forward by a random amount up to this
+
+	BreakAfter    time.Duration // Continuous play time before taking a break
+	BreakDuration time.Duration
+
+	MaxDaily  time.Duration // Play time allowed per calendar day
+	MaxWeekly time.Duration // Play time allowed per week, weeks start on monday
+
+	RestAfterGames int // Games played in a session before resting
+	RestDuration   time.Duration
+}
+
+// Window is a recurring play window
+type Window interface {
+	// Occurrence returns the occurrence of the window containing t, if any
+	Occurrence(t time.Time) (start, end time.Time, found bool)
+}
+
+// WeeklyRange is a window repeating every week on Weekday between two clock times, End before Start means the window
+// finishes the next day
+type WeeklyRange struct {
+	Weekday time.Weekday
+	Start   time.Duration // Offset from midnight
+	End     time.Duration
+}
+
+func (w WeeklyRange) Occurrence(t time.Time) (time.Time, time.Time, bool) {
+	// An occurrence crossing midnight may have started the day before
+	for _, daysAgo := range []int{0, 1} {
+		day := time.Date(t.Year(), t.Month(), t.Day()-daysAgo, 0, 0, 0, 0, t.Location())
+		if day.Weekday() != w.Weekday {
+			continue
+		}
+		start := atClock(day, w.Start)
+		end := atClock(day, w.End)
+		if !end.After(start) {
+			end = atClock(day.AddDate(0, 0, 1), w.End)
+		}
+		if !t.Before(start) && t.Before(end) {
+			return start, end, true
+		}
+	}
+
+	return time.Time{}, time.Time{}, false
+}
+
+// CronWindow opens every time Cron fires and stays open for Duration
+type CronWindow struct {
+	Cron     Cron
+	Duration time.Duration
+}
+
+func (w CronWindow) Occurrence(t time.Time) (time.Time, time.Time, bool) {
+	if w.Duration <= 0 {
+		return time.Time{}, time.Time{}, false
+	}
+
+	// Next is exclusive, start one minute earlier so a window opening exactly Duration ago is not missed
+	start := w.Cron.Next(t.Add(-w.Duration - time.Minute))
+	for !start.IsZero() && !start.After(t) {
+		end := start.Add(w.Duration)
+		if t.Before(end) {
+			return start, end, true
+		}
+		start = w.Cron.Next(start)
+	}
+
+	return time.Time{}, time.Time{}, false
+}
+
+// atClock returns day at the given offset from midnight, using the wall clock so DST changes don't shift it
+func atClock(day time.Time, offset time.Duration) time.Time {
+	h := int(offset / time.Hour)
+	m := int(offset % time.Hour / time.Minute)
+
+	return time.Date(day.Year(), day.Month(), day.Day(), h, m, 0, 0, day.Location())
+}
@@ -0,0 +1,395 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/hectorgimenez/koolo/internal/bot"
+	"github.com/hectorgimenez/koolo/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+const apiV1Prefix = "/api/v1"
+
+// apiRoute describes a /api/v1 endpoint, the same table is used to register the handlers and to generate the OpenAPI
+// spec, so the documentation can't drift from what is served
+type apiRoute struct {
+	method      string
+	path        string // Relative to apiV1Prefix, wildcards use the ServeMux {name} syntax
+	summary     string
+	write       bool // Requires a token that is not read only
+	request     any  // Zero value of the request body type, nil when there is no body
+	response    any  // Zero value of the response body type
+	status      int  // Success status code, 200 if empty
+	handlerFunc http.HandlerFunc
+}
+
+type apiSupervisor struct {
+	Name   string               `json:"name"`
+	Status bot.SupervisorStatus `json:"status"`
+}
+
+type apiResult struct {
+	Status string `json:"status"`
+}
+
+type apiError struct {
+	Error string `json:"error"`
+}
+
+// apiConfigSection is a free form object holding a config section, keys are the ones used in config.yaml
+type apiConfigSection map[string]any
+
+// APIV1 serves the versioned JSON API meant for scripting, every endpoint but the spec requires an API token from
+// koolo.yaml
+type APIV1 struct {
+	s      *HttpServer
+	routes []apiRoute
+}
+
+func NewAPIV1(s *HttpServer) *APIV1 {
+	api := &APIV1{s: s}
+	api.routes = []apiRoute{
+		{method: http.MethodGet, path: "/supervisors", summary: "List supervisors and their status", response: []apiSupervisor{}, handlerFunc: api.listSupervisors},
+		{method: http.MethodGet, path: "/supervisors/{name}", summary: "Get live supervisor stats", response: bot.Stats{}, handlerFunc: api.getSupervisor},
+		{method: http.MethodGet, path: "/supervisors/{name}/overview", summary: "Get the live character overview", response: bot.CharacterOverview{}, handlerFunc: api.getOverview},
+		{method: http.MethodPost, path: "/supervisors/{name}/start", summary: "Start a supervisor", write: true, response: apiResult{}, status: http.StatusAccepted, handlerFunc: api.startSupervisor},
+		{method: http.MethodPost, path: "/supervisors/{name}/stop", summary: "Stop a supervisor", write: true, response: apiResult{}, handlerFunc: api.stopSupervisor},
+		{method: http.MethodPost, path: "/supervisors/{name}/pause", summary: "Pause a running supervisor", write: true, response: apiResult{}, handlerFunc: api.pauseSupervisor},
+		{method: http.MethodPost, path: "/supervisors/{name}/resume", summary: "Resume a paused supervisor", write: true, response: apiResult{}, handlerFunc: api.resumeSupervisor},
+		{method: http.MethodGet, path: "/supervisors/{name}/config", summary: "List the editable config sections", response: []string{}, handlerFunc: api.listConfigSections},
+		{method: http.MethodGet, path: "/supervisors/{name}/config/{section}", summary: "Get a config section", response: apiConfigSection{}, handlerFunc: api.getConfigSection},
+		{method: http.MethodPatch, path: "/supervisors/{name}/config/{section}", summary: "Update the given keys of a config section, saves and reloads the config", write: true, request: apiConfigSection{}, response: apiConfigSection{}, handlerFunc: api.patchConfigSection},
+		{method: http.MethodPost, path: "/config/reload", summary: "Reload every config from disk and apply it to the running supervisors", write: true, response: apiResult{}, handlerFunc: api.reloadConfig},
+	}
+
+	return api
+}
+
+func (api *APIV1) RegisterRoutes(mux *http.ServeMux) {
+	for _, route := range api.routes {
+		mux.HandleFunc(route.method+" "+apiV1Prefix+route.path, api.authenticated(route))
+	}
+	mux.HandleFunc("GET "+apiV1Prefix+"/openapi.json", api.openAPI)
+}
+
+// authenticated checks the bearer token against the tokens configured in koolo.yaml
+func (api *APIV1) authenticated(route apiRoute) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !found || token == "" {
+			api.sendError(w, "missing bearer token", http.StatusUnauthorized)
+			return
+		}
+
+		for _, t := range config.Koolo.API.Tokens {
+			if t.Token == "" || subtle.ConstantTimeCompare([]byte(t.Token), []byte(token)) != 1 {
+				continue
+			}
+			if route.write && t.ReadOnly {
+				api.sendError(w, "token is read only", http.StatusForbidden)
+				return
+			}
+			route.handlerFunc(w, r)
+			return
+		}
+
+		api.sendError(w, "invalid token", http.StatusUnauthorized)
+	}
+}
+
+func (api *APIV1) listSupervisors(w http.ResponseWriter, r *http.Request) {
+	supervisors := api.s.manager.AvailableSupervisors()
+	slices.Sort(supervisors)
+
+	out := make([]apiSupervisor, 0, len(supervisors))
+	for _, name := range supervisors {
+		out = append(out, apiSupervisor{Name: name, Status: api.status(name)})
+	}
+
+	api.sendJSON(w, http.StatusOK, out)
+}
+
+func (api *APIV1) getSupervisor(w http.ResponseWriter, r *http.Request) {
+	name, found := api.supervisor(w, r)
+	if !found {
+		return
+	}
+
+	api.sendJSON(w, http.StatusOK, api.s.getStatusData().Status[name])
+}
+
+func (api *APIV1) getOverview(w http.ResponseWriter, r *http.Request) {
+	name, found := api.supervisor(w, r)
+	if !found {
+		return
+	}
+
+	api.sendJSON(w, http.StatusOK, api.s.getStatusData().Status[name].UI)
+}
+
+func (api *APIV1) startSupervisor(w http.ResponseWriter, r *http.Request) {
+	name, found := api.supervisor(w, r)
+	if !found {
+		return
+	}
+
+	switch api.status(name) {
+	case bot.NotStarted, bot.Crashed:
+	default:
+		api.sendError(w, fmt.Sprintf("supervisor %s is already running", name), http.StatusConflict)
+		return
+	}
+
+	supCfg, _ := config.GetCharacter(name)
+	if api.s.tokenAuthStartBlocked(name, supCfg) {
+		api.sendError(w, "another client using token auth is still starting, try again later", http.StatusConflict)
+		return
+	}
+
+	// Start blocks for the whole supervisor lifetime
+	go func() {
+		if err := api.s.manager.Start(name, false); err != nil {
+			api.s.logger.Error(fmt.Sprintf("error starting supervisor %s: %s", name, err.Error()))
+		}
+	}()
+
+	api.sendJSON(w, http.StatusAccepted, apiResult{Status: "starting"})
+}
+
+func (api *APIV1) stopSupervisor(w http.ResponseWriter, r *http.Request) {
+	name, found := api.supervisor(w, r)
+	if !found {
+		return
+	}
+
+	api.s.manager.Stop(name)
+	api.sendJSON(w, http.StatusOK, apiResult{Status: "stopped"})
+}
+
+func (api *APIV1) pauseSupervisor(w http.ResponseWriter, r *http.Request) {
+	api.setPaused(w, r, true)
+}
+
+func (api *APIV1) resumeSupervisor(w http.ResponseWriter, r *http.Request) {
+	api.setPaused(w, r, false)
+}
+
+// setPaused only toggles when the supervisor is not already in the requested state, so retries are safe
+func (api *APIV1) setPaused(w http.ResponseWriter, r *http.Request, paused bool) {
+	name, found := api.supervisor(w, r)
+	if !found {
+		return
+	}
+
+	status := api.status(name)
+	switch {
+	case status == bot.NotStarted || status == bot.Crashed:
+		api.sendError(w, fmt.Sprintf("supervisor %s is not running", name), http.StatusConflict)
+		return
+	case (status == bot.Paused) != paused:
+		api.s.manager.TogglePause(name)
+	}
+
+	if paused {
+		api.sendJSON(w, http.StatusOK, apiResult{Status: string(bot.Paused)})
+		return
+	}
+	api.sendJSON(w, http.StatusOK, apiResult{Status: "resumed"})
+}
+
+func (api *APIV1) listConfigSections(w http.ResponseWriter, r *http.Request) {
+	if _, found := api.supervisor(w, r); !found {
+		return
+	}
+
+	api.sendJSON(w, http.StatusOK, configSections())
+}
+
+func (api *APIV1) getConfigSection(w http.ResponseWriter, r *http.Request) {
+	name, found := api.supervisor(w, r)
+	if !found {
+		return
+	}
+	cfg, _ := config.GetCharacter(name)
+
+	section, err := api.configSection(r, cfg)
+	if err != nil {
+		api.sendError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	out, err := sectionToJSON(section)
+	if err != nil {
+		api.sendError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	api.sendJSON(w, http.StatusOK, out)
+}
+
+func (api *APIV1) patchConfigSection(w http.ResponseWriter, r *http.Request) {
+	name, found := api.supervisor(w, r)
+	if !found {
+		return
+	}
+
+	var changes apiConfigSection
+	if err := json.NewDecoder(r.Body).Decode(&changes); err != nil {
+		api.sendError(w, fmt.Sprintf("invalid request body: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	// Work on a deep copy, running supervisors keep using the current config until it's reloaded
+	current, _ := config.GetCharacter(name)
+	raw, err := yaml.Marshal(current)
+	if err != nil {
+		api.sendError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	cfg := &config.CharacterCfg{}
+	if err = yaml.Unmarshal(raw, cfg); err != nil {
+		api.sendError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	section, err := api.configSection(r, cfg)
+	if err != nil {
+		api.sendError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	// Keys not present in the request keep their current value
+	raw, err = yaml.Marshal(map[string]any(changes))
+	if err != nil {
+		api.sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = yaml.Unmarshal(raw, section.Addr().Interface()); err != nil {
+		api.sendError(w, fmt.Sprintf("invalid section values: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	if err = config.SaveSupervisorConfig(name, cfg); err != nil {
+		api.sendError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err = api.s.manager.ReloadConfig(); err != nil {
+		api.sendError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	out, err := sectionToJSON(section)
+	if err != nil {
+		api.sendError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	api.sendJSON(w, http.StatusOK, out)
+}
+
+func (api *APIV1) reloadConfig(w http.ResponseWriter, r *http.Request) {
+	if err := api.s.manager.ReloadConfig(); err != nil {
+		api.sendError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	api.s.logger.Info("Config reloaded")
+	api.sendJSON(w, http.StatusOK, apiResult{Status: "reloaded"})
+}
+
+// supervisor resolves the {name} path value, writing a 404 if there is no such supervisor
+func (api *APIV1) supervisor(w http.ResponseWriter, r *http.Request) (string, bool) {
+	name := r.PathValue("name")
+	if _, found := config.GetCharacter(name); !found || name == "template" {
+		api.sendError(w, fmt.Sprintf("supervisor %s not found", name), http.StatusNotFound)
+		return "", false
+	}
+
+	return name, true
+}
+
+func (api *APIV1) status(name string) bot.SupervisorStatus {
+	if status := api.s.manager.Status(name).SupervisorStatus; status != "" {
+		return status
+	}
+
+	return bot.NotStarted
+}
+
+// configSections lists the top level keys of the character config holding a struct, plain values like the account
+// credentials are not sections. Passwords inside the sections are left out by sectionToJSON.
+func configSections() []string {
+	var sections []string
+	t := reflect.TypeOf(config.CharacterCfg{})
+	for i := 0; i < t.NumField(); i++ {
+		if name, ok := yamlSectionName(t.Field(i)); ok {
+			sections = append(sections, name)
+		}
+	}
+
+	return sections
+}
+
+func (api *APIV1) configSection(r *http.Request, cfg *config.CharacterCfg) (reflect.Value, error) {
+	section := r.PathValue("section")
+	v := reflect.ValueOf(cfg).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if name, ok := yamlSectionName(v.Type().Field(i)); ok && name == section {
+			return v.Field(i), nil
+		}
+	}
+
+	return reflect.Value{}, fmt.Errorf("config section %s not found", section)
+}
+
+func yamlSectionName(f reflect.StructField) (string, bool) {
+	name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+	if name == "" || name == "-" || !f.IsExported() || f.Type.Kind() != reflect.Struct {
+		return "", false
+	}
+
+	return name, true
+}
+
+// sectionToJSON converts a config section into a map keyed by its yaml names, the config structs have no json tags.
+// Passwords (game passwords of the companion section...) are removed, read only tokens can read every section. They
+// are removed instead of masked so a section sent back as read doesn't overwrite them.
+func sectionToJSON(section reflect.Value) (apiConfigSection, error) {
+	raw, err := yaml.Marshal(section.Interface())
+	if err != nil {
+		return nil, err
+	}
+
+	out := apiConfigSection{}
+	if err = yaml.Unmarshal(raw, &out); err != nil {
+		return nil, err
+	}
+	removePasswords(out)
+
+	return out, nil
+}
+
+func removePasswords(section map[string]any) {
+	for key, value := range section {
+		if strings.Contains(strings.ToLower(key), "password") {
+			delete(section, key)
+		} else if nested, ok := value.(map[string]any); ok {
+			removePasswords(nested)
+		}
+	}
+}
+
+func (api *APIV1) sendJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+func (api *APIV1) sendError(w http.ResponseWriter, message string, status int) {
+	api.sendJSON(w, status, apiError{Error: message})
+}
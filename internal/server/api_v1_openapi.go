@@ -0,0 +1,204 @@
+package server
+
+import (
+	"encoding"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hectorgimenez/koolo/internal/config"
+)
+
+var pathParamRegex = regexp.MustCompile(`{([^}]+)}`)
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	durationType      = reflect.TypeOf(time.Duration(0))
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// openAPI serves an OpenAPI 3 spec generated from the route table, it doesn't require a token so clients can be
+// generated from it
+func (api *APIV1) openAPI(w http.ResponseWriter, r *http.Request) {
+	api.sendJSON(w, http.StatusOK, api.spec())
+}
+
+func (api *APIV1) spec() map[string]any {
+	sg := &schemaGenerator{components: make(map[string]any)}
+	errorResponse := map[string]any{
+		"description": "Error",
+		"content":     jsonContent(sg.schema(reflect.TypeOf(apiError{}))),
+	}
+
+	paths := make(map[string]any)
+	for _, route := range api.routes {
+		status := route.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		op := map[string]any{
+			"summary":     route.summary,
+			"operationId": operationID(route),
+			"security":    []any{map[string]any{"bearerAuth": []string{}}},
+			"responses": map[string]any{
+				strconv.Itoa(status): map[string]any{
+					"description": http.StatusText(status),
+					"content":     jsonContent(sg.schema(reflect.TypeOf(route.response))),
+				},
+				"default": errorResponse,
+			},
+		}
+
+		var params []any
+		for _, m := range pathParamRegex.FindAllStringSubmatch(route.path, -1) {
+			params = append(params, map[string]any{
+				"name":     m[1],
+				"in":       "path",
+				"required": true,
+				"schema":   map[string]any{"type": "string"},
+			})
+		}
+		if len(params) > 0 {
+			op["parameters"] = params
+		}
+		if route.request != nil {
+			op["requestBody"] = map[string]any{
+				"required": true,
+				"content":  jsonContent(sg.schema(reflect.TypeOf(route.request))),
+			}
+		}
+
+		item, found := paths[apiV1Prefix+route.path].(map[string]any)
+		if !found {
+			item = make(map[string]any)
+			paths[apiV1Prefix+route.path] = item
+		}
+		item[strings.ToLower(route.method)] = op
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "Koolo API",
+			"version": config.Version,
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": sg.components,
+			"securitySchemes": map[string]any{
+				"bearerAuth": map[string]any{"type": "http", "scheme": "bearer"},
+			},
+		},
+	}
+}
+
+// operationID builds a stable id from the method and path, e.g. "POST /supervisors/{name}/start" is postSupervisorsNameStart
+func operationID(route apiRoute) string {
+	id := strings.ToLower(route.method)
+	for _, part := range strings.FieldsFunc(route.path, func(r rune) bool { return r == '/' || r == '{' || r == '}' }) {
+		id += strings.ToUpper(part[:1]) + part[1:]
+	}
+
+	return id
+}
+
+func jsonContent(schema map[string]any) map[string]any {
+	return map[string]any{"application/json": map[string]any{"schema": schema}}
+}
+
+// schemaGenerator maps Go types to JSON schemas following encoding/json rules, named structs are stored once in
+// components and referenced, which also takes care of recursive types
+type schemaGenerator struct {
+	components map[string]any
+}
+
+func (sg *schemaGenerator) schema(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case t == durationType:
+		return map[string]any{"type": "integer", "format": "int64"}
+	case t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType):
+		// Custom encoding, we can't know its shape
+		return map[string]any{}
+	case t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType):
+		return map[string]any{"type": "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "format": "byte"}
+		}
+		return map[string]any{"type": "array", "items": sg.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": sg.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return sg.structSchema(t)
+		}
+		name := strings.ReplaceAll(t.String(), " ", "")
+		if _, found := sg.components[name]; !found {
+			sg.components[name] = map[string]any{} // Placeholder, breaks recursion
+			sg.components[name] = sg.structSchema(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + name}
+	}
+
+	// Interfaces, funcs and channels, anything goes
+	return map[string]any{}
+}
+
+func (sg *schemaGenerator) structSchema(t reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	sg.addFields(t, properties)
+
+	return map[string]any{"type": "object", "properties": properties}
+}
+
+func (sg *schemaGenerator) addFields(t reflect.Type, properties map[string]any) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" && opts == "" {
+			continue
+		}
+
+		// Embedded structs without a json name are flattened, same as encoding/json does
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			sg.addFields(ft, properties)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+		properties[name] = sg.schema(f.Type)
+	}
+}
@@ -0,0 +1,217 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hectorgimenez/koolo/internal/bot"
+	"github.com/hectorgimenez/koolo/internal/config"
+)
+
+const testKooloConfig = `api:
+  tokens:
+    - name: admin
+      token: admin-token
+    - name: grafana
+      token: read-token
+      readOnly: true
+`
+
+const testCharacterConfig = `characterName: sorc
+companion:
+  leaderName: leader
+  gamePassword: secret
+  companionGamePassword: secret
+`
+
+// newTestAPI loads a koolo.yaml with a read/write and a read only token and a single "sorc" character from a
+// temporary config directory
+func newTestAPI(t *testing.T) *http.ServeMux {
+	t.Helper()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"config/koolo.yaml":       testKooloConfig,
+		"config/sorc/config.yaml": testCharacterConfig,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "config", "sorc", "pickit"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	if err = config.Load(); err != nil {
+		t.Fatalf("config.Load: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s := &HttpServer{logger: logger, manager: bot.NewSupervisorManager(logger, nil, nil)}
+	mux := http.NewServeMux()
+	NewAPIV1(s).RegisterRoutes(mux)
+
+	return mux
+}
+
+func apiRequest(t *testing.T, mux *http.ServeMux, method, path, token, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var r io.Reader
+	if body != "" {
+		r = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, apiV1Prefix+path, r)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestAPIV1Authentication(t *testing.T) {
+	mux := newTestAPI(t)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		want   int
+	}{
+		{name: "missing token", method: http.MethodGet, path: "/supervisors", want: http.StatusUnauthorized},
+		{name: "invalid token", method: http.MethodGet, path: "/supervisors", token: "nope", want: http.StatusUnauthorized},
+		{name: "invalid token on write", method: http.MethodPost, path: "/supervisors/sorc/stop", token: "nope", want: http.StatusUnauthorized},
+		{name: "read only token reads", method: http.MethodGet, path: "/supervisors", token: "read-token", want: http.StatusOK},
+		{name: "read only token writes", method: http.MethodPost, path: "/supervisors/sorc/stop", token: "read-token", want: http.StatusForbidden},
+		{name: "read only token patches", method: http.MethodPatch, path: "/supervisors/sorc/config/packetCasting", token: "read-token", want: http.StatusForbidden},
+		{name: "read only token reloads", method: http.MethodPost, path: "/config/reload", token: "read-token", want: http.StatusForbidden},
+		{name: "unknown supervisor", method: http.MethodGet, path: "/supervisors/nope/config", token: "read-token", want: http.StatusNotFound},
+		{name: "spec needs no token", method: http.MethodGet, path: "/openapi.json", want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := apiRequest(t, mux, tt.method, tt.path, tt.token, "")
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body)
+			}
+			if rec.Code == http.StatusUnauthorized || rec.Code == http.StatusForbidden {
+				var apiErr apiError
+				if err := json.NewDecoder(rec.Body).Decode(&apiErr); err != nil || apiErr.Error == "" {
+					t.Errorf("expected a JSON error body, got %q", rec.Body)
+				}
+			}
+		})
+	}
+}
+
+func TestAPIV1PatchConfigSection(t *testing.T) {
+	mux := newTestAPI(t)
+
+	rec := apiRequest(t, mux, http.MethodPatch, "/supervisors/sorc/config/packetCasting", "admin-token", `{"useForItemPickup": true}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PATCH status = %d: %s", rec.Code, rec.Body)
+	}
+	var patched apiConfigSection
+	if err := json.NewDecoder(rec.Body).Decode(&patched); err != nil {
+		t.Fatal(err)
+	}
+	if patched["useForItemPickup"] != true || patched["useForTpInteraction"] != false {
+		t.Errorf("PATCH response = %v", patched)
+	}
+
+	// Saved and reloaded, keys not in the request keep their value
+	rec = apiRequest(t, mux, http.MethodGet, "/supervisors/sorc/config/packetCasting", "read-token", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET status = %d: %s", rec.Code, rec.Body)
+	}
+	var got apiConfigSection
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got["useForItemPickup"] != true || got["useForEntranceInteraction"] != false {
+		t.Errorf("GET after PATCH = %v", got)
+	}
+
+	cfg, _ := config.GetCharacter("sorc")
+	if !cfg.PacketCasting.UseForItemPickup || cfg.CharacterName != "sorc" {
+		t.Errorf("reloaded config = %+v", cfg.PacketCasting)
+	}
+}
+
+func TestAPIV1ConfigSectionHidesPasswords(t *testing.T) {
+	mux := newTestAPI(t)
+
+	rec := apiRequest(t, mux, http.MethodGet, "/supervisors/sorc/config/companion", "read-token", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET status = %d: %s", rec.Code, rec.Body)
+	}
+	if strings.Contains(rec.Body.String(), "secret") {
+		t.Fatalf("passwords returned: %s", rec.Body)
+	}
+	var got apiConfigSection
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got["leaderName"] != "leader" {
+		t.Errorf("GET = %v", got)
+	}
+
+	// Sending the section back as read keeps the passwords
+	rec = apiRequest(t, mux, http.MethodPatch, "/supervisors/sorc/config/companion", "admin-token", `{"leaderName": "other"}`)
+	if rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), "secret") {
+		t.Fatalf("PATCH status = %d: %s", rec.Code, rec.Body)
+	}
+	cfg, _ := config.GetCharacter("sorc")
+	if cfg.Companion.GamePassword != "secret" || cfg.Companion.LeaderName != "other" {
+		t.Errorf("reloaded config = %+v", cfg.Companion)
+	}
+}
+
+func TestAPIV1PatchConfigSectionErrors(t *testing.T) {
+	mux := newTestAPI(t)
+
+	tests := []struct {
+		name string
+		path string
+		body string
+		want int
+	}{
+		{name: "unknown section", path: "/supervisors/sorc/config/nope", body: `{}`, want: http.StatusNotFound},
+		{name: "invalid json", path: "/supervisors/sorc/config/packetCasting", body: `{`, want: http.StatusBadRequest},
+		{name: "invalid value", path: "/supervisors/sorc/config/packetCasting", body: `{"useForItemPickup": "maybe"}`, want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := apiRequest(t, mux, http.MethodPatch, tt.path, "admin-token", tt.body)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body)
+			}
+		})
+	}
+}
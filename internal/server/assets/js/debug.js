@@ -316,9 +316,16 @@ function createCopyDataButton() {
     });
 }
 
+function openMap() {
+    const urlParams = new URLSearchParams(window.location.search);
+    const characterName = urlParams.get('characterName') || 'nullref';
+    window.open(`/api/debug/map?characterName=${characterName}&paths=20`, '_blank');
+}
+
 // Event Listeners
 setIntervalBtn.addEventListener('click', setRefreshInterval);
 expandAllBtn.addEventListener('click', toggleExpandAll);
+document.getElementById('open-map-btn').addEventListener('click', openMap);
 searchInput.addEventListener('input', () => performSearch());
 searchNextBtn.addEventListener('click', goToNextSearchResult);
 searchPrevBtn.addEventListener('click', goToPreviousSearchResult);
@@ -536,6 +536,9 @@ async function validateRule() {
         } else {
             const errors = result.errors || [];
             showValidation('error', 'Invalid: ' + (errors.join(', ') || 'Unknown error'));
+            if (result.errorDetails && result.errorDetails.length > 0) {
+                highlightNIPError(nipPreview, result.errorDetails[0]);
+            }
         }
 
         // Show warnings if any
@@ -593,6 +596,20 @@ async function saveRule() {
     }
 }
 
+// Marks the columns of a syntax error in the NIP preview, they are 1-based and inclusive
+function highlightNIPError(nipLine, detail) {
+    const start = Math.max(detail.start - 1, 0);
+    const end = Math.min(Math.max(detail.end, detail.start), nipLine.length);
+
+    const mark = document.createElement('span');
+    mark.className = 'nip-error';
+    mark.title = detail.message;
+    // Errors at the end of the line, like a missing value, have no text to mark
+    mark.textContent = nipLine.slice(start, end) || ' ';
+
+    document.getElementById('nipPreview').replaceChildren(nipLine.slice(0, start), mark, nipLine.slice(end));
+}
+
 function showValidation(type, message) {
     const validationDiv = document.getElementById('validation');
     validationDiv.textContent = message;
@@ -809,7 +826,7 @@ async function deleteRule(ruleId) {
         '⚠️ DELETE CONFIRMATION ⚠️\n\n' +
         'Are you sure you want to permanently delete this rule?\n\n' +
         'Rule: ' + nipLine + '\n\n' +
-        'This action CANNOT be undone!'
+        'The previous version stays in the file history.'
     );
 
     if (!confirmed) {
@@ -858,6 +875,123 @@ async function reloadCurrentFile() {
     }
 }
 
+let currentRevisions = [];
+
+function historyQuery() {
+    return `path=${encodeURIComponent(currentPickitPath)}&file=${encodeURIComponent(currentLoadedFile)}`;
+}
+
+async function showHistory() {
+    if (!currentLoadedFile) {
+        return;
+    }
+
+    try {
+        const response = await fetch(`/api/pickit/files/history?${historyQuery()}`);
+        if (!response.ok) {
+            const errorData = await response.json();
+            throw new Error(errorData.error || 'Failed to load history');
+        }
+
+        currentRevisions = await response.json();
+        document.getElementById('historyFile').textContent = currentLoadedFile;
+        document.getElementById('historyDiff').style.display = 'none';
+
+        const list = document.getElementById('historyList');
+        if (currentRevisions.length === 0) {
+            list.textContent = 'No changes made from the editor yet';
+        } else {
+            list.innerHTML = currentRevisions.map((rev, index) => {
+                const previous = currentRevisions[index + 1];
+                return `
+                <div style="display: flex; justify-content: space-between; align-items: center; padding: 6px 0; border-bottom: 1px solid #333;">
+                    <div style="font-size: 13px;">
+                        <span style="color: #4CAF50;">#${rev.id}</span>
+                        <span style="color: #888;">${new Date(rev.timestamp).toLocaleString()} ${rev.author ? '- ' + rev.author : ''}</span>
+                        <div class="revision-message" data-index="${index}"></div>
+                    </div>
+                    <div style="display: flex; gap: 8px;">
+                        ${previous ? `<button class="btn btn-secondary" style="padding: 4px 10px; font-size: 12px;" onclick="showRevisionDiff(${previous.id}, ${rev.id})">Diff</button>` : ''}
+                        <button class="btn btn-secondary" style="padding: 4px 10px; font-size: 12px;" onclick="rollbackRevision(${rev.id}, false)">Restore file</button>
+                        <button class="btn btn-secondary" style="padding: 4px 10px; font-size: 12px;" onclick="rollbackRevision(${rev.id}, true)">Restore all files</button>
+                    </div>
+                </div>
+                `;
+            }).join('');
+
+            // Messages contain NIP lines, set as text
+            list.querySelectorAll('.revision-message').forEach(div => {
+                div.textContent = currentRevisions[div.dataset.index].message;
+            });
+        }
+
+        const section = document.getElementById('historySection');
+        section.style.display = 'block';
+        section.scrollIntoView({ behavior: 'smooth', block: 'start' });
+    } catch (error) {
+        console.error('Load history error:', error);
+        showValidation('error', 'Failed to load history: ' + error.message);
+    }
+}
+
+function closeHistory() {
+    document.getElementById('historySection').style.display = 'none';
+}
+
+async function showRevisionDiff(from, to) {
+    try {
+        const response = await fetch(`/api/pickit/files/history/diff?${historyQuery()}&from=${from}&to=${to}`);
+        if (!response.ok) {
+            const errorData = await response.json();
+            throw new Error(errorData.error || 'Failed to load diff');
+        }
+
+        const diff = await response.json();
+        const pre = document.getElementById('historyDiff');
+        pre.innerHTML = '';
+        diff.filter(line => line.op !== '=').forEach(line => {
+            const span = document.createElement('div');
+            span.style.color = line.op === '+' ? '#4CAF50' : '#f44336';
+            span.textContent = `${line.op} ${line.op === '+' ? line.newLine : line.oldLine}: ${line.text}`;
+            pre.appendChild(span);
+        });
+        if (pre.childElementCount === 0) {
+            pre.textContent = 'No changes';
+        }
+        pre.style.display = 'block';
+    } catch (error) {
+        console.error('Diff error:', error);
+        showValidation('error', 'Failed to load diff: ' + error.message);
+    }
+}
+
+async function rollbackRevision(revision, allFiles) {
+    const target = allFiles ? 'every file of the pickit folder' : currentLoadedFile;
+    if (!confirm(`Restore ${target} as it was at revision #${revision}?`)) {
+        return;
+    }
+
+    const fileParam = allFiles ? '' : `&file=${encodeURIComponent(currentLoadedFile)}`;
+    try {
+        const response = await fetch(`/api/pickit/files/history/rollback?path=${encodeURIComponent(currentPickitPath)}${fileParam}&revision=${revision}`, {
+            method: 'POST'
+        });
+        if (!response.ok) {
+            const errorData = await response.json();
+            throw new Error(errorData.error || 'Failed to restore');
+        }
+
+        const result = await response.json();
+        showValidation('success', `Restored ${result.restored.length} file(s) to revision #${revision}`);
+
+        await reloadCurrentFile();
+        await showHistory();
+    } catch (error) {
+        console.error('Rollback error:', error);
+        showValidation('error', 'Failed to restore: ' + error.message);
+    }
+}
+
 // Event listeners setup
 document.addEventListener('DOMContentLoaded', function () {
     // Load data on page load
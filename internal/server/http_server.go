@@ -35,7 +35,10 @@ import (
 	ctx "github.com/hectorgimenez/koolo/internal/context"
 	"github.com/hectorgimenez/koolo/internal/event"
 	"github.com/hectorgimenez/koolo/internal/game"
+	"github.com/hectorgimenez/koolo/internal/pather"
 	"github.com/hectorgimenez/koolo/internal/remote/droplog"
+	"github.com/hectorgimenez/koolo/internal/remote/metrics"
+	"github.com/hectorgimenez/koolo/internal/remote/runlog"
 	"github.com/hectorgimenez/koolo/internal/utils"
 	"github.com/hectorgimenez/koolo/internal/utils/winproc"
 	"github.com/lxn/win"
@@ -49,6 +52,8 @@ type HttpServer struct {
 	templates *template.Template
 	wsServer  *WebSocketServer
 	pickitAPI *PickitAPI
+	metrics   *metrics.Collector
+	runStore  *runlog.Store
 }
 
 var (
@@ -185,7 +190,7 @@ func (s *HttpServer) BroadcastStatus() {
 	}
 }
 
-func New(logger *slog.Logger, manager *bot.SupervisorManager) (*HttpServer, error) {
+func New(logger *slog.Logger, manager *bot.SupervisorManager, metricsCollector *metrics.Collector, runStore *runlog.Store) (*HttpServer, error) {
 	var templates *template.Template
 	helperFuncs := template.FuncMap{
 		"isInSlice": func(slice []stat.Resist, value string) bool {
@@ -231,6 +236,8 @@ func New(logger *slog.Logger, manager *bot.SupervisorManager) (*HttpServer, erro
 		manager:   manager,
 		templates: templates,
 		pickitAPI: NewPickitAPI(),
+		metrics:   metricsCollector,
+		runStore:  runStore,
 	}, nil
 }
 
@@ -582,11 +589,15 @@ func (s *HttpServer) Listen(port int) error {
 	http.HandleFunc("/togglePause", s.togglePause)
 	http.HandleFunc("/debug", s.debugHandler)
 	http.HandleFunc("/debug-data", s.debugData)
+	http.HandleFunc("/api/debug/map", s.debugMap)
+	http.HandleFunc("/api/debug/paths", s.debugPaths)
 	http.HandleFunc("/drops", s.drops)
 	http.HandleFunc("/all-drops", s.allDrops)
 	http.HandleFunc("/export-drops", s.exportDrops)
 	http.HandleFunc("/open-droplogs", s.openDroplogs)
 	http.HandleFunc("/reset-droplogs", s.resetDroplogs)
+	http.HandleFunc("/api/run-stats", s.runStats)
+	http.Handle("/metrics", s.metrics) // Prometheus/OpenMetrics scrape endpoint
 	http.HandleFunc("/process-list", s.getProcessList)
 	http.HandleFunc("/attach-process", s.attachProcess)
 	http.HandleFunc("/ws", s.wsServer.HandleWebSocket)      // Web socket
@@ -615,8 +626,17 @@ func (s *HttpServer) Listen(port int) error {
 	http.HandleFunc("/api/pickit/files/rules/delete", s.pickitAPI.handleDeleteFileRule)
 	http.HandleFunc("/api/pickit/files/rules/update", s.pickitAPI.handleUpdateFileRule)
 	http.HandleFunc("/api/pickit/files/rules/append", s.pickitAPI.handleAppendNIPLine)
+	http.HandleFunc("/api/pickit/files/history", s.pickitAPI.handleHistory)
+	http.HandleFunc("/api/pickit/files/history/diff", s.pickitAPI.handleHistoryDiff)
+	http.HandleFunc("/api/pickit/files/history/rollback", s.pickitAPI.handleHistoryRollback)
 	http.HandleFunc("/api/pickit/browse-folder", s.pickitAPI.handleBrowseFolder)
 	http.HandleFunc("/api/pickit/simulate", s.pickitAPI.handleSimulate)
+	http.HandleFunc("/api/pickit/coverage", s.pickitAPI.handleCoverage)
+	http.HandleFunc("/api/pickit/conflicts", s.pickitAPI.handleDetectConflicts)
+	http.HandleFunc("/pickit-coverage", s.pickitCoveragePage)
+
+	// Versioned API for scripting, token protected
+	NewAPIV1(s).RegisterRoutes(http.DefaultServeMux)
 
 	assets, _ := fs.Sub(assetsFS, "assets")
 	http.Handle("/assets/", http.StripPrefix("/assets/", http.FileServer(http.FS(assets))))
@@ -692,10 +712,93 @@ func (s *HttpServer) debugData(w http.ResponseWriter, r *http.Request) {
 	w.Write(jsonData)
 }
 
+// debugMap renders the current level of a supervisor with the last computed paths, ?format=svg|png (default svg) and
+// ?paths=N (default 10)
+func (s *HttpServer) debugMap(w http.ResponseWriter, r *http.Request) {
+	characterName := r.URL.Query().Get("characterName")
+	if characterName == "" {
+		http.Error(w, "Character name is required", http.StatusBadRequest)
+		return
+	}
+
+	context := s.manager.GetContext(characterName)
+	if context == nil || context.PathFinder == nil {
+		http.Error(w, "Supervisor not running", http.StatusNotFound)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = pather.DebugMapSVG
+	}
+	paths := 10
+	if p := r.URL.Query().Get("paths"); p != "" {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			http.Error(w, "Invalid paths parameter", http.StatusBadRequest)
+			return
+		}
+		paths = n
+	}
+
+	var buf bytes.Buffer
+	if err := context.PathFinder.RenderDebugMap(&buf, format, paths); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if format == pather.DebugMapPNG {
+		w.Header().Set("Content-Type", "image/png")
+	} else {
+		w.Header().Set("Content-Type", "image/svg+xml")
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(buf.Bytes())
+}
+
+// debugPaths returns the last computed paths of a supervisor as JSON, ?paths=N (default 10)
+func (s *HttpServer) debugPaths(w http.ResponseWriter, r *http.Request) {
+	context := s.manager.GetContext(r.URL.Query().Get("characterName"))
+	if context == nil || context.PathFinder == nil {
+		http.Error(w, "Supervisor not running", http.StatusNotFound)
+		return
+	}
+
+	paths, err := strconv.Atoi(r.URL.Query().Get("paths"))
+	if err != nil || paths <= 0 {
+		paths = 10
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(context.PathFinder.PathHistory(paths))
+}
+
 func (s *HttpServer) debugHandler(w http.ResponseWriter, r *http.Request) {
 	s.templates.ExecuteTemplate(w, "debug.gohtml", nil)
 }
 
+func (s *HttpServer) pickitCoveragePage(w http.ResponseWriter, r *http.Request) {
+	characters := make([]string, 0)
+	for name := range config.GetCharacters() {
+		characters = append(characters, name)
+	}
+	slices.Sort(characters)
+
+	character := r.URL.Query().Get("character")
+	if character == "" && len(characters) > 0 {
+		character = characters[0]
+	}
+
+	data := PickitCoverageData{Character: character, Characters: characters}
+	report, err := s.pickitAPI.coverageReport(character)
+	if err != nil {
+		data.ErrorMessage = err.Error()
+	}
+	data.Report = report
+
+	s.templates.ExecuteTemplate(w, "pickit_coverage.gohtml", data)
+}
+
 func (s *HttpServer) pickitEditorPage(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 
@@ -714,7 +817,6 @@ func (s *HttpServer) pickitEditorPage(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *HttpServer) startSupervisor(w http.ResponseWriter, r *http.Request) {
-	supervisorList := s.manager.AvailableSupervisors()
 	Supervisor := r.URL.Query().Get("characterName")
 
 	// Get the current auth method for the supervisor we wanna start
@@ -724,11 +826,20 @@ func (s *HttpServer) startSupervisor(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Prevent launching of other clients while there's a client with TokenAuth still starting
-	for _, sup := range supervisorList {
+	if s.tokenAuthStartBlocked(Supervisor, supCfg) {
+		return
+	}
+
+	s.manager.Start(Supervisor, false)
+	s.initialData(w, r)
+}
+
+// tokenAuthStartBlocked prevents launching of other clients while there's a client with TokenAuth still starting
+func (s *HttpServer) tokenAuthStartBlocked(supervisor string, supCfg *config.CharacterCfg) bool {
+	for _, sup := range s.manager.AvailableSupervisors() {
 
 		// If the current don't check against the one we're trying to launch
-		if sup == Supervisor {
+		if sup == supervisor {
 			continue
 		}
 
@@ -736,21 +847,20 @@ func (s *HttpServer) startSupervisor(w http.ResponseWriter, r *http.Request) {
 
 			// Prevent launching if we're using token auth & another client is starting (no matter what auth method)
 			if supCfg.AuthMethod == "TokenAuth" {
-				return
+				return true
 			}
 
 			// Prevent launching if another client that is using token auth is starting
 			sCfg, found := config.GetCharacter(sup)
 			if found {
 				if sCfg.AuthMethod == "TokenAuth" {
-					return
+					return true
 				}
 			}
 		}
 	}
 
-	s.manager.Start(Supervisor, false)
-	s.initialData(w, r)
+	return false
 }
 
 func (s *HttpServer) stopSupervisor(w http.ResponseWriter, r *http.Request) {
@@ -869,6 +979,55 @@ func (s *HttpServer) allDrops(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// runStats returns the aggregated run history stored by runlog. Optional query parameters: from and to (RFC3339 or
+// YYYY-MM-DD, to is inclusive for dates) and supervisor, repeated or comma separated.
+func (s *HttpServer) runStats(w http.ResponseWriter, r *http.Request) {
+	q := runlog.Query{}
+	var err error
+	if q.From, err = parseRunStatsTime(r.URL.Query().Get("from"), false); err != nil {
+		http.Error(w, fmt.Sprintf("invalid from: %v", err), http.StatusBadRequest)
+		return
+	}
+	if q.To, err = parseRunStatsTime(r.URL.Query().Get("to"), true); err != nil {
+		http.Error(w, fmt.Sprintf("invalid to: %v", err), http.StatusBadRequest)
+		return
+	}
+	for _, sup := range r.URL.Query()["supervisor"] {
+		for _, name := range strings.Split(sup, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				q.Supervisors = append(q.Supervisors, name)
+			}
+		}
+	}
+
+	summary, err := s.runStore.Summary(q)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary)
+}
+
+func parseRunStatsTime(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+
+	return t, nil
+}
+
 // exportDrops renders a static HTML of the centralized drops and returns it as a file download.
 func (s *HttpServer) exportDrops(w http.ResponseWriter, r *http.Request) {
 	// Reuse allDrops data generation
@@ -991,6 +1150,7 @@ func (s *HttpServer) config(w http.ResponseWriter, r *http.Request) {
 		// Debug
 		newConfig.Debug.Log = r.Form.Get("debug_log") == "true"
 		newConfig.Debug.Screenshots = r.Form.Get("debug_screenshots") == "true"
+		newConfig.Debug.RecordEvents = r.Form.Get("debug_record_events") == "true"
 		// Discord
 		newConfig.Discord.Enabled = r.Form.Get("discord_enabled") == "true"
 		newConfig.Discord.EnableGameCreatedMessages = r.Form.Has("enable_game_created_messages")
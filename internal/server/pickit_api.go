@@ -8,12 +8,22 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/koolo/internal/config"
 	"github.com/hectorgimenez/koolo/internal/pickit"
+	"github.com/hectorgimenez/koolo/internal/pickit/nip"
+	"github.com/hectorgimenez/koolo/internal/remote/droplog"
 	"github.com/hectorgimenez/koolo/internal/utils"
 )
 
+const (
+	maxSimulationSamples = 100
+	maxSimulationDrops   = 200
+)
+
 // PickitAPI handles all pickit editor endpoints
 type PickitAPI struct {
 	builder *pickit.NIPBuilder
@@ -48,6 +58,9 @@ func (api *PickitAPI) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/api/pickit/files/rules/delete", api.handleDeleteFileRule)
 	mux.HandleFunc("/api/pickit/files/rules/update", api.handleUpdateFileRule)
 	mux.HandleFunc("/api/pickit/files/rules/append", api.handleAppendNIPLine)
+	mux.HandleFunc("/api/pickit/files/history", api.handleHistory)
+	mux.HandleFunc("/api/pickit/files/history/diff", api.handleHistoryDiff)
+	mux.HandleFunc("/api/pickit/files/history/rollback", api.handleHistoryRollback)
 	mux.HandleFunc("/api/pickit/browse-folder", api.handleBrowseFolder)
 
 	// Template endpoints
@@ -57,6 +70,7 @@ func (api *PickitAPI) RegisterRoutes(mux *http.ServeMux) {
 	// Utility endpoints
 	mux.HandleFunc("/api/pickit/stats", api.handleGetStats)
 	mux.HandleFunc("/api/pickit/simulate", api.handleSimulate)
+	mux.HandleFunc("/api/pickit/coverage", api.handleCoverage)
 	mux.HandleFunc("/api/pickit/suggestions", api.handleGetSuggestions)
 	mux.HandleFunc("/api/pickit/conflicts", api.handleDetectConflicts)
 }
@@ -172,7 +186,7 @@ func (api *PickitAPI) handleCreateRule(w http.ResponseWriter, r *http.Request) {
 
 	// Save rule (implementation needed)
 	characterID := r.URL.Query().Get("character")
-	if err := api.saveRule(characterID, &rule); err != nil {
+	if err := api.saveRule(characterID, revisionAuthor(r), &rule); err != nil {
 		http.Error(w, fmt.Sprintf("Error saving rule: %v", err), http.StatusInternalServerError)
 		return
 	}
@@ -216,7 +230,7 @@ func (api *PickitAPI) handleUpdateRule(w http.ResponseWriter, r *http.Request) {
 	rule.GeneratedNIP = nipLine
 
 	characterID := r.URL.Query().Get("character")
-	if err := api.updateRule(characterID, &rule); err != nil {
+	if err := api.updateRule(characterID, revisionAuthor(r), &rule); err != nil {
 		http.Error(w, fmt.Sprintf("Error updating rule: %v", err), http.StatusInternalServerError)
 		return
 	}
@@ -237,7 +251,7 @@ func (api *PickitAPI) handleDeleteRule(w http.ResponseWriter, r *http.Request) {
 	ruleID := r.URL.Query().Get("id")
 	characterID := r.URL.Query().Get("character")
 
-	if err := api.deleteRule(characterID, ruleID); err != nil {
+	if err := api.deleteRule(characterID, revisionAuthor(r), ruleID); err != nil {
 		http.Error(w, fmt.Sprintf("Error deleting rule: %v", err), http.StatusInternalServerError)
 		return
 	}
@@ -437,70 +451,171 @@ func (api *PickitAPI) handleGetStats(w http.ResponseWriter, r *http.Request) {
 	api.sendJSON(w, response)
 }
 
-// handleSimulate simulates a rule against test data
+// handleSimulate runs synthetic items rolled from the item database and items from the droplog through the pickit
+// rules and reports what the bot would do with each one
 func (api *PickitAPI) handleSimulate(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		api.sendError(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	var rule pickit.PickitRule
-	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	var request struct {
+		Character string   `json:"character"` // Character whose rules are used
+		NIPLines  []string `json:"nipLines"`  // Rules to test instead of the character ones
+		ItemIDs   []string `json:"itemIds"`   // Item database entries to roll samples from
+		Samples   int      `json:"samples"`   // Samples per item
+		Seed      int64    `json:"seed"`      // Seed for the rolled stats, same seed same items
+		Droplog   bool     `json:"droplog"`   // Also evaluate the items stashed by the character
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		api.sendError(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
-	// Generate simulation based on rule
+	var simulator *pickit.Simulator
+	if len(request.NIPLines) > 0 {
+		var err error
+		simulator, err = pickit.NewSimulatorFromLines(request.NIPLines, request.Seed)
+		if err != nil {
+			api.sendError(w, fmt.Sprintf("Invalid NIP rule: %v", err), http.StatusBadRequest)
+			return
+		}
+	} else {
+		if request.Character == "" {
+			api.sendError(w, "character or nipLines required", http.StatusBadRequest)
+			return
+		}
+		var err error
+		if simulator, err = api.characterSimulator(request.Character, request.Seed); err != nil {
+			api.sendError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	samples := request.Samples
+	if samples <= 0 {
+		samples = 10
+	}
+	samples = min(samples, maxSimulationSamples)
+
 	result := pickit.SimulationResult{
-		RuleID:      rule.ID,
-		MatchCount:  0,
-		Matches:     []pickit.ItemMatch{},
-		Performance: "Good",
+		Items:       []pickit.SimulatedItem{},
+		Summary:     map[pickit.Outcome]int{},
 		Suggestions: []string{},
 	}
 
-	// Simulate by finding matching items in database
-	_, err := api.builder.GenerateNIP(&rule)
-	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to generate NIP: %v", err), http.StatusBadRequest)
-		return
+	for _, id := range request.ItemIDs {
+		def, found := pickit.GetItemByIDV2(id)
+		if !found {
+			def, found = pickit.GetItemByID(id)
+		}
+		if !found {
+			result.Suggestions = append(result.Suggestions, fmt.Sprintf("Unknown item %s", id))
+			continue
+		}
+
+		items, err := simulator.SampleItems(def, samples)
+		if err != nil {
+			result.Suggestions = append(result.Suggestions, err.Error())
+			continue
+		}
+		for _, it := range items {
+			result.Items = append(result.Items, simulator.Evaluate(it, "synthetic"))
+		}
 	}
 
-	// Find items that match the rule criteria
-	for _, item := range pickit.ItemDatabase {
-		// Check name match
-		nameMatch := false
-		for _, cond := range rule.LeftConditions {
-			if cond.Property == "name" && cond.Operator == "==" {
-				if item.ID == cond.Value || item.InternalName == cond.Value {
-					nameMatch = true
-					break
-				}
-			}
+	if request.Droplog {
+		for _, rec := range api.droplogRecords(request.Character, maxSimulationDrops) {
+			result.Items = append(result.Items, simulator.Evaluate(rec.Drop.Item, "droplog"))
 		}
+	}
 
-		if nameMatch {
-			result.MatchCount++
-			result.Matches = append(result.Matches, pickit.ItemMatch{
-				ItemName:  item.Name,
-				ImageIcon: item.ImageIcon,
-				Matched:   true,
-				Reason:    "Name matches rule criteria",
-				Stats:     make(map[string]interface{}),
-			})
+	droppedAfterIdentify := 0
+	for _, it := range result.Items {
+		result.Summary[it.Outcome]++
+		if it.AfterIdentify == pickit.OutcomeIgnore {
+			droppedAfterIdentify++
 		}
 	}
 
-	// Add suggestions based on match count
-	if result.MatchCount == 0 {
+	// Add suggestions based on the outcomes
+	if len(result.Items) > 0 && result.Summary[pickit.OutcomeIgnore] == len(result.Items) {
 		result.Suggestions = append(result.Suggestions, "No items matched. Check your item name and conditions.")
-	} else if result.MatchCount > 10 {
-		result.Suggestions = append(result.Suggestions, "Rule matches many items. Consider adding quality or stat filters.")
+	}
+	if droppedAfterIdentify > len(result.Items)/2 {
+		result.Suggestions = append(result.Suggestions, "Most items are picked up only to be dropped after identifying them. Consider adding quality filters.")
 	}
 
 	api.sendJSON(w, result)
+}
 
-	api.sendJSON(w, result)
+// droplogRecords returns the latest items stashed by the character, or by everyone when it's empty. A limit of 0
+// returns all of them.
+func (api *PickitAPI) droplogRecords(characterID string, limit int) []droplog.Record {
+	base := config.Koolo.LogSaveDirectory
+	if base == "" {
+		base = "logs"
+	}
+	records, err := droplog.ReadAll(filepath.Join(base, "droplogs"))
+	if err != nil {
+		return nil
+	}
+
+	var filtered []droplog.Record
+	for _, rec := range records {
+		if characterID == "" || strings.EqualFold(rec.Profile, characterID) {
+			filtered = append(filtered, rec)
+		}
+	}
+
+	if limit > 0 {
+		return filtered[max(0, len(filtered)-limit):]
+	}
+
+	return filtered
+}
+
+// handleCoverage returns how many historical drops every rule of the character matched
+func (api *PickitAPI) handleCoverage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	report, err := api.coverageReport(r.URL.Query().Get("character"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	api.sendJSON(w, report)
+}
+
+// coverageReport runs every item the character stashed through its current rules
+func (api *PickitAPI) coverageReport(characterID string) (pickit.CoverageReport, error) {
+	simulator, err := api.characterSimulator(characterID, 0)
+	if err != nil {
+		return pickit.CoverageReport{}, err
+	}
+
+	records := api.droplogRecords(characterID, 0)
+	drops := make([]data.Drop, 0, len(records))
+	for _, rec := range records {
+		drops = append(drops, rec.Drop)
+	}
+
+	return simulator.Coverage(drops), nil
+}
+
+// characterSimulator creates a simulator for the pickit files of the character as they are now, the rules loaded by
+// the bot don't include the changes made in the editor since it started
+func (api *PickitAPI) characterSimulator(characterID string, seed int64) (*pickit.Simulator, error) {
+	cfg, found := config.GetCharacter(characterID)
+	if !found {
+		return nil, fmt.Errorf("character %q not found", characterID)
+	}
+
+	return pickit.NewSimulatorFromDir(config.PickitPath(cfg), seed)
 }
 
 // handleGetSuggestions returns auto-suggestions for a rule
@@ -520,25 +635,148 @@ func (api *PickitAPI) handleGetSuggestions(w http.ResponseWriter, r *http.Reques
 	api.sendJSON(w, suggestions)
 }
 
-// handleDetectConflicts detects conflicts between rules
+// handleDetectConflicts detects conflicts between rules, the ones in the request body or, with GET, every rule in
+// the character pickit files
 func (api *PickitAPI) handleDetectConflicts(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
+	var rules []pickit.PickitRule
+	switch r.Method {
+	case http.MethodGet:
+		characterID := r.URL.Query().Get("character")
+		if characterID == "" {
+			http.Error(w, "Character ID required", http.StatusBadRequest)
+			return
+		}
+		var err error
+		if rules, err = api.loadNIPLines(characterID); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	case http.MethodPost:
+		if err := json.NewDecoder(r.Body).Decode(&rules); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		for i := range rules {
+			if rules[i].GeneratedNIP == "" {
+				rules[i].GeneratedNIP, _ = api.builder.GenerateNIP(&rules[i])
+			}
+		}
+	default:
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	var rules []pickit.PickitRule
-	if err := json.NewDecoder(r.Body).Decode(&rules); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	conflicts := pickit.DetectConflicts(rules)
+	api.sendJSON(w, conflicts)
+}
+
+// handleHistory lists the revisions of a pickit file, of every file of the directory when there's no file
+func (api *PickitAPI) handleHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		api.sendError(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	conflicts := pickit.DetectConflicts(rules)
-	api.sendJSON(w, conflicts)
+	history, err := pickitHistory(r)
+	if err != nil {
+		api.sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	revisions, err := history.Revisions(r.URL.Query().Get("file"))
+	if err != nil {
+		api.sendError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	api.sendJSON(w, revisions)
+}
+
+// handleHistoryDiff compares two revisions line by line, from and to are revision IDs
+func (api *PickitAPI) handleHistoryDiff(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		api.sendError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	history, err := pickitHistory(r)
+	if err != nil {
+		api.sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	from, errFrom := strconv.Atoi(r.URL.Query().Get("from"))
+	to, errTo := strconv.Atoi(r.URL.Query().Get("to"))
+	if errFrom != nil || errTo != nil {
+		api.sendError(w, "Missing required parameters: from, to", http.StatusBadRequest)
+		return
+	}
+
+	diff, err := history.Diff(from, to)
+	if err != nil {
+		api.sendError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	api.sendJSON(w, diff)
+}
+
+// handleHistoryRollback restores a file as it was at a revision, every file of the directory when there's no file
+func (api *PickitAPI) handleHistoryRollback(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		api.sendError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	history, err := pickitHistory(r)
+	if err != nil {
+		api.sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	revision, err := strconv.Atoi(r.URL.Query().Get("revision"))
+	if err != nil {
+		api.sendError(w, "Missing required parameter: revision", http.StatusBadRequest)
+		return
+	}
+
+	restored, err := history.Rollback(r.URL.Query().Get("file"), revision, revisionAuthor(r))
+	if err != nil {
+		api.sendError(w, fmt.Sprintf("Rollback failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Rolled back %d pickit files to revision %d", len(restored), revision)
+
+	api.sendJSON(w, map[string]interface{}{
+		"success":  true,
+		"restored": restored,
+	})
 }
 
 // Helper functions
 
+// pickitHistory returns the history of the pickit directory of the request, from the path or the character
+func pickitHistory(r *http.Request) (*pickit.History, error) {
+	if pickitPath := r.URL.Query().Get("path"); pickitPath != "" {
+		return pickit.NewHistory(pickitPath), nil
+	}
+	if characterID := r.URL.Query().Get("character"); characterID != "" {
+		return pickit.NewHistory(filepath.Join("config", characterID, "pickit")), nil
+	}
+
+	return nil, fmt.Errorf("either 'path' or 'character' parameter required")
+}
+
+// revisionAuthor is who made the change, as sent by the editor
+func revisionAuthor(r *http.Request) string {
+	if author := r.URL.Query().Get("author"); author != "" {
+		return author
+	}
+
+	return "editor"
+}
+
 func (api *PickitAPI) sendJSON(w http.ResponseWriter, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(data)
@@ -652,7 +890,50 @@ func (api *PickitAPI) loadCharacterRules(characterID string) ([]pickit.PickitRul
 	return rules, nil
 }
 
-func (api *PickitAPI) saveRule(characterID string, rule *pickit.PickitRule) error {
+// loadNIPLines returns every rule line of the character pickit files as they are, in the order the bot loads them.
+// Unlike loadCharacterRules, lines the editor can't parse are kept.
+func (api *PickitAPI) loadNIPLines(characterID string) ([]pickit.PickitRule, error) {
+	rules := []pickit.PickitRule{}
+
+	pickitDir := filepath.Join("config", characterID, "pickit")
+	if cfg, found := config.GetCharacter(characterID); found {
+		pickitDir = config.PickitPath(cfg)
+	}
+	entries, err := os.ReadDir(pickitDir)
+	if os.IsNotExist(err) {
+		return rules, nil
+	}
+	if err != nil {
+		return rules, fmt.Errorf("failed to read pickit directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".nip") {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(pickitDir, entry.Name()))
+		if err != nil {
+			return rules, fmt.Errorf("failed to read %s: %w", entry.Name(), err)
+		}
+
+		for i, line := range strings.Split(string(content), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "//") {
+				continue
+			}
+			rules = append(rules, pickit.PickitRule{
+				ID:           fmt.Sprintf("%s:%d", entry.Name(), i+1),
+				FileName:     entry.Name(),
+				GeneratedNIP: line,
+				Enabled:      true,
+			})
+		}
+	}
+
+	return rules, nil
+}
+
+func (api *PickitAPI) saveRule(characterID, author string, rule *pickit.PickitRule) error {
 	// Get character's pickit directory
 	pickitDir := filepath.Join("config", characterID, "pickit")
 
@@ -688,14 +969,15 @@ func (api *PickitAPI) saveRule(characterID string, rule *pickit.PickitRule) erro
 	content += nipLine + "\n"
 
 	// Write back to file
-	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+	message := fmt.Sprintf("Add %s", nipLine)
+	if _, err := pickit.NewHistory(pickitDir).WriteFile(fileName, content, author, message); err != nil {
 		return fmt.Errorf("failed to write pickit file: %w", err)
 	}
 
 	return nil
 }
 
-func (api *PickitAPI) updateRule(characterID string, rule *pickit.PickitRule) error {
+func (api *PickitAPI) updateRule(characterID, author string, rule *pickit.PickitRule) error {
 	// Parse rule ID to get file and line number
 	parts := strings.Split(rule.ID, ":")
 	if len(parts) != 2 {
@@ -703,7 +985,8 @@ func (api *PickitAPI) updateRule(characterID string, rule *pickit.PickitRule) er
 	}
 
 	fileName := parts[0]
-	filePath := filepath.Join("config", characterID, "pickit", fileName)
+	pickitDir := filepath.Join("config", characterID, "pickit")
+	filePath := filepath.Join(pickitDir, fileName)
 
 	// Read file
 	content, err := os.ReadFile(filePath)
@@ -725,14 +1008,15 @@ func (api *PickitAPI) updateRule(characterID string, rule *pickit.PickitRule) er
 	}
 
 	// Write back
-	if err := os.WriteFile(filePath, []byte(strings.Join(lines, "\n")), 0644); err != nil {
+	message := fmt.Sprintf("Update line %s: %s", parts[1], newNipLine)
+	if _, err := pickit.NewHistory(pickitDir).WriteFile(fileName, strings.Join(lines, "\n"), author, message); err != nil {
 		return fmt.Errorf("failed to update pickit file: %w", err)
 	}
 
 	return nil
 }
 
-func (api *PickitAPI) deleteRule(characterID, ruleID string) error {
+func (api *PickitAPI) deleteRule(characterID, author, ruleID string) error {
 	// Parse rule ID to get file and line number
 	parts := strings.Split(ruleID, ":")
 	if len(parts) != 2 {
@@ -740,7 +1024,8 @@ func (api *PickitAPI) deleteRule(characterID, ruleID string) error {
 	}
 
 	fileName := parts[0]
-	filePath := filepath.Join("config", characterID, "pickit", fileName)
+	pickitDir := filepath.Join("config", characterID, "pickit")
+	filePath := filepath.Join(pickitDir, fileName)
 
 	// Read file
 	content, err := os.ReadFile(filePath)
@@ -756,7 +1041,8 @@ func (api *PickitAPI) deleteRule(characterID, ruleID string) error {
 	}
 
 	// Write back
-	if err := os.WriteFile(filePath, []byte(strings.Join(lines, "\n")), 0644); err != nil {
+	message := fmt.Sprintf("Delete line %s", parts[1])
+	if _, err := pickit.NewHistory(pickitDir).WriteFile(fileName, strings.Join(lines, "\n"), author, message); err != nil {
 		return fmt.Errorf("failed to delete from pickit file: %w", err)
 	}
 
@@ -894,15 +1180,16 @@ func (api *PickitAPI) handleDeleteFileRule(w http.ResponseWriter, r *http.Reques
 	}
 
 	// Determine file path
-	var filePath string
+	var pickitDir string
 	if pickitPath != "" {
-		filePath = filepath.Join(pickitPath, fileName)
+		pickitDir = pickitPath
 	} else if characterID != "" {
-		filePath = filepath.Join("config", characterID, "pickit", fileName)
+		pickitDir = filepath.Join("config", characterID, "pickit")
 	} else {
 		api.sendError(w, "Either 'path' or 'character' parameter required", http.StatusBadRequest)
 		return
 	}
+	filePath := filepath.Join(pickitDir, fileName)
 
 	// Read the file
 	content, err := os.ReadFile(filePath)
@@ -925,7 +1212,8 @@ func (api *PickitAPI) handleDeleteFileRule(w http.ResponseWriter, r *http.Reques
 
 	// Write back to file
 	newContent := strings.Join(newLines, "\n")
-	if err := os.WriteFile(filePath, []byte(newContent), 0644); err != nil {
+	message := fmt.Sprintf("Delete line %d", lineNum+1)
+	if _, err := pickit.NewHistory(pickitDir).WriteFile(fileName, newContent, revisionAuthor(r), message); err != nil {
 		api.sendError(w, fmt.Sprintf("Failed to write file: %v", err), http.StatusInternalServerError)
 		return
 	}
@@ -990,15 +1278,16 @@ func (api *PickitAPI) handleUpdateFileRule(w http.ResponseWriter, r *http.Reques
 	}
 
 	// Determine file path
-	var filePath string
+	var pickitDir string
 	if pickitPath != "" {
-		filePath = filepath.Join(pickitPath, fileName)
+		pickitDir = pickitPath
 	} else if characterID != "" {
-		filePath = filepath.Join("config", characterID, "pickit", fileName)
+		pickitDir = filepath.Join("config", characterID, "pickit")
 	} else {
 		api.sendError(w, "Either 'path' or 'character' parameter required", http.StatusBadRequest)
 		return
 	}
+	filePath := filepath.Join(pickitDir, fileName)
 
 	// Read the file
 	content, err := os.ReadFile(filePath)
@@ -1021,7 +1310,8 @@ func (api *PickitAPI) handleUpdateFileRule(w http.ResponseWriter, r *http.Reques
 
 	// Write back to file
 	newContent := strings.Join(lines, "\n")
-	if err := os.WriteFile(filePath, []byte(newContent), 0644); err != nil {
+	message := fmt.Sprintf("Update line %d: %s", lineNum+1, updateData.NewNIPLine)
+	if _, err := pickit.NewHistory(pickitDir).WriteFile(fileName, newContent, revisionAuthor(r), message); err != nil {
 		api.sendError(w, fmt.Sprintf("Failed to write file: %v", err), http.StatusInternalServerError)
 		return
 	}
@@ -1102,7 +1392,8 @@ func (api *PickitAPI) handleAppendNIPLine(w http.ResponseWriter, r *http.Request
 	content += requestData.NIPLine + "\n"
 
 	// Write back to file
-	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+	message := fmt.Sprintf("Add %s", requestData.NIPLine)
+	if _, err := pickit.NewHistory(pickitDir).WriteFile(fileName, content, revisionAuthor(r), message); err != nil {
 		api.sendError(w, fmt.Sprintf("Failed to write file: %v", err), http.StatusInternalServerError)
 		return
 	}
@@ -1115,7 +1406,7 @@ func (api *PickitAPI) handleAppendNIPLine(w http.ResponseWriter, r *http.Request
 	})
 }
 
-// handleValidateNIPLine validates a raw NIP line syntax (simple validation)
+// handleValidateNIPLine validates a raw NIP line syntax, errors point to the columns of the offending text
 func (api *PickitAPI) handleValidateNIPLine(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		api.sendError(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -1137,85 +1428,81 @@ func (api *PickitAPI) handleValidateNIPLine(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	nipLine := strings.TrimSpace(requestData.NIPLine)
+	// Leading spaces are kept so the error columns match the text in the editor
+	nipLine := strings.TrimRight(requestData.NIPLine, "\r\n")
 
-	// Basic validation
 	valid := true
 	errors := []string{}
+	errorDetails := []map[string]interface{}{}
 	warnings := []string{}
 
-	// Check if line is empty
-	if nipLine == "" {
+	line, err := nip.Parse(nipLine)
+	switch {
+	case strings.TrimSpace(nipLine) == "":
 		valid = false
 		errors = append(errors, "NIP line cannot be empty")
-	}
-
-	// Check if it's just a comment
-	if strings.HasPrefix(nipLine, "//") {
+	case err != nil:
+		valid = false
+		errors = append(errors, err.Error())
+
+		// Columns are 1-based and inclusive so the editor can highlight them
+		if nipErr, ok := err.(*nip.Error); ok {
+			start, end := nipErr.Columns()
+			errorDetails = append(errorDetails, map[string]interface{}{
+				"message": nipErr.Msg,
+				"start":   start,
+				"end":     end,
+			})
+		}
+	case !line.IsRule():
 		valid = false
 		errors = append(errors, "Line is just a comment, no rule defined")
+	default:
+		warnings = nipLineWarnings(line)
 	}
 
-	// Check for basic NIP syntax patterns
-	if valid {
-		// Should contain at least one [property]
-		if !strings.Contains(nipLine, "[") || !strings.Contains(nipLine, "]") {
-			errors = append(errors, "No property found. NIP rules must contain at least one [property]")
-			valid = false
-		}
-
-		// Should contain an operator
-		hasOperator := strings.Contains(nipLine, "==") ||
-			strings.Contains(nipLine, "!=") ||
-			strings.Contains(nipLine, ">=") ||
-			strings.Contains(nipLine, "<=") ||
-			strings.Contains(nipLine, ">") ||
-			strings.Contains(nipLine, "<")
-
-		if !hasOperator {
-			errors = append(errors, "No operator found. Use ==, !=, >=, <=, >, or <")
-			valid = false
-		}
+	log.Printf("Validated NIP line: %s - Valid: %v", nipLine, valid)
 
-		// Common properties
-		commonProps := []string{"name", "type", "quality", "sockets", "defense", "flag"}
-		hasCommonProp := false
-		for _, prop := range commonProps {
-			if strings.Contains(nipLine, "["+prop+"]") {
-				hasCommonProp = true
-				break
-			}
-		}
+	api.sendJSON(w, map[string]interface{}{
+		"valid":        valid,
+		"errors":       errors,
+		"errorDetails": errorDetails,
+		"warnings":     warnings,
+		"nipLine":      nipLine,
+	})
+}
 
-		if !hasCommonProp {
-			warnings = append(warnings, "No common properties found. Make sure property names are correct.")
-		}
+// nipLineWarnings checks the properties used by a syntactically valid line
+func nipLineWarnings(line *nip.Line) []string {
+	warnings := []string{}
 
-		// Check for maxquantity syntax
-		if strings.Contains(nipLine, "maxquantity") && !strings.Contains(nipLine, "# #") {
-			warnings = append(warnings, "maxquantity should be after # # delimiter")
+	// Common properties
+	commonProps := map[string]bool{"name": true, "type": true, "quality": true, "sockets": true, "defense": true, "flag": true}
+	hasCommonProp := false
+	nip.Walk(line.Section(0), func(n nip.Node) {
+		if p, ok := n.(*nip.PropertyExpr); ok && commonProps[p.Name()] {
+			hasCommonProp = true
 		}
+	})
+	if !hasCommonProp {
+		warnings = append(warnings, "No common properties found. Make sure property names are correct.")
+	}
 
-		// Check for stats after #
-		parts := strings.Split(nipLine, "#")
-		if len(parts) > 1 && len(parts[1]) > 0 {
-			// Has right side (stats)
-			rightSide := strings.TrimSpace(parts[1])
-			if rightSide != "" && !strings.HasPrefix(rightSide, "#") {
-				// Contains stats, good
-				warnings = append(warnings, "Rule has stat requirements on right side (after #)")
-			}
+	// Check for maxquantity syntax
+	nip.Walk(line.Section(1), func(n nip.Node) {
+		if p, ok := n.(*nip.PropertyExpr); ok && p.Name() == "maxquantity" {
+			warnings = append(warnings, fmt.Sprintf("col %d: maxquantity should be after # # delimiter", p.Pos()+1))
 		}
+	})
+	if qty := line.Section(2); qty != nil && !strings.HasPrefix(nip.FormatNode(qty), "[maxquantity]") {
+		warnings = append(warnings, fmt.Sprintf("col %d: only [maxquantity] is allowed after the second #", qty.Pos()+1))
 	}
 
-	log.Printf("Validated NIP line: %s - Valid: %v", nipLine, valid)
+	if line.Section(1) != nil {
+		warnings = append(warnings, "Rule has stat requirements on right side (after #)")
+	}
 
-	api.sendJSON(w, map[string]interface{}{
-		"valid":    valid,
-		"errors":   errors,
-		"warnings": warnings,
-		"nipLine":  nipLine,
-	})
+	return warnings
 }
 
 // handleBrowseFolder allows browsing for a folder using native Windows dialog
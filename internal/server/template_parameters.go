@@ -4,6 +4,7 @@ import (
 	"github.com/hectorgimenez/d2go/pkg/data"
 	"github.com/hectorgimenez/koolo/internal/bot"
 	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/pickit"
 )
 
 type IndexData struct {
@@ -35,6 +36,14 @@ type AllDropRecord struct {
 	Drop       data.Drop
 }
 
+// PickitCoverageData is used by the pickit rule coverage report.
+type PickitCoverageData struct {
+	ErrorMessage string
+	Character    string
+	Characters   []string
+	Report       pickit.CoverageReport
+}
+
 type CharacterSettings struct {
 	ErrorMessage       string
 	Supervisor         string
@@ -12,8 +12,9 @@ func GameCoordsToScreenCords(destinationX, destinationY int) (int, int) {
 
 	// Transform cartesian movement (World) to isometric (screen)
 	// Helpful documentation: https://clintbellanger.net/articles/isometric_math/
-	screenX := int((float32(diffX-diffY) * 19.8) + float32(ctx.GameReader.GameAreaSizeX/2))
-	screenY := int((float32(diffX+diffY) * 9.9) + float32(ctx.GameReader.GameAreaSizeY/2))
+	gameAreaSizeX, gameAreaSizeY := ctx.GameReader.GameAreaSize()
+	screenX := int((float32(diffX-diffY) * 19.8) + float32(gameAreaSizeX/2))
+	screenY := int((float32(diffX+diffY) * 9.9) + float32(gameAreaSizeY/2))
 
 	return screenX, screenY
 }
@@ -48,7 +48,13 @@ var (
 
 func GetScreenCoordsForItem(itm data.Item) data.Position {
 	ctx := context.Get()
-	if ctx.GameReader.LegacyGraphics() {
+
+	return ScreenCoordsForItem(itm, ctx.GameReader.LegacyGraphics())
+}
+
+// ScreenCoordsForItem returns the center of the item cell on screen without reading the current context
+func ScreenCoordsForItem(itm data.Item, legacyGraphics bool) data.Position {
+	if legacyGraphics {
 		return getScreenCoordsForItemClassic(itm)
 	}
 
@@ -0,0 +1,13 @@
+//go:build !windows
+
+package utils
+
+import (
+	"fmt"
+	"os"
+)
+
+// ShowDialog prints the message, native dialogs are only shown on Windows
+func ShowDialog(title, message string) {
+	fmt.Fprintf(os.Stderr, "%s: %s\n", title, message)
+}